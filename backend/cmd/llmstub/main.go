@@ -0,0 +1,42 @@
+// Command llmstub serves a fake OpenAI-compatible API for exercising the
+// batch mode locally:
+//
+//	go run ./cmd/llmstub -addr :8089 -delay 10s
+//	LLM_BATCH_ENABLED=true LLM_BATCH_BASE_URL=http://localhost:8089/v1 go run ./cmd/server
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/adyutaa/parsea/internal/infrastructure/llm/llmstub"
+)
+
+func main() {
+	addr := flag.String("addr", ":8089", "listen address")
+	delay := flag.Duration("delay", 0, "how long each batch stays in progress")
+	fail := flag.String("fail", "", "comma-separated custom IDs to answer with an error, e.g. job-12:cv")
+	expire := flag.String("expire", "", "comma-separated custom IDs left unfinished when their batch expires")
+	flag.Parse()
+
+	stub := llmstub.NewServer(*delay)
+	addIDs(stub.FailCustomIDs, *fail)
+	addIDs(stub.ExpireCustomIDs, *expire)
+
+	slog.Info("llm stub listening", "addr", *addr, "delay", delay.String())
+	if err := http.ListenAndServe(*addr, stub.Handler()); err != nil {
+		slog.Error("llm stub stopped", "error", err)
+		os.Exit(1)
+	}
+}
+
+func addIDs(set map[string]bool, list string) {
+	for _, id := range strings.Split(list, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			set[id] = true
+		}
+	}
+}
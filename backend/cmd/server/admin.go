@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/adyutaa/parsea/internal/config"
+	"github.com/adyutaa/parsea/internal/domain"
+	"github.com/adyutaa/parsea/internal/repository"
+	"github.com/adyutaa/parsea/internal/service"
+
+	"gorm.io/gorm"
+)
+
+const tenantUsage = `usage: server tenant <command>
+
+commands:
+  create [-name NAME] [-token-budget N] [-cost-budget USD] ID
+                            create a tenant; budgets default to TENANT_MONTHLY_*
+  list                      list tenants`
+
+const apiKeyUsage = `usage: server apikey <command>
+
+commands:
+  create [-name NAME] [-role member|admin|dpo] TENANT
+                                issue a key for a tenant and print it once
+  list TENANT                   list a tenant's keys
+  revoke ID                     stop a key from authenticating`
+
+// runTenant implements the tenant subcommand
+func runTenant(db *gorm.DB, defaults config.TenantsConfig, args []string) error {
+	if len(args) == 0 {
+		return errors.New(tenantUsage)
+	}
+	repo := repository.NewTenantRepository(db)
+
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("tenant create", flag.ContinueOnError)
+		name := fs.String("name", "", "display name (defaults to the ID)")
+		tokenBudget := fs.Int64("token-budget", defaults.MonthlyTokenBudget, "monthly token budget, 0 for unlimited")
+		costBudget := fs.Float64("cost-budget", defaults.MonthlyCostBudgetUSD, "monthly cost budget in USD, 0 for unlimited")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return errors.New(tenantUsage)
+		}
+		if *tokenBudget < 0 || *costBudget < 0 {
+			return errors.New("create: budgets cannot be negative")
+		}
+
+		tenant := domain.DefaultTenant(fs.Arg(0))
+		if *name != "" {
+			tenant.Name = *name
+		}
+		tenant.MonthlyTokenBudget = *tokenBudget
+		tenant.MonthlyCostBudgetUSD = *costBudget
+		if err := repo.Create(tenant); err != nil {
+			return fmt.Errorf("failed to create tenant: %w", err)
+		}
+		fmt.Println(tenant.ID)
+		return nil
+
+	case "list":
+		tenants, err := repo.List()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tTOKEN BUDGET\tCOST BUDGET USD\tCREATED AT")
+		for _, t := range tenants {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%.2f\t%s\n", t.ID, t.Name, t.MonthlyTokenBudget, t.MonthlyCostBudgetUSD, t.CreatedAt.Format(time.RFC3339))
+		}
+		return w.Flush()
+
+	default:
+		return fmt.Errorf("unknown tenant command %q\n%s", args[0], tenantUsage)
+	}
+}
+
+// runAPIKey implements the apikey subcommand
+func runAPIKey(db *gorm.DB, args []string) error {
+	if len(args) == 0 {
+		return errors.New(apiKeyUsage)
+	}
+	keys := service.NewAPIKeyService(repository.NewAPIKeyRepository(db), repository.NewTenantRepository(db))
+
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("apikey create", flag.ContinueOnError)
+		name := fs.String("name", "", "what the key is for")
+		roleName := fs.String("role", string(domain.RoleMember), "member; admin to change tenant settings and erase candidates; dpo to erase candidates")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return errors.New(apiKeyUsage)
+		}
+		role, err := domain.ParseAPIKeyRole(*roleName)
+		if err != nil {
+			return err
+		}
+
+		key, plain, err := keys.Create(fs.Arg(0), *name, role)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "created key %d for tenant %s; it is shown only once\n", key.ID, key.TenantID)
+		fmt.Println(plain)
+		return nil
+
+	case "list":
+		if len(args) != 2 {
+			return errors.New(apiKeyUsage)
+		}
+		list, err := keys.List(args[1])
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tPREFIX\tNAME\tROLE\tCREATED AT\tREVOKED AT")
+		for _, k := range list {
+			revoked := "-"
+			if k.RevokedAt != nil {
+				revoked = k.RevokedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", k.ID, k.Prefix, k.Name, k.Role, k.CreatedAt.Format(time.RFC3339), revoked)
+		}
+		return w.Flush()
+
+	case "revoke":
+		if len(args) != 2 {
+			return errors.New(apiKeyUsage)
+		}
+		id, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return fmt.Errorf("revoke: key ID must be a positive integer, got %q", args[1])
+		}
+		if err := keys.Revoke(uint(id)); err != nil {
+			return fmt.Errorf("failed to revoke key %d: %w", id, err)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown apikey command %q\n%s", args[0], apiKeyUsage)
+	}
+}
@@ -2,122 +2,293 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
+	"math"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 	"time"
 
+	"github.com/adyutaa/parsea/internal/config"
+	"github.com/adyutaa/parsea/internal/domain"
 	"github.com/adyutaa/parsea/internal/handler"
+	"github.com/adyutaa/parsea/internal/health"
 	"github.com/adyutaa/parsea/internal/infrastructure/llm"
 	"github.com/adyutaa/parsea/internal/infrastructure/vectordb"
+	"github.com/adyutaa/parsea/internal/logging"
+	"github.com/adyutaa/parsea/internal/metrics"
+	"github.com/adyutaa/parsea/internal/middleware"
+	"github.com/adyutaa/parsea/internal/queue"
 	"github.com/adyutaa/parsea/internal/repository"
 	"github.com/adyutaa/parsea/internal/service"
+	"github.com/adyutaa/parsea/internal/tracing"
 	"github.com/adyutaa/parsea/internal/worker"
+	"github.com/adyutaa/parsea/pkg/extract"
+	"github.com/adyutaa/parsea/pkg/pdf"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
+// debugJobsLimit caps how many jobs GET /debug/jobs returns
+const debugJobsLimit = 100
+
 func main() {
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "optional YAML config file; environment variables override it")
+	flag.Parse()
+
 	// Load environment variables
-	if err := godotenv.Load(); err != nil {
-		log.Println("⚠️  No .env file found, using environment variables")
+	envErr := godotenv.Load()
+
+	// "server migrate|tenant|apikey ..." only need the database
+	command := flag.Arg(0)
+	migrating := command == "migrate" || command == "tenant" || command == "apikey"
+
+	// Load and validate configuration before anything else runs
+	cfg, err := config.Load(*configPath)
+	if err == nil && migrating {
+		err = cfg.ValidateDatabase()
+	} else if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// Initialize logging before anything else writes
+	if err := logging.Init(logging.Config{Level: cfg.Log.Level, Format: cfg.Log.Format}); err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to initialize logging:", err)
+		os.Exit(1)
+	}
+	if envErr != nil {
+		slog.Info("no .env file found, using environment variables")
+	}
+	if !migrating {
+		slog.Info("effective configuration", "config", cfg.Redacted())
+	}
+
+	// Initialize tracing (spans are dropped unless an exporter is configured)
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		fatal("failed to initialize tracing", err)
 	}
 
 	// Initialize database connection
-	db, err := initDatabase()
+	db, err := initDatabase(cfg.Database)
 	if err != nil {
-		log.Fatal("Failed to initialize database:", err)
+		fatal("failed to initialize database", err)
+	}
+	slog.Info("connected to PostgreSQL")
+
+	switch command {
+	case "migrate":
+		if err := runMigrate(context.Background(), db, flag.Args()[1:]); err != nil {
+			fatal("migrate failed", err)
+		}
+		return
+	case "tenant", "apikey":
+		if err := ensureSchema(context.Background(), db, false); err != nil {
+			fatal("database schema check failed", err)
+		}
+		if command == "tenant" {
+			err = runTenant(db, cfg.Tenants, flag.Args()[1:])
+		} else {
+			err = runAPIKey(db, flag.Args()[1:])
+		}
+		if err != nil {
+			fatal(command+" failed", err)
+		}
+		return
+	}
+
+	// Refuse to serve against an outdated schema
+	if err := ensureSchema(context.Background(), db, cfg.Database.AutoMigrate); err != nil {
+		fatal("database schema check failed", err)
 	}
-	fmt.Println("✅ Connected to Supabase PostgreSQL!")
 
 	// Initialize Redis connection
-	rdb, err := initRedis()
+	rdb, err := initRedis(cfg.Redis)
 	if err != nil {
-		log.Fatal("Failed to initialize Redis:", err)
+		fatal("failed to initialize Redis", err)
 	}
-	fmt.Println("✅ Connected to Redis Cloud!")
+	slog.Info("connected to Redis", "addr", cfg.Redis.Addr())
 
 	// Initialize OpenAI client
-	openaiKey := os.Getenv("OPENAI_API_KEY")
-	if openaiKey == "" {
-		log.Fatal("OPENAI_API_KEY not set in environment")
-	}
-	llmClient := llm.NewOpenAIClient(openaiKey)
-	fmt.Println("✅ Connected to OpenAI!")
+	llmClient := llm.NewOpenAIClient(cfg.LLM)
+	slog.Info("OpenAI client configured")
 
 	// Initialize Qdrant (optional - will fallback if not configured)
 	var contextService *service.ContextService
-	qdrantClient, err := vectordb.NewQdrantClient()
-	if err != nil {
-		log.Printf("⚠️  Qdrant not available: %v (will use fallback context)\n", err)
-		contextService = nil
+	var qdrantClient *vectordb.QdrantClient
+	if !cfg.Qdrant.Enabled() {
+		slog.Info("Qdrant not configured, using fallback context")
+	} else if qdrantClient, err = vectordb.NewQdrantClient(cfg.Qdrant); err != nil {
+		slog.Warn("Qdrant not available, using fallback context", logging.Err(err))
+		qdrantClient = nil
 	} else {
-		fmt.Println("✅ Connected to Qdrant Cloud!")
+		slog.Info("connected to Qdrant", "host", cfg.Qdrant.Host, "tls", cfg.Qdrant.UseTLS)
 		contextService = service.NewContextService(qdrantClient, llmClient)
 	}
 
 	// Create uploads directory
-	uploadPath := getEnv("UPLOAD_PATH", "./uploads")
+	uploadPath := cfg.Server.UploadPath
 	if err := os.MkdirAll(uploadPath, os.ModePerm); err != nil {
-		log.Fatal("Failed to create uploads directory:", err)
+		fatal("failed to create uploads directory", err)
 	}
 
 	// Initialize repositories
 	docRepo := repository.NewDocumentRepository(db)
 	evalRepo := repository.NewEvaluationRepository(db)
+	tenantRepo := repository.NewTenantRepository(db)
+	usageRepo := repository.NewUsageRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	batchRepo := repository.NewBatchRepository(db)
+	erasureRepo := repository.NewErasureRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
 
 	// Initialize services
-	docService := service.NewDocumentService(docRepo, uploadPath)
-	evalService := service.NewEvaluationService(evalRepo, docRepo, rdb)
+	docService := service.NewDocumentService(docRepo, uploadPath, cfg.Server.MaxUploadBytes)
+	evalService := service.NewEvaluationService(evalRepo, docRepo, usageRepo, tenantRepo, rdb)
+	usageService := service.NewUsageService(usageRepo)
+	// The batch leaderboard and an opening's ranking score candidates alike
+	rankingWeights := domain.RankingWeights{
+		CVMatchRate:  cfg.Ranking.CVWeight,
+		ProjectScore: cfg.Ranking.ProjectWeight,
+	}
+	batchService := service.NewBatchService(batchRepo, docRepo, docService, evalService, rankingWeights)
+	reportService := service.NewReportService(evalRepo, docRepo)
+	rankingService := service.NewRankingService(evalRepo, docRepo, tenantRepo, evalService, llmClient, rankingWeights)
+	erasureService := service.NewErasureService(erasureRepo, qdrantClient)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, tenantRepo)
+	for _, p := range domain.Priorities {
+		name := queue.NameFor(p)
+		metrics.RegisterQueueDepth(name, func() (int64, error) { return evalService.GetQueueDepth(name) })
+	}
+	metrics.RegisterOutboxPending(outboxRepo.CountPending)
+	llmClient.SetUsageRecorder(usageService.Record)
 
 	// Initialize handlers
 	docHandler := handler.NewDocumentHandler(docService)
 	evalHandler := handler.NewEvaluationHandler(evalService)
+	tenantHandler := handler.NewTenantHandler(tenantRepo)
+	usageHandler := handler.NewUsageHandler(usageService)
+	batchHandler := handler.NewBatchHandler(batchService, cfg.Server.MaxBatchUploadBytes)
+	rankingHandler := handler.NewRankingHandler(rankingService)
+	reportHandler := handler.NewReportHandler(reportService)
+	erasureHandler := handler.NewErasureHandler(erasureService)
+
+	// Initialize text extractors (PDF falls back to OCR for scanned documents)
+	extractors := extract.NewRegistry(pdf.NewParserWithOCR(initOCRConfig(cfg.OCR)))
 
 	// Start background worker
-	evalWorker := worker.NewEvaluationWorker(rdb, evalRepo, docRepo, llmClient, contextService)
+	evalWorker := worker.NewEvaluationWorker(rdb, evalRepo, docRepo, tenantRepo, llmClient, contextService, extractors, cfg.Worker.JobTimeout, queue.Weights{
+		High:   cfg.Worker.QueueWeights.High,
+		Normal: cfg.Worker.QueueWeights.Normal,
+		Bulk:   cfg.Worker.QueueWeights.Bulk,
+	})
 	workerCtx, workerCancel := context.WithCancel(context.Background())
 	defer workerCancel()
 
+	// Evaluate bulk jobs through the provider's batch API; the runner takes
+	// the bulk queue over from the worker, so it is created before the worker starts
+	if cfg.LLM.Batch.Enabled {
+		llmBatchRepo := repository.NewLLMBatchRepository(db)
+		metrics.RegisterLLMBatchesInFlight(llmBatchRepo.CountSubmitted)
+		batchRunner := worker.NewLLMBatchRunner(evalWorker, llmBatchRepo, cfg.LLM.Batch.MaxJobs, cfg.LLM.Batch.CollectWindow, cfg.LLM.Batch.PollInterval)
+		go batchRunner.Start(workerCtx)
+	}
+
 	go evalWorker.Start(workerCtx)
-	fmt.Println("✅ Background worker started!")
+	slog.Info("background worker started")
+
+	// Publish committed jobs from the outbox to the queue
+	outboxRelay := worker.NewOutboxRelay(rdb, outboxRepo, cfg.Worker.OutboxPollInterval, cfg.Worker.OutboxBatchSize)
+	evalService.SetOutboxNotifier(outboxRelay.Notify)
+	go outboxRelay.Start(workerCtx)
+
+	// Queue scheduled jobs once their run_at passes
+	scheduler := worker.NewScheduler(evalWorker.ID(), evalRepo, cfg.Worker.SchedulerInterval, cfg.Worker.OutboxBatchSize, outboxRelay.Notify)
+	go scheduler.Start(workerCtx)
+
+	// Requeue jobs whose queue message was lost with a crashed worker
+	reaper := worker.NewReaper(evalWorker.ID(), rdb, evalRepo, cfg.Worker.ReapInterval, cfg.Worker.StaleAfter, cfg.Worker.OutboxBatchSize, outboxRelay.Notify)
+	go reaper.Start(workerCtx)
+
+	// Erase documents once they outlive their tenant's retention period
+	purger := worker.NewPurger(erasureService, cfg.Retention.PurgeInterval, cfg.Retention.BatchSize)
+	go purger.Start(workerCtx)
 
 	// Setup Gin router
 	gin.SetMode(gin.ReleaseMode)
-	r := gin.Default()
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.Use(middleware.RequestID())
 
 	// Add CORS middleware
 	r.Use(corsMiddleware())
+	// Every route but the probes and metrics needs an API key, which decides the tenant
+	r.Use(middleware.Authenticate(apiKeyService, "/livez", "/readyz", "/metrics"))
+	r.Use(middleware.Tracing())
+	r.Use(middleware.Metrics())
+	r.Use(middleware.RequestLogger())
 
-	// Health check endpoint
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status":   "healthy",
-			"database": "connected",
-			"redis":    "connected",
-			"llm":      "connected",
-			"qdrant":   contextService != nil,
-		})
-	})
+	// Liveness and readiness probes
+	healthHandler := handler.NewHealthHandler(initHealthChecker(cfg.Health, db, rdb, qdrantClient, llmClient))
+	r.GET("/livez", healthHandler.Livez)
+	r.GET("/readyz", healthHandler.Readyz)
+
+	// Prometheus scrape endpoint
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	// API routes
-	r.POST("/upload", docHandler.Upload)
-	r.POST("/evaluate", evalHandler.Evaluate)
+	// Throttle the endpoints that store files or spend LLM tokens
+	rateLimiter := middleware.NewRateLimiter(rdb, cfg.RateLimit.PerMinute, cfg.RateLimit.Burst)
+
+	// Replay responses for retried requests carrying an Idempotency-Key; it
+	// runs before the rate limiter so replays spend no tokens
+	idempotency := middleware.NewIdempotency(rdb, cfg.Idempotency.TTL, cfg.Idempotency.LockTimeout,
+		max(2*cfg.Server.MaxUploadBytes, cfg.Server.MaxBatchUploadBytes)+1<<20)
+
+	r.POST("/upload", idempotency.Handle("upload"), rateLimiter.Limit("upload"), docHandler.Upload)
+	r.GET("/documents/:id/profile", docHandler.GetProfile)
+	r.DELETE("/candidates/:id", middleware.RequireRole(domain.RoleAdmin, domain.RoleDPO), erasureHandler.EraseCandidate)
+	r.GET("/erasures", middleware.RequireRole(domain.RoleAdmin, domain.RoleDPO), erasureHandler.ListErasures)
+	r.POST("/evaluate", idempotency.Handle("evaluate"), rateLimiter.Limit("evaluate"), evalHandler.Evaluate)
 	r.GET("/result", evalHandler.GetResult)
+	r.GET("/evaluations/export.csv", reportHandler.ExportCSV)
+	r.GET("/evaluations/:id/report.pdf", reportHandler.GetPDF)
 	r.GET("/queue/status", evalHandler.GetQueueStatus)
-
-	// Debug endpoint
-	r.GET("/debug/jobs", func(c *gin.Context) {
+	r.POST("/batches", idempotency.Handle("batches"), rateLimiter.Limit("batches"), batchHandler.CreateBatch)
+	r.GET("/batches/:id", batchHandler.GetBatch)
+	r.GET("/openings/:title/ranking", rankingHandler.GetRanking)
+	r.POST("/compare", rateLimiter.Limit("compare"), rankingHandler.Compare)
+	r.GET("/usage", usageHandler.GetUsage)
+	r.GET("/settings", tenantHandler.GetSettings)
+	r.PUT("/settings", middleware.RequireRole(domain.RoleAdmin), tenantHandler.UpdateSettings)
+
+	// Debug endpoint: the newest jobs of the caller's tenant, for admin keys
+	r.GET("/debug/jobs", middleware.RequireRole(domain.RoleAdmin), func(c *gin.Context) {
 		var jobs []map[string]any
-		err := db.Table("evaluation_jobs").Find(&jobs).Error
+		err := db.Table("evaluation_jobs").
+			Where("tenant_id = ?", middleware.TenantID(c)).
+			Order("id DESC").
+			Limit(debugJobsLimit).
+			Find(&jobs).Error
 		if err != nil {
 			c.JSON(500, gin.H{"error": err.Error()})
 			return
@@ -129,18 +300,12 @@ func main() {
 	})
 
 	// Start server
-	port := getEnv("PORT", "8080")
-
-	fmt.Println("\n" + strings.Repeat("=", 60))
-	fmt.Printf("🚀 Server starting on http://localhost:%s\n", port)
-	fmt.Println(strings.Repeat("=", 60))
-	fmt.Println("\n📋 Available endpoints:")
-	fmt.Println("  GET    /health              - Health check")
-	fmt.Println("  POST   /upload              - Upload CV and Project Report")
-	fmt.Println("  POST   /evaluate            - Start evaluation job")
-	fmt.Println("  GET    /result?id=job_id    - Get evaluation result")
-	fmt.Println("  GET    /queue/status        - Get queue status")
-	fmt.Println()
+	addr := fmt.Sprintf(":%d", cfg.Server.Port)
+
+	for _, route := range r.Routes() {
+		slog.Debug("route registered", "method", route.Method, "path", route.Path)
+	}
+	slog.Info("server starting", "addr", addr)
 
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
@@ -148,24 +313,26 @@ func main() {
 
 	go func() {
 		<-quit
-		fmt.Println("\n👋 Shutting down server...")
+		slog.Info("shutting down server")
 		workerCancel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("failed to flush traces", logging.Err(err))
+		}
+		cancel()
+
 		os.Exit(0) // Force exit after 1 second
 	}()
 
-	if err := r.Run(":" + port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	if err := r.Run(addr); err != nil {
+		fatal("failed to start server", err)
 	}
 }
 
 // initDatabase initializes PostgreSQL connection with proper settings
-func initDatabase() (*gorm.DB, error) {
-	dsn := os.Getenv("DATABASE_URL")
-	if dsn == "" {
-		return nil, fmt.Errorf("DATABASE_URL not set in environment")
-	}
-
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+func initDatabase(cfg config.DatabaseConfig) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.Open(cfg.URL), &gorm.Config{
 		Logger:                                   logger.Default.LogMode(logger.Silent),
 		PrepareStmt:                              true, // Enable prepared statements for better performance
 		DisableForeignKeyConstraintWhenMigrating: true,
@@ -180,11 +347,11 @@ func initDatabase() (*gorm.DB, error) {
 		return nil, err
 	}
 
-	// Reasonable connection pooling settings
-	sqlDB.SetMaxIdleConns(10)                  // 10 idle connections
-	sqlDB.SetMaxOpenConns(25)                  // 25 max open connections
-	sqlDB.SetConnMaxLifetime(30 * time.Minute) // 30 minute connection lifetime
-	sqlDB.SetConnMaxIdleTime(5 * time.Minute)  // 5 minute idle timeout
+	// Connection pooling settings
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 
 	// Test connection
 	ctx := context.Background()
@@ -195,27 +362,20 @@ func initDatabase() (*gorm.DB, error) {
 	return db, nil
 }
 
-// initRedis initializes Redis connection
-func initRedis() (*redis.Client, error) {
-	host := os.Getenv("REDIS_HOST")
-	port := os.Getenv("REDIS_PORT")
-	username := os.Getenv("REDIS_USERNAME")
-	password := os.Getenv("REDIS_PASSWORD")
-
-	if host == "" || port == "" || password == "" {
-		return nil, fmt.Errorf("Redis configuration not set")
+// initRedis initializes Redis connection; username and password are optional
+// so a local Redis without AUTH works out of the box
+func initRedis(cfg config.RedisConfig) (*redis.Client, error) {
+	opts := &redis.Options{
+		Addr:     cfg.Addr(),
+		Username: cfg.Username,
+		Password: cfg.Password,
+		DB:       cfg.DB,
 	}
-
-	if username == "" {
-		username = "default"
+	if cfg.TLS {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12, ServerName: cfg.Host}
 	}
 
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     host + ":" + port,
-		Username: username,
-		Password: password,
-		DB:       0,
-	})
+	rdb := redis.NewClient(opts)
 
 	ctx := context.Background()
 	if err := rdb.Ping(ctx).Err(); err != nil {
@@ -225,12 +385,111 @@ func initRedis() (*redis.Client, error) {
 	return rdb, nil
 }
 
+// initHealthChecker wires the dependency probes behind /readyz
+func initHealthChecker(cfg config.HealthConfig, db *gorm.DB, rdb *redis.Client, qdrantClient *vectordb.QdrantClient, llmClient *llm.OpenAIService) *health.Checker {
+	timeout := cfg.Timeout
+
+	checks := []health.Check{
+		{
+			Name:     "postgres",
+			Critical: true,
+			Timeout:  timeout,
+			Probe: func(ctx context.Context) (map[string]any, error) {
+				sqlDB, err := db.DB()
+				if err != nil {
+					return nil, err
+				}
+				if err := sqlDB.PingContext(ctx); err != nil {
+					return nil, err
+				}
+				stats := sqlDB.Stats()
+				return map[string]any{"open_connections": stats.OpenConnections, "in_use": stats.InUse}, nil
+			},
+		},
+		{
+			Name:     "redis",
+			Critical: true,
+			Timeout:  timeout,
+			Probe: func(ctx context.Context) (map[string]any, error) {
+				return nil, rdb.Ping(ctx).Err()
+			},
+		},
+		{
+			Name:    "worker",
+			Timeout: timeout,
+			Probe: func(ctx context.Context) (map[string]any, error) {
+				beats, err := worker.Heartbeats(ctx, rdb)
+				if err != nil {
+					return nil, err
+				}
+
+				ages := make(map[string]any, len(beats))
+				fresh := 0
+				for id, at := range beats {
+					age := time.Since(at)
+					ages[id] = math.Round(age.Seconds()*10) / 10
+					if age <= 2*worker.HeartbeatInterval {
+						fresh++
+					}
+				}
+				details := map[string]any{"heartbeat_age_seconds": ages}
+				if fresh == 0 {
+					return details, fmt.Errorf("no worker heartbeat in the last %s", 2*worker.HeartbeatInterval)
+				}
+				return details, nil
+			},
+		},
+	}
+
+	if qdrantClient != nil {
+		checks = append(checks, health.Check{
+			Name:    "qdrant",
+			Timeout: timeout,
+			Probe: func(ctx context.Context) (map[string]any, error) {
+				version, err := qdrantClient.HealthCheck(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return map[string]any{"version": version}, nil
+			},
+		})
+	} else {
+		checks = append(checks, health.Disabled("qdrant"))
+	}
+
+	// The LLM probe calls an external API, so it is opt-in and cached
+	if cfg.CheckLLM {
+		checks = append(checks, health.Check{
+			Name:     "llm",
+			Timeout:  5 * time.Second,
+			CacheFor: time.Minute,
+			Probe: func(ctx context.Context) (map[string]any, error) {
+				return nil, llmClient.Ping(ctx)
+			},
+		})
+	} else {
+		checks = append(checks, health.Disabled("llm"))
+	}
+
+	return health.NewChecker(checks...)
+}
+
+// initOCRConfig maps the OCR fallback settings for scanned PDFs
+func initOCRConfig(cfg config.OCRConfig) pdf.OCRConfig {
+	ocr := pdf.DefaultOCRConfig()
+	ocr.Enabled = cfg.Enabled
+	ocr.Languages = cfg.Languages
+	ocr.PageTimeout = cfg.PageTimeout
+	return ocr
+}
+
 // corsMiddleware adds CORS headers
 func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Tenant-ID, X-API-Key, X-Request-ID, Idempotency-Key, traceparent, tracestate")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, Retry-After, Idempotent-Replayed")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -241,11 +500,8 @@ func corsMiddleware() gin.HandlerFunc {
 	}
 }
 
-// getEnv gets environment variable with default value
-func getEnv(key, defaultValue string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
-	}
-	return value
+// fatal logs a startup error and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, logging.Err(err))
+	os.Exit(1)
 }
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/adyutaa/parsea/internal/migrate"
+
+	"gorm.io/gorm"
+)
+
+const migrateUsage = `usage: server migrate <command>
+
+commands:
+  up          apply all pending migrations
+  down [n]    revert the last n migrations (default 1)
+  status      list migrations and when they were applied
+  version     print the current schema version`
+
+// runMigrate implements the migrate subcommand
+func runMigrate(ctx context.Context, db *gorm.DB, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	migrator, err := migrate.New(sqlDB)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		slog.InfoContext(ctx, "migrations complete", "applied", applied, "version", migrator.Latest())
+		return nil
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				return fmt.Errorf("down: step count must be a positive integer, got %q", args[1])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		slog.InfoContext(ctx, "migrations reverted", "reverted", reverted)
+		return nil
+
+	case "status":
+		entries, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, e := range entries {
+			applied := "pending"
+			if e.AppliedAt != nil {
+				applied = e.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", e.Version, e.Name, applied)
+		}
+		return w.Flush()
+
+	case "version":
+		version, err := migrator.Version(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Println(version)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown migrate command %q\n%s", args[0], migrateUsage)
+	}
+}
+
+// ensureSchema refuses to start the server on an outdated schema, or brings
+// it up to date first when auto-migrate is enabled
+func ensureSchema(ctx context.Context, db *gorm.DB, autoMigrate bool) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	migrator, err := migrate.New(sqlDB)
+	if err != nil {
+		return err
+	}
+
+	if autoMigrate {
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		if applied > 0 {
+			slog.InfoContext(ctx, "database migrated on startup", "applied", applied)
+		}
+	}
+
+	if err := migrator.Check(ctx); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "database schema is current", "version", migrator.Latest())
+	return nil
+}
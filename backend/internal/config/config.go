@@ -0,0 +1,397 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// Config is the complete service configuration. Values come from Default,
+// then the optional YAML file, then environment variables, which win.
+type Config struct {
+	Server      ServerConfig      `yaml:"server"`
+	Database    DatabaseConfig    `yaml:"database"`
+	Redis       RedisConfig       `yaml:"redis"`
+	Qdrant      QdrantConfig      `yaml:"qdrant"`
+	LLM         LLMConfig         `yaml:"llm"`
+	Worker      WorkerConfig      `yaml:"worker"`
+	Ranking     RankingConfig     `yaml:"ranking"`
+	Tenants     TenantsConfig     `yaml:"tenants"`
+	Retention   RetentionConfig   `yaml:"retention"`
+	OCR         OCRConfig         `yaml:"ocr"`
+	RateLimit   RateLimitConfig   `yaml:"rate_limit"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	Health      HealthConfig      `yaml:"health"`
+	Log         LogConfig         `yaml:"log"`
+	Tracing     TracingConfig     `yaml:"tracing"`
+}
+
+type ServerConfig struct {
+	Port           int    `yaml:"port" env:"PORT"`
+	UploadPath     string `yaml:"upload_path" env:"UPLOAD_PATH"`
+	MaxUploadBytes int64  `yaml:"max_upload_bytes" env:"MAX_UPLOAD_BYTES"`
+	// MaxBatchUploadBytes caps the zip archive accepted by POST /batches
+	MaxBatchUploadBytes int64 `yaml:"max_batch_upload_bytes" env:"MAX_BATCH_UPLOAD_BYTES"`
+}
+
+type DatabaseConfig struct {
+	URL             string        `yaml:"url" env:"DATABASE_URL" secret:"url"`
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DATABASE_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DATABASE_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DATABASE_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DATABASE_CONN_MAX_IDLE_TIME"`
+	// AutoMigrate applies pending migrations on server start instead of
+	// refusing to start
+	AutoMigrate bool `yaml:"auto_migrate" env:"DATABASE_AUTO_MIGRATE"`
+}
+
+type RedisConfig struct {
+	Host     string `yaml:"host" env:"REDIS_HOST"`
+	Port     int    `yaml:"port" env:"REDIS_PORT"`
+	Username string `yaml:"username" env:"REDIS_USERNAME"`
+	Password string `yaml:"password" env:"REDIS_PASSWORD" secret:"true"` // empty for a local Redis without AUTH
+	DB       int    `yaml:"db" env:"REDIS_DB"`
+	TLS      bool   `yaml:"tls" env:"REDIS_TLS"`
+}
+
+// QdrantConfig is optional; without a host the RAG steps use built-in context
+type QdrantConfig struct {
+	Host       string `yaml:"host" env:"QDRANT_HOST"`
+	Port       int    `yaml:"port" env:"QDRANT_PORT"` // gRPC port
+	APIKey     string `yaml:"api_key" env:"QDRANT_API_KEY" secret:"true"`
+	UseTLS     bool   `yaml:"use_tls" env:"QDRANT_USE_TLS"`
+	Collection string `yaml:"collection" env:"QDRANT_COLLECTION"`
+}
+
+type LLMConfig struct {
+	APIKey         string        `yaml:"api_key" env:"OPENAI_API_KEY" secret:"true"`
+	Timeout        time.Duration `yaml:"timeout" env:"LLM_TIMEOUT"`
+	SummaryTimeout time.Duration `yaml:"summary_timeout" env:"LLM_SUMMARY_TIMEOUT"`
+	// Batch sends bulk-priority jobs through the provider's batch API
+	Batch LLMBatchConfig `yaml:"batch"`
+}
+
+type LLMBatchConfig struct {
+	Enabled bool `yaml:"enabled" env:"LLM_BATCH_ENABLED"`
+	// BaseURL points batch calls at another OpenAI-compatible provider, such
+	// as the stub in cmd/llmstub; empty uses OpenAI
+	BaseURL string `yaml:"base_url" env:"LLM_BATCH_BASE_URL"`
+	// MaxJobs caps how many jobs go into one provider batch
+	MaxJobs int `yaml:"max_jobs" env:"LLM_BATCH_MAX_JOBS"`
+	// CollectWindow is how long queued bulk jobs are gathered before a
+	// batch smaller than MaxJobs is submitted
+	CollectWindow time.Duration `yaml:"collect_window" env:"LLM_BATCH_COLLECT_WINDOW"`
+	// PollInterval is how often a submitted batch is checked for completion
+	PollInterval time.Duration `yaml:"poll_interval" env:"LLM_BATCH_POLL_INTERVAL"`
+}
+
+type WorkerConfig struct {
+	JobTimeout time.Duration `yaml:"job_timeout" env:"WORKER_JOB_TIMEOUT"`
+	// OutboxPollInterval bounds how long a committed job can wait before the
+	// relay publishes it when the in-process wake-up is missed
+	OutboxPollInterval time.Duration `yaml:"outbox_poll_interval" env:"OUTBOX_POLL_INTERVAL"`
+	OutboxBatchSize    int           `yaml:"outbox_batch_size" env:"OUTBOX_BATCH_SIZE"`
+	// SchedulerInterval is how often scheduled jobs are checked for a passed run_at
+	SchedulerInterval time.Duration `yaml:"scheduler_interval" env:"SCHEDULER_INTERVAL"`
+	// ReapInterval is how often jobs lost by a crashed worker are looked for
+	ReapInterval time.Duration `yaml:"reap_interval" env:"WORKER_REAP_INTERVAL"`
+	// StaleAfter is how long a job must go unchanged before it can be
+	// requeued, so it must exceed the heartbeat TTL and the outbox delay
+	StaleAfter time.Duration `yaml:"stale_after" env:"WORKER_STALE_AFTER"`
+	// QueueWeights sets how often each priority queue is checked first
+	QueueWeights QueueWeightsConfig `yaml:"queue_weights"`
+}
+
+type QueueWeightsConfig struct {
+	High   int `yaml:"high" env:"QUEUE_WEIGHT_HIGH"`
+	Normal int `yaml:"normal" env:"QUEUE_WEIGHT_NORMAL"`
+	Bulk   int `yaml:"bulk" env:"QUEUE_WEIGHT_BULK"`
+}
+
+// RankingConfig sets the default weights of the composite score that
+// GET /openings/:title/ranking orders candidates by
+type RankingConfig struct {
+	CVWeight      float64 `yaml:"cv_weight" env:"RANKING_CV_WEIGHT"`
+	ProjectWeight float64 `yaml:"project_weight" env:"RANKING_PROJECT_WEIGHT"`
+}
+
+// TenantsConfig holds the monthly LLM budgets given to tenants created with
+// "server tenant create"; admins can change them later
+type TenantsConfig struct {
+	MonthlyTokenBudget   int64   `yaml:"monthly_token_budget" env:"TENANT_MONTHLY_TOKEN_BUDGET"`
+	MonthlyCostBudgetUSD float64 `yaml:"monthly_cost_budget_usd" env:"TENANT_MONTHLY_COST_BUDGET_USD"`
+}
+
+// RetentionConfig controls the purger that erases documents past their
+// tenant's retention period; the period itself is a tenant setting
+type RetentionConfig struct {
+	PurgeInterval time.Duration `yaml:"purge_interval" env:"RETENTION_PURGE_INTERVAL"`
+	// BatchSize caps how many documents one purge pass erases
+	BatchSize int `yaml:"batch_size" env:"RETENTION_BATCH_SIZE"`
+}
+
+type OCRConfig struct {
+	Enabled     bool          `yaml:"enabled" env:"OCR_ENABLED"`
+	Languages   []string      `yaml:"languages" env:"OCR_LANGUAGES" sep:"+"`
+	PageTimeout time.Duration `yaml:"page_timeout" env:"OCR_PAGE_TIMEOUT"`
+}
+
+type RateLimitConfig struct {
+	PerMinute int `yaml:"per_minute" env:"RATE_LIMIT_PER_MINUTE"`
+	Burst     int `yaml:"burst" env:"RATE_LIMIT_BURST"`
+}
+
+type IdempotencyConfig struct {
+	// TTL is how long a response is replayed for a repeated Idempotency-Key
+	TTL time.Duration `yaml:"ttl" env:"IDEMPOTENCY_TTL"`
+	// LockTimeout releases the key of a request that never finished
+	LockTimeout time.Duration `yaml:"lock_timeout" env:"IDEMPOTENCY_LOCK_TIMEOUT"`
+}
+
+type HealthConfig struct {
+	Timeout  time.Duration `yaml:"timeout" env:"HEALTH_CHECK_TIMEOUT"`
+	CheckLLM bool          `yaml:"check_llm" env:"HEALTH_CHECK_LLM"`
+}
+
+type LogConfig struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL"`
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+}
+
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env:"OTEL_TRACES_EXPORTER"`
+	Endpoint    string  `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	Insecure    bool    `yaml:"insecure" env:"OTEL_EXPORTER_OTLP_INSECURE"`
+	ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"OTEL_TRACES_SAMPLER_ARG"`
+}
+
+// Default returns the configuration used when nothing overrides it
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:                8080,
+			UploadPath:          "./uploads",
+			MaxUploadBytes:      10 * 1024 * 1024,
+			MaxBatchUploadBytes: 100 * 1024 * 1024,
+		},
+		Database: DatabaseConfig{
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+		},
+		Redis: RedisConfig{
+			Host: "localhost",
+			Port: 6379,
+		},
+		Qdrant: QdrantConfig{
+			Port:       6334,
+			UseTLS:     true,
+			Collection: "job_requirements",
+		},
+		LLM: LLMConfig{
+			Timeout:        60 * time.Second,
+			SummaryTimeout: 45 * time.Second,
+			Batch: LLMBatchConfig{
+				MaxJobs:       200,
+				CollectWindow: time.Minute,
+				PollInterval:  30 * time.Second,
+			},
+		},
+		Worker: WorkerConfig{
+			JobTimeout:         5 * time.Minute,
+			OutboxPollInterval: time.Second,
+			OutboxBatchSize:    100,
+			SchedulerInterval:  5 * time.Second,
+			ReapInterval:       time.Minute,
+			StaleAfter:         2 * time.Minute,
+			QueueWeights:       QueueWeightsConfig{High: 6, Normal: 3, Bulk: 1},
+		},
+		Ranking: RankingConfig{
+			CVWeight:      0.5,
+			ProjectWeight: 0.5,
+		},
+		Tenants: TenantsConfig{
+			MonthlyTokenBudget:   2000000,
+			MonthlyCostBudgetUSD: 20,
+		},
+		Retention: RetentionConfig{
+			PurgeInterval: time.Hour,
+			BatchSize:     100,
+		},
+		OCR: OCRConfig{
+			Enabled:     true,
+			Languages:   []string{"eng"},
+			PageTimeout: 30 * time.Second,
+		},
+		RateLimit: RateLimitConfig{
+			PerMinute: 30,
+			Burst:     10,
+		},
+		Idempotency: IdempotencyConfig{
+			TTL:         24 * time.Hour,
+			LockTimeout: 5 * time.Minute,
+		},
+		Health: HealthConfig{
+			Timeout: 2 * time.Second,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			ServiceName: "parsea",
+			SampleRatio: 1,
+		},
+	}
+}
+
+// Load builds the configuration from defaults, the YAML file at path (if
+// any) and the environment. Callers must Validate the result before use;
+// tools that need only part of it can check those fields themselves.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := yaml.UnmarshalWithOptions(data, cfg, yaml.Strict()); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Validate reports every invalid setting at once
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(validPort(c.Server.Port), "server.port (PORT) must be between 1 and 65535")
+	check(c.Server.UploadPath != "", "server.upload_path (UPLOAD_PATH) is required")
+	check(c.Server.MaxUploadBytes > 0, "server.max_upload_bytes (MAX_UPLOAD_BYTES) must be positive")
+	check(c.Server.MaxBatchUploadBytes > 0, "server.max_batch_upload_bytes (MAX_BATCH_UPLOAD_BYTES) must be positive")
+
+	c.Database.validate(check)
+
+	check(c.Redis.Host != "", "redis.host (REDIS_HOST) is required")
+	check(validPort(c.Redis.Port), "redis.port (REDIS_PORT) must be between 1 and 65535")
+	check(c.Redis.DB >= 0, "redis.db (REDIS_DB) cannot be negative")
+
+	if c.Qdrant.Enabled() {
+		check(validPort(c.Qdrant.Port), "qdrant.port (QDRANT_PORT) must be between 1 and 65535")
+		check(c.Qdrant.Collection != "", "qdrant.collection (QDRANT_COLLECTION) is required")
+	}
+
+	check(c.LLM.APIKey != "", "llm.api_key (OPENAI_API_KEY) is required")
+	check(c.LLM.Timeout > 0, "llm.timeout (LLM_TIMEOUT) must be positive")
+	check(c.LLM.SummaryTimeout > 0, "llm.summary_timeout (LLM_SUMMARY_TIMEOUT) must be positive")
+	if c.LLM.Batch.Enabled {
+		// The provider accepts up to 50,000 requests per batch, two per job
+		check(c.LLM.Batch.MaxJobs > 0 && c.LLM.Batch.MaxJobs <= 25000, "llm.batch.max_jobs (LLM_BATCH_MAX_JOBS) must be between 1 and 25000")
+		check(c.LLM.Batch.CollectWindow > 0, "llm.batch.collect_window (LLM_BATCH_COLLECT_WINDOW) must be positive")
+		check(c.LLM.Batch.PollInterval > 0, "llm.batch.poll_interval (LLM_BATCH_POLL_INTERVAL) must be positive")
+	}
+
+	check(c.Worker.JobTimeout > 0, "worker.job_timeout (WORKER_JOB_TIMEOUT) must be positive")
+	check(c.Worker.OutboxPollInterval > 0, "worker.outbox_poll_interval (OUTBOX_POLL_INTERVAL) must be positive")
+	check(c.Worker.OutboxBatchSize > 0, "worker.outbox_batch_size (OUTBOX_BATCH_SIZE) must be positive")
+	check(c.Worker.SchedulerInterval > 0, "worker.scheduler_interval (SCHEDULER_INTERVAL) must be positive")
+	check(c.Worker.ReapInterval > 0, "worker.reap_interval (WORKER_REAP_INTERVAL) must be positive")
+	check(c.Worker.StaleAfter >= 30*time.Second, "worker.stale_after (WORKER_STALE_AFTER) must be at least 30s")
+	w := c.Worker.QueueWeights
+	check(w.High >= 0 && w.Normal >= 0 && w.Bulk >= 0, "worker.queue_weights cannot be negative")
+	check(w.High+w.Normal+w.Bulk > 0, "worker.queue_weights must not all be zero")
+
+	check(c.Ranking.CVWeight >= 0 && c.Ranking.ProjectWeight >= 0, "ranking weights (RANKING_CV_WEIGHT, RANKING_PROJECT_WEIGHT) cannot be negative")
+	check(c.Ranking.CVWeight+c.Ranking.ProjectWeight > 0, "ranking weights (RANKING_CV_WEIGHT, RANKING_PROJECT_WEIGHT) must not both be zero")
+
+	check(c.Tenants.MonthlyTokenBudget >= 0, "tenants.monthly_token_budget (TENANT_MONTHLY_TOKEN_BUDGET) cannot be negative")
+	check(c.Tenants.MonthlyCostBudgetUSD >= 0, "tenants.monthly_cost_budget_usd (TENANT_MONTHLY_COST_BUDGET_USD) cannot be negative")
+
+	check(c.Retention.PurgeInterval > 0, "retention.purge_interval (RETENTION_PURGE_INTERVAL) must be positive")
+	check(c.Retention.BatchSize > 0, "retention.batch_size (RETENTION_BATCH_SIZE) must be positive")
+
+	if c.OCR.Enabled {
+		check(len(c.OCR.Languages) > 0, "ocr.languages (OCR_LANGUAGES) is required when OCR is enabled")
+		check(c.OCR.PageTimeout > 0, "ocr.page_timeout (OCR_PAGE_TIMEOUT) must be positive")
+	}
+
+	check(c.RateLimit.PerMinute > 0, "rate_limit.per_minute (RATE_LIMIT_PER_MINUTE) must be positive")
+	check(c.RateLimit.Burst > 0, "rate_limit.burst (RATE_LIMIT_BURST) must be positive")
+
+	check(c.Idempotency.TTL > 0, "idempotency.ttl (IDEMPOTENCY_TTL) must be positive")
+	check(c.Idempotency.LockTimeout > 0, "idempotency.lock_timeout (IDEMPOTENCY_LOCK_TIMEOUT) must be positive")
+
+	check(c.Health.Timeout > 0, "health.timeout (HEALTH_CHECK_TIMEOUT) must be positive")
+
+	check(oneOf(c.Log.Level, "debug", "info", "warn", "error"), "log.level (LOG_LEVEL) must be debug, info, warn or error")
+	check(oneOf(c.Log.Format, "json", "text"), "log.format (LOG_FORMAT) must be json or text")
+
+	check(oneOf(c.Tracing.Exporter, "none", "stdout", "otlp"), "tracing.exporter (OTEL_TRACES_EXPORTER) must be none, stdout or otlp")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio (OTEL_TRACES_SAMPLER_ARG) must be between 0 and 1")
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// ValidateDatabase checks only the settings the migrate command needs
+func (c *Config) ValidateDatabase() error {
+	var errs []error
+	c.Database.validate(func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	})
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func (d DatabaseConfig) validate(check func(ok bool, format string, args ...any)) {
+	check(d.URL != "", "database.url (DATABASE_URL) is required")
+	check(d.MaxOpenConns > 0, "database.max_open_conns must be positive")
+	check(d.MaxIdleConns >= 0, "database.max_idle_conns cannot be negative")
+}
+
+// Enabled reports whether a Qdrant server is configured
+func (q QdrantConfig) Enabled() bool {
+	return q.Host != ""
+}
+
+// Addr returns host:port for the Redis client
+func (r RedisConfig) Addr() string {
+	return fmt.Sprintf("%s:%d", r.Host, r.Port)
+}
+
+func validPort(port int) bool {
+	return port > 0 && port <= 65535
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// APIKeyRole decides what a key may do within its tenant
+type APIKeyRole string
+
+const (
+	// RoleMember may upload, evaluate and read results
+	RoleMember APIKeyRole = "member"
+	// RoleAdmin may also change tenant settings and budgets and erase candidates
+	RoleAdmin APIKeyRole = "admin"
+	// RoleDPO is a member that may also erase candidates and read the
+	// erasure audit trail, e.g. for a data protection officer
+	RoleDPO APIKeyRole = "dpo"
+)
+
+// ParseAPIKeyRole validates a role name
+func ParseAPIKeyRole(s string) (APIKeyRole, error) {
+	switch role := APIKeyRole(s); role {
+	case RoleMember, RoleAdmin, RoleDPO:
+		return role, nil
+	}
+	return "", fmt.Errorf("role must be %s, %s or %s, got %q", RoleMember, RoleAdmin, RoleDPO, s)
+}
+
+// APIKey authenticates requests for one tenant. The key itself is shown once
+// when it is created; only its SHA-256 hash is stored.
+type APIKey struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	TenantID  string     `json:"tenant_id"`
+	Name      string     `json:"name"`
+	Role      APIKeyRole `json:"role"`
+	Prefix    string     `json:"prefix"` // leading characters of the key, to tell keys apart
+	KeyHash   string     `json:"-"`
+	CreatedAt time.Time  `json:"created_at" gorm:"default:now()"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Label names the key in logs and audit records without revealing it
+func (k *APIKey) Label() string {
+	if k.Name == "" {
+		return k.Prefix
+	}
+	return k.Name + " (" + k.Prefix + ")"
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
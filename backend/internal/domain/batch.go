@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+// Batch groups the evaluation jobs of many candidates for one opening
+type Batch struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID  string    `json:"tenant_id" gorm:"default:'default'"`
+	JobTitle  string    `json:"job_title" gorm:"not null"`
+	Total     int       `json:"total"`
+	CreatedAt time.Time `json:"created_at" gorm:"default:now()"`
+}
+
+func (Batch) TableName() string {
+	return "batches"
+}
+
+// BatchProgress aggregates the status of a batch's jobs
+type BatchProgress struct {
+	Total      int               `json:"total"`
+	Finished   int               `json:"finished"`
+	Percent    float64           `json:"percent"`
+	ByStatus   map[JobStatus]int `json:"by_status"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+}
+
+// Done reports whether every job in the batch reached a terminal status
+func (p BatchProgress) Done() bool {
+	return p.Finished == p.Total
+}
+
+// LeaderboardEntry is one ranked candidate of a finished batch
+type LeaderboardEntry struct {
+	Rank           int            `json:"rank"`
+	JobID          uint           `json:"job_id"`
+	CVID           uint           `json:"cv_id"`
+	ReportID       uint           `json:"report_id"`
+	Candidate      string         `json:"candidate"` // CV filename; <candidate>/<file> for zip uploads; empty once erased
+	Score          float64        `json:"score"`
+	CVMatchRate    float64        `json:"cv_match_rate" gorm:"column:cv_match_rate"`
+	ProjectScore   float64        `json:"project_score"`
+	Recommendation Recommendation `json:"recommendation"`
+}
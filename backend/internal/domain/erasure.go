@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ErasureReason records why candidate data was erased
+type ErasureReason string
+
+const (
+	// ErasureRequest is an erasure asked for through DELETE /candidates/:id
+	ErasureRequest ErasureReason = "request"
+	// ErasureRetention is a purge of documents past the tenant's retention period
+	ErasureRetention ErasureReason = "retention"
+)
+
+// ErasureActorPurger is the actor of retention purges
+const ErasureActorPurger = "retention purger"
+
+// Erasure is the audit record of one erasure. It lists what was deleted,
+// never the personal data itself.
+type Erasure struct {
+	ID       uint          `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID string        `json:"tenant_id" gorm:"not null"`
+	Reason   ErasureReason `json:"reason" gorm:"not null"`
+	// CandidateID is the CV named in the request; nil for retention purges
+	CandidateID *uint  `json:"candidate_id,omitempty"`
+	RequestID   string `json:"request_id,omitempty"`
+	// APIKeyID is the key that requested the erasure; nil for retention
+	// purges. Actor labels the key, or names the purger.
+	APIKeyID *uint  `json:"api_key_id,omitempty"`
+	Actor    string `json:"actor"`
+
+	DocumentIDs      IDList `json:"document_ids" gorm:"type:jsonb"`
+	DocumentsDeleted int    `json:"documents_deleted"`
+	FilesDeleted     int    `json:"files_deleted"`
+	VectorsDeleted   int    `json:"vectors_deleted"`
+	JobsAnonymised   int    `json:"jobs_anonymised"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"default:now()"`
+}
+
+func (Erasure) TableName() string {
+	return "erasures"
+}
+
+// IDList is a list of row IDs stored as a jsonb array
+type IDList []uint
+
+func (l IDList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	return json.Marshal(l)
+}
+
+func (l *IDList) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("unsupported IDList type %T", value)
+	}
+	return json.Unmarshal(bytes, l)
+}
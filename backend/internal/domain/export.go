@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// JobFilter selects a tenant's jobs for export; zero fields match everything
+type JobFilter struct {
+	TenantID       string
+	Status         JobStatus
+	JobTitle       string // matched case-insensitively
+	Priority       Priority
+	Recommendation Recommendation
+	BatchID        *uint
+	From, To       *time.Time // created_at range, from inclusive, to exclusive
+}
+
+// JobExportRow is one job of a CSV export
+type JobExportRow struct {
+	ID              uint
+	JobTitle        string
+	Status          JobStatus
+	Priority        Priority
+	Blind           bool
+	BatchID         *uint
+	CVID            *uint  // nil once the candidate is erased
+	Candidate       string // CV filename; empty once the document is deleted
+	ReportID        *uint
+	CVMatchRate     *float64 `gorm:"column:cv_match_rate"`
+	ProjectScore    *float64
+	Recommendation  Recommendation
+	CVFeedback      string
+	ProjectFeedback string
+	OverallSummary  string
+	ErrorMessage    string
+	RunAt           *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	ErasedAt        *time.Time
+}
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// LLMBatchStage is the part of the pipeline a provider batch runs
+type LLMBatchStage string
+
+const (
+	// LLMBatchEvaluate holds the CV and project evaluations of each job
+	LLMBatchEvaluate LLMBatchStage = "evaluate"
+	// LLMBatchSummarize holds the summaries, built from the evaluate results
+	LLMBatchSummarize LLMBatchStage = "summarize"
+)
+
+type LLMBatchStatus string
+
+const (
+	LLMBatchSubmitted LLMBatchStatus = "submitted"
+	LLMBatchCompleted LLMBatchStatus = "completed"
+	LLMBatchFailed    LLMBatchStatus = "failed"
+)
+
+// LLMBatch is a batch submitted to the LLM provider's batch API
+type LLMBatch struct {
+	ID              uint           `json:"id" gorm:"primaryKey;autoIncrement"`
+	ProviderBatchID string         `json:"provider_batch_id" gorm:"not null"`
+	Stage           LLMBatchStage  `json:"stage" gorm:"not null"`
+	Status          LLMBatchStatus `json:"status" gorm:"default:'submitted'"`
+	RequestCount    int            `json:"request_count"`
+	ErrorMessage    string         `json:"error_message,omitempty"`
+	// NextPollAt is when the batch is next checked; claiming a batch pushes
+	// it forward so only one worker polls it at a time
+	NextPollAt  time.Time  `json:"next_poll_at"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"default:now()"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+func (LLMBatch) TableName() string {
+	return "llm_batches"
+}
+
+// LLMBatchItem is one job's part in a provider batch
+type LLMBatchItem struct {
+	BatchID uint           `json:"batch_id" gorm:"primaryKey"`
+	JobID   uint           `json:"job_id" gorm:"primaryKey"`
+	State   *LLMBatchState `json:"-" gorm:"type:jsonb"`
+}
+
+func (LLMBatchItem) TableName() string {
+	return "llm_batch_items"
+}
+
+// LLMBatchState is what a job carries from one batch stage to the next. It
+// is cleared once the job finishes.
+type LLMBatchState struct {
+	// Redactions maps PII placeholders to their originals, so the model's
+	// feedback can be restored when the results arrive
+	Redactions    map[string]string        `json:"redactions,omitempty"`
+	CVResult      *CVEvaluationResult      `json:"cv_result,omitempty"`
+	ProjectResult *ProjectEvaluationResult `json:"project_result,omitempty"`
+}
+
+func (s LLMBatchState) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+func (s *LLMBatchState) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for LLMBatchState: %T", value)
+	}
+
+	return json.Unmarshal(bytes, s)
+}
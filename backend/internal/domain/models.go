@@ -8,11 +8,18 @@ import (
 
 type Document struct {
 	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID   string    `json:"tenant_id" gorm:"default:'default'"`
 	Filename   string    `json:"filename" gorm:"not null"`
 	FilePath   string    `json:"file_path" gorm:"not null"`
 	DocType    string    `json:"doc_type" gorm:"not null"`
+	MimeType   string    `json:"mime_type"`
 	FileSize   int64     `json:"file_size"`
 	UploadedAt time.Time `json:"uploaded_at" gorm:"default:now()"`
+
+	ExtractionStrategy   string   `json:"extraction_strategy,omitempty"`
+	ExtractionConfidence *float64 `json:"extraction_confidence,omitempty"`
+
+	Profile *CandidateProfile `json:"profile,omitempty" gorm:"type:jsonb"`
 }
 
 func (Document) TableName() string {
@@ -33,7 +40,7 @@ func (j *JSON) Scan(value interface{}) error {
 		*j = nil
 		return nil
 	}
-	
+
 	var bytes []byte
 	switch v := value.(type) {
 	case []byte:
@@ -43,30 +50,44 @@ func (j *JSON) Scan(value interface{}) error {
 	default:
 		return nil
 	}
-	
+
 	return json.Unmarshal(bytes, j)
 }
 
 type EvaluationJob struct {
-	ID           uint      `json:"id" gorm:"primaryKey;autoIncrement"`
-	CVID         uint      `json:"cv_id" gorm:"not null"`
-	ReportID     uint      `json:"report_id" gorm:"not null"`
-	JobTitle     string    `json:"job_title" gorm:"not null"`
-	Status       string    `json:"status" gorm:"default:'queued'"` // queued, processing, completed, failed
-	Result       JSON      `json:"result,omitempty" gorm:"type:jsonb"`
-	ErrorMessage string    `json:"error_message,omitempty"`
-	CreatedAt    time.Time `json:"created_at" gorm:"default:now()"`
-	UpdatedAt    time.Time `json:"updated_at" gorm:"default:now()"`
+	ID       uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID string `json:"tenant_id" gorm:"default:'default'"`
+	// CVID and ReportID are zero once the candidate's documents are erased
+	CVID     uint      `json:"cv_id"`
+	ReportID uint      `json:"report_id"`
+	JobTitle string    `json:"job_title" gorm:"not null"`
+	Status   JobStatus `json:"status" gorm:"default:'queued'"`
+	// Version increments on every status change, for optimistic concurrency
+	Version int               `json:"version"`
+	Result  *EvaluationResult `json:"result,omitempty" gorm:"type:jsonb"`
+	// Typed copies of the result scores, for querying without unpacking the jsonb
+	CVMatchRate  *float64 `json:"cv_match_rate,omitempty" gorm:"column:cv_match_rate"`
+	ProjectScore *float64 `json:"project_score,omitempty"`
+	// Recommendation stays NULL until the job is scored; the column rejects ''
+	Recommendation Recommendation `json:"recommendation,omitempty" gorm:"default:null"`
+	ErrorMessage   string         `json:"error_message,omitempty"`
+	// RedactionCounts holds the number of PII values replaced per kind, nil when redaction was off
+	RedactionCounts JSON `json:"redaction_counts,omitempty" gorm:"type:jsonb"`
+	// Blind marks jobs evaluated with protected attributes removed; BlindCounts records what was removed
+	Blind bool `json:"blind"`
+	// Priority selects the queue: high, normal or bulk
+	Priority Priority `json:"priority" gorm:"default:'normal'"`
+	// RunAt is when a scheduled job is queued; nil for jobs queued immediately
+	RunAt *time.Time `json:"run_at,omitempty"`
+	// BatchID links jobs created by POST /batches
+	BatchID     *uint `json:"batch_id,omitempty"`
+	BlindCounts JSON  `json:"blind_counts,omitempty" gorm:"type:jsonb"`
+	// ErasedAt is set when the candidate's data was erased; only the scores remain
+	ErasedAt  *time.Time `json:"erased_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at" gorm:"default:now()"`
+	UpdatedAt time.Time  `json:"updated_at" gorm:"default:now()"`
 }
 
 func (EvaluationJob) TableName() string {
 	return "evaluation_jobs"
 }
-
-type EvaluationResult struct {
-	CVMatchRate     float64 `json:"cv_match_rate"`
-	CVFeedback      string  `json:"cv_feedback"`
-	ProjectScore    float64 `json:"project_score"`
-	ProjectFeedback string  `json:"project_feedback"`
-	OverallSummary  string  `json:"overall_summary"`
-}
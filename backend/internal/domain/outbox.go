@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// OutboxMessage is a queue message written in the same transaction as the
+// change that produced it, and published to Redis afterwards by the relay
+type OutboxMessage struct {
+	ID        uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Queue     string     `json:"queue" gorm:"not null"`
+	Payload   string     `json:"payload" gorm:"not null"`
+	Attempts  int        `json:"attempts"`
+	LastError string     `json:"last_error,omitempty"`
+	CreatedAt time.Time  `json:"created_at" gorm:"default:now()"`
+	SentAt    *time.Time `json:"sent_at,omitempty"`
+}
+
+func (OutboxMessage) TableName() string {
+	return "outbox"
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "fmt"
+
+// Priority selects which evaluation queue a job goes to
+type Priority string
+
+const (
+	PriorityHigh   Priority = "high"
+	PriorityNormal Priority = "normal"
+	PriorityBulk   Priority = "bulk"
+)
+
+// Priorities lists every priority, most urgent first
+var Priorities = []Priority{PriorityHigh, PriorityNormal, PriorityBulk}
+
+// ParsePriority validates a priority name; an empty name means normal
+func ParsePriority(name string) (Priority, error) {
+	if name == "" {
+		return PriorityNormal, nil
+	}
+	for _, p := range Priorities {
+		if string(p) == name {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("priority must be one of high, normal or bulk")
+}
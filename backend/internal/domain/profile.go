@@ -0,0 +1,160 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CandidateProfile is the typed form of a CV, extracted by the LLM
+type CandidateProfile struct {
+	Contact         ContactInfo      `json:"contact"`
+	Headline        string           `json:"headline,omitempty"`
+	YearsExperience float64          `json:"years_experience"`
+	Skills          []Skill          `json:"skills"`
+	WorkHistory     []WorkExperience `json:"work_history"`
+	Education       []Education      `json:"education"`
+	Links           []Link           `json:"links"`
+}
+
+type ContactInfo struct {
+	Name     string `json:"name,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Phone    string `json:"phone,omitempty"`
+	Location string `json:"location,omitempty"`
+}
+
+type Skill struct {
+	Name  string  `json:"name"`
+	Years float64 `json:"years,omitempty"`
+}
+
+// WorkExperience dates use YYYY-MM; an empty EndDate with Current set means ongoing
+type WorkExperience struct {
+	Company     string `json:"company"`
+	Title       string `json:"title"`
+	StartDate   string `json:"start_date,omitempty"`
+	EndDate     string `json:"end_date,omitempty"`
+	Current     bool   `json:"current,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type Education struct {
+	Institution string `json:"institution"`
+	Degree      string `json:"degree,omitempty"`
+	Field       string `json:"field,omitempty"`
+	StartDate   string `json:"start_date,omitempty"`
+	EndDate     string `json:"end_date,omitempty"`
+}
+
+type Link struct {
+	Type string `json:"type"` // github, linkedin, portfolio, other
+	URL  string `json:"url"`
+}
+
+func (p CandidateProfile) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+func (p *CandidateProfile) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for CandidateProfile: %T", value)
+	}
+
+	return json.Unmarshal(bytes, p)
+}
+
+// ComputeYearsExperience sums the work history, counting overlapping roles once
+func (p *CandidateProfile) ComputeYearsExperience(now time.Time) float64 {
+	type span struct{ start, end time.Time }
+
+	var spans []span
+	for _, w := range p.WorkHistory {
+		start, err := parseProfileDate(w.StartDate)
+		if err != nil {
+			continue
+		}
+		end, err := parseProfileDate(w.EndDate)
+		if err != nil {
+			if !w.Current && w.EndDate != "" {
+				continue
+			}
+			end = now
+		}
+		if end.After(start) {
+			spans = append(spans, span{start, end})
+		}
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start.Before(spans[j].start) })
+
+	var total time.Duration
+	var current *span
+	for i := range spans {
+		s := spans[i]
+		if current == nil || s.start.After(current.end) {
+			if current != nil {
+				total += current.end.Sub(current.start)
+			}
+			current = &s
+			continue
+		}
+		if s.end.After(current.end) {
+			current.end = s.end
+		}
+	}
+	if current != nil {
+		total += current.end.Sub(current.start)
+	}
+
+	return float64(int(total.Hours()/24/365.25*10)) / 10
+}
+
+// Summary renders the structured facts that help experience scoring
+func (p *CandidateProfile) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Total professional experience: %.1f years\n", p.YearsExperience)
+
+	if len(p.Skills) > 0 {
+		skills := make([]string, 0, len(p.Skills))
+		for _, s := range p.Skills {
+			if s.Years > 0 {
+				skills = append(skills, fmt.Sprintf("%s (%.1fy)", s.Name, s.Years))
+			} else {
+				skills = append(skills, s.Name)
+			}
+		}
+		fmt.Fprintf(&b, "Skills: %s\n", strings.Join(skills, ", "))
+	}
+
+	for _, w := range p.WorkHistory {
+		end := w.EndDate
+		if w.Current || end == "" {
+			end = "present"
+		}
+		fmt.Fprintf(&b, "- %s at %s (%s to %s)\n", w.Title, w.Company, w.StartDate, end)
+	}
+
+	return b.String()
+}
+
+func parseProfileDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{"2006-01", "2006-01-02", "2006"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
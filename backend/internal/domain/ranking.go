@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RankingWeights sets how much the CV match rate (0-1) and the project score
+// (1-5, rescaled to 0-1) each count towards a candidate's place in an
+// opening's ranking and a batch's leaderboard. Equal weights give CombinedScore.
+type RankingWeights struct {
+	CVMatchRate  float64 `json:"cv_match_rate"`
+	ProjectScore float64 `json:"project_score"`
+}
+
+// Normalize scales the weights to sum to 1
+func (w RankingWeights) Normalize() (RankingWeights, error) {
+	if w.CVMatchRate < 0 || w.ProjectScore < 0 {
+		return w, errors.New("ranking weights cannot be negative")
+	}
+	sum := w.CVMatchRate + w.ProjectScore
+	if sum == 0 {
+		return w, errors.New("ranking weights must not both be zero")
+	}
+	return RankingWeights{CVMatchRate: w.CVMatchRate / sum, ProjectScore: w.ProjectScore / sum}, nil
+}
+
+// EqualWeights counts the CV match rate and the project score the same
+var EqualWeights = RankingWeights{CVMatchRate: 0.5, ProjectScore: 0.5}
+
+// Score weighs a CV match rate (0-1) and a project score (1-5, rescaled to
+// 0-1) into a candidate's place. The weights must be normalized.
+func (w RankingWeights) Score(cvMatchRate, projectScore float64) float64 {
+	return w.CVMatchRate*cvMatchRate + w.ProjectScore*(projectScore-1)/4
+}
+
+// ScoreSQL is Score as a SQL expression over the named columns, for ranking
+// in the database; args holds the weights for its placeholders
+func (w RankingWeights) ScoreSQL(cvMatchRate, projectScore string) (expr string, args []interface{}) {
+	return fmt.Sprintf("? * %s + ? * (%s - 1) / 4", cvMatchRate, projectScore), []interface{}{w.CVMatchRate, w.ProjectScore}
+}
+
+// Ranking is the ordered list of completed evaluations for one opening
+type Ranking struct {
+	JobTitle   string            `json:"job_title"`
+	Weights    RankingWeights    `json:"weights"`
+	Total      int64             `json:"total"`
+	Limit      int               `json:"limit"`
+	Offset     int               `json:"offset"`
+	Candidates []RankedCandidate `json:"candidates"`
+}
+
+// RankedCandidate is one completed evaluation in a ranking
+type RankedCandidate struct {
+	LeaderboardEntry
+	Blind       bool      `json:"blind"`
+	CompletedAt time.Time `json:"completed_at" gorm:"column:completed_at"`
+}
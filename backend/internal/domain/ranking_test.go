@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRankingWeightsScore(t *testing.T) {
+	tests := []struct {
+		weights      RankingWeights
+		cvMatchRate  float64
+		projectScore float64
+		want         float64
+	}{
+		{EqualWeights, 0.8, 5, 0.9},
+		{EqualWeights, 0, 1, 0},
+		{RankingWeights{CVMatchRate: 1}, 0.7, 2, 0.7},
+		{RankingWeights{ProjectScore: 1}, 0.7, 2, 0.25},
+		{RankingWeights{CVMatchRate: 0.25, ProjectScore: 0.75}, 0.4, 3, 0.475},
+	}
+
+	for _, tt := range tests {
+		if got := tt.weights.Score(tt.cvMatchRate, tt.projectScore); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%+v.Score(%v, %v) = %v, want %v", tt.weights, tt.cvMatchRate, tt.projectScore, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Recommendation is the hiring recommendation derived from the two scores
+type Recommendation string
+
+const (
+	RecommendationStrongHire Recommendation = "strong_hire"
+	RecommendationHire       Recommendation = "hire"
+	RecommendationMaybe      Recommendation = "maybe"
+	RecommendationNoHire     Recommendation = "no_hire"
+)
+
+// EvaluationResult is the final output of the pipeline. It is stored whole in
+// evaluation_jobs.result and its scores are also copied into typed columns.
+type EvaluationResult struct {
+	CVMatchRate     float64        `json:"cv_match_rate"`
+	CVFeedback      string         `json:"cv_feedback"`
+	ProjectScore    float64        `json:"project_score"`
+	ProjectFeedback string         `json:"project_feedback"`
+	OverallSummary  string         `json:"overall_summary"`
+	Recommendation  Recommendation `json:"recommendation,omitempty"`
+	// Rubric scores behind the two headline scores; absent for jobs
+	// evaluated before they were introduced
+	CVCriteria      CriterionScores `json:"cv_criteria,omitempty"`
+	ProjectCriteria CriterionScores `json:"project_criteria,omitempty"`
+}
+
+// Criterion is one rubric dimension the LLM scores from 1 to 5
+type Criterion struct {
+	Key   string
+	Label string
+}
+
+// CVRubric and ProjectRubric list the criteria scored alongside the match
+// rate and the project score, in the order reports show them
+var (
+	CVRubric = []Criterion{
+		{Key: "technical_skills", Label: "Technical skills"},
+		{Key: "experience_level", Label: "Experience level"},
+		{Key: "achievements", Label: "Relevant achievements"},
+		{Key: "cultural_fit", Label: "Cultural fit"},
+	}
+	ProjectRubric = []Criterion{
+		{Key: "correctness", Label: "Correctness"},
+		{Key: "code_quality", Label: "Code quality"},
+		{Key: "resilience", Label: "Resilience and error handling"},
+		{Key: "documentation", Label: "Documentation"},
+		{Key: "creativity", Label: "Creativity"},
+	}
+)
+
+// CriterionScores maps criterion keys to scores from 1 to 5
+type CriterionScores map[string]float64
+
+// Clean keeps the rubric's criteria, clamping each score into 1-5. It
+// returns nil when none are left.
+func (s CriterionScores) Clean(rubric []Criterion) CriterionScores {
+	cleaned := CriterionScores{}
+	for _, c := range rubric {
+		score, ok := s[c.Key]
+		if !ok {
+			continue
+		}
+		cleaned[c.Key] = min(max(score, 1), 5)
+	}
+	if len(cleaned) == 0 {
+		return nil
+	}
+	return cleaned
+}
+
+// CombinedScore averages a CV match rate (0-1) and a project score (1-5)
+// rescaled to 0-1
+func CombinedScore(cvMatchRate, projectScore float64) float64 {
+	return EqualWeights.Score(cvMatchRate, projectScore)
+}
+
+// RecommendationFor maps the combined score onto a recommendation. Migration
+// 0004_evaluation_result_columns applies the same thresholds when backfilling.
+func RecommendationFor(cvMatchRate, projectScore float64) Recommendation {
+	combined := CombinedScore(cvMatchRate, projectScore)
+	switch {
+	case combined >= 0.8:
+		return RecommendationStrongHire
+	case combined >= 0.6:
+		return RecommendationHire
+	case combined >= 0.4:
+		return RecommendationMaybe
+	default:
+		return RecommendationNoHire
+	}
+}
+
+func (r EvaluationResult) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+func (r *EvaluationResult) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for EvaluationResult: %T", value)
+	}
+
+	return json.Unmarshal(bytes, r)
+}
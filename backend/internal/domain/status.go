@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// JobStatus is the lifecycle state of an evaluation job
+type JobStatus string
+
+const (
+	JobScheduled  JobStatus = "scheduled"
+	JobQueued     JobStatus = "queued"
+	JobProcessing JobStatus = "processing"
+	JobCompleted  JobStatus = "completed"
+	JobFailed     JobStatus = "failed"
+)
+
+// JobStatuses lists every status in lifecycle order
+var JobStatuses = []JobStatus{JobScheduled, JobQueued, JobProcessing, JobCompleted, JobFailed}
+
+// jobTransitions lists the statuses each status may move to. A processing
+// job goes back to queued when the worker that claimed it died.
+var jobTransitions = map[JobStatus][]JobStatus{
+	JobScheduled:  {JobQueued, JobFailed},
+	JobQueued:     {JobProcessing, JobFailed},
+	JobProcessing: {JobCompleted, JobFailed, JobQueued},
+}
+
+// CanTransitionTo reports whether the state machine allows s -> next
+func (s JobStatus) CanTransitionTo(next JobStatus) bool {
+	for _, allowed := range jobTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Terminal reports whether no further transitions are possible
+func (s JobStatus) Terminal() bool {
+	return len(jobTransitions[s]) == 0
+}
+
+var (
+	// ErrInvalidTransition means the state machine does not allow the change
+	ErrInvalidTransition = errors.New("invalid job status transition")
+	// ErrJobConflict means the job changed since it was read, e.g. another
+	// worker picked it up or it already finished
+	ErrJobConflict = errors.New("job was modified concurrently")
+)
+
+// TransitionError describes a rejected status change
+type TransitionError struct {
+	JobID uint
+	From  JobStatus
+	To    JobStatus
+	Err   error // ErrInvalidTransition or ErrJobConflict
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("job %d: %s -> %s: %v", e.JobID, e.From, e.To, e.Err)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return e.Err
+}
+
+// JobEvent records one status transition of an evaluation job
+type JobEvent struct {
+	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	JobID      uint      `json:"job_id" gorm:"not null"`
+	FromStatus JobStatus `json:"from_status,omitempty"` // empty when the job was created
+	ToStatus   JobStatus `json:"to_status" gorm:"not null"`
+	Version    int       `json:"version"`
+	WorkerID   string    `json:"worker_id,omitempty"` // empty for changes made by the API
+	Message    string    `json:"message,omitempty"`
+	CreatedAt  time.Time `json:"created_at" gorm:"default:now()"`
+}
+
+func (JobEvent) TableName() string {
+	return "job_events"
+}
+
+// StaleJob is a queued or processing job that hasn't changed for a while,
+// checked by the reaper for a lost queue message or a dead worker
+type StaleJob struct {
+	EvaluationJob
+	// ClaimedBy is the worker that last moved the job to processing
+	ClaimedBy string
+	// Requeues counts how often the job was taken back from a dead worker
+	Requeues int
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// DefaultTenantID is the tenant created by the initial migration; LLM usage
+// not attributed to any tenant is recorded against it
+const DefaultTenantID = "default"
+
+// Tenant holds per-organization settings
+type Tenant struct {
+	ID        string `json:"id" gorm:"primaryKey"`
+	Name      string `json:"name"`
+	RedactPII bool   `json:"redact_pii"`
+
+	// Monthly LLM budgets; zero means unlimited
+	MonthlyTokenBudget   int64   `json:"monthly_token_budget"`
+	MonthlyCostBudgetUSD float64 `json:"monthly_cost_budget_usd"`
+
+	// DocumentRetentionDays is how long uploaded documents are kept before
+	// the purger erases them; zero keeps them forever
+	DocumentRetentionDays int `json:"document_retention_days"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"default:now()"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"default:now()"`
+}
+
+func (Tenant) TableName() string {
+	return "tenants"
+}
+
+// DefaultTenant returns the settings of a new tenant, also applied when a
+// tenant's stored settings can't be loaded
+func DefaultTenant(id string) *Tenant {
+	return &Tenant{
+		ID:        id,
+		Name:      id,
+		RedactPII: true,
+	}
+}
@@ -13,13 +13,15 @@ type OpenAIClient struct {
 }
 
 type CVEvaluationResult struct {
-	MatchRate float64 `json:"match_rate"`
-	Feedback  string  `json:"feedback"`
+	MatchRate float64         `json:"match_rate"`
+	Feedback  string          `json:"feedback"`
+	Criteria  CriterionScores `json:"criteria,omitempty"` // scored against CVRubric
 }
 
 type ProjectEvaluationResult struct {
-	Score    float64 `json:"score"`
-	Feedback string  `json:"feedback"`
+	Score    float64         `json:"score"`
+	Feedback string          `json:"feedback"`
+	Criteria CriterionScores `json:"criteria,omitempty"` // scored against ProjectRubric
 }
 
 // Infrastructure Service Types
@@ -60,11 +62,11 @@ type EvaluationResponse struct {
 }
 
 type ResultResponse struct {
-	ID        string      `json:"id"`
-	Status    string      `json:"status"`
-	Result    JSON        `json:"result,omitempty"`
-	CreatedAt any `json:"created_at"`
-	UpdatedAt any `json:"updated_at"`
+	ID        string            `json:"id"`
+	Status    string            `json:"status"`
+	Result    *EvaluationResult `json:"result,omitempty"`
+	CreatedAt any               `json:"created_at"`
+	UpdatedAt any               `json:"updated_at"`
 }
 
 type QueueStatusResponse struct {
@@ -91,4 +93,4 @@ type ValidationRule struct {
 type ValidationResult struct {
 	IsValid bool
 	Errors  map[string]string
-}
\ No newline at end of file
+}
@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+// LLMUsage records the tokens and estimated cost of a single LLM or embedding call
+type LLMUsage struct {
+	ID               uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID         string    `json:"tenant_id"`
+	JobID            *uint     `json:"job_id,omitempty"`
+	Operation        string    `json:"operation"` // evaluate_cv, evaluate_project, generate_summary, extract_profile, embedding
+	Model            string    `json:"model"`
+	PromptTokens     int64     `json:"prompt_tokens"`
+	CompletionTokens int64     `json:"completion_tokens"`
+	TotalTokens      int64     `json:"total_tokens"`
+	CostUSD          float64   `json:"cost_usd"`
+	CreatedAt        time.Time `json:"created_at" gorm:"default:now()"`
+}
+
+func (LLMUsage) TableName() string {
+	return "llm_usage"
+}
+
+// UsageSummary aggregates LLM usage over a set of calls
+type UsageSummary struct {
+	Calls            int64   `json:"calls"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+}
+
+// UsageBreakdown is a UsageSummary for one model and operation
+type UsageBreakdown struct {
+	Model     string `json:"model"`
+	Operation string `json:"operation"`
+	UsageSummary
+}
+
+// UsageReport is the usage of a tenant over a period
+type UsageReport struct {
+	TenantID  string           `json:"tenant_id"`
+	From      time.Time        `json:"from"`
+	To        time.Time        `json:"to"`
+	Total     UsageSummary     `json:"total"`
+	Jobs      int64            `json:"jobs"`
+	Breakdown []UsageBreakdown `json:"breakdown"`
+}
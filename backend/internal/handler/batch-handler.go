@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/adyutaa/parsea/internal/domain"
+	"github.com/adyutaa/parsea/internal/middleware"
+	"github.com/adyutaa/parsea/internal/service"
+	"github.com/adyutaa/parsea/internal/validation"
+	"github.com/gin-gonic/gin"
+)
+
+type BatchHandler struct {
+	service *service.BatchService
+	// maxArchiveBytes caps the size of an uploaded zip
+	maxArchiveBytes int64
+}
+
+func NewBatchHandler(service *service.BatchService, maxArchiveBytes int64) *BatchHandler {
+	return &BatchHandler{service: service, maxArchiveBytes: maxArchiveBytes}
+}
+
+// CreateBatchRequest is the JSON form of POST /batches, for documents that
+// were already uploaded
+type CreateBatchRequest struct {
+	JobTitle string                 `json:"job_title" binding:"required"`
+	Blind    bool                   `json:"blind"`
+	Priority string                 `json:"priority"` // defaults to bulk
+	Pairs    []service.DocumentPair `json:"pairs"`
+}
+
+// CreateBatch starts one evaluation per candidate, either from a zip upload
+// (multipart field "archive") or from a JSON list of document ID pairs
+func (h *BatchHandler) CreateBatch(c *gin.Context) {
+	var req CreateBatchRequest
+	fromArchive := strings.HasPrefix(c.ContentType(), "multipart/")
+	if fromArchive {
+		if !h.bindArchive(c, &req) {
+			return
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid JSON format: " + err.Error(),
+		})
+		return
+	}
+
+	req.JobTitle = strings.TrimSpace(req.JobTitle)
+	if err := validation.ValidateJobTitle(req.JobTitle); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Batches are usually large, so they stay out of the way of interactive
+	// evaluations unless asked otherwise
+	if req.Priority == "" {
+		req.Priority = string(domain.PriorityBulk)
+	}
+	priority, err := domain.ParsePriority(req.Priority)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	params := service.StartBatchParams{
+		JobTitle: req.JobTitle,
+		TenantID: middleware.TenantID(c),
+		Blind:    req.Blind,
+		Priority: priority,
+		Pairs:    req.Pairs,
+	}
+	var batch *domain.Batch
+	if fromArchive {
+		// Zip uploads are saved here, after the form has been validated
+		batch, req.Pairs, err = h.startArchiveBatch(c, params)
+	} else {
+		batch, err = h.service.StartBatch(c.Request.Context(), params)
+	}
+	var budgetErr *service.BudgetError
+	if errors.As(err, &budgetErr) {
+		c.JSON(http.StatusPaymentRequired, gin.H{
+			"error":     "Monthly LLM budget exhausted",
+			"budget":    budgetErr.Budget,
+			"limit":     budgetErr.Limit,
+			"used":      budgetErr.Used,
+			"resets_at": budgetErr.ResetsAt,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(batchErrorStatus(err), gin.H{
+			"error": "Failed to start batch: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"id":       batch.ID,
+		"status":   "processing",
+		"total":    batch.Total,
+		"blind":    req.Blind,
+		"priority": priority,
+		"pairs":    req.Pairs,
+	})
+}
+
+// bindArchive reads the form fields of a zip upload. The archive itself is
+// read by startArchiveBatch.
+func (h *BatchHandler) bindArchive(c *gin.Context, req *CreateBatchRequest) bool {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxArchiveBytes+1<<20)
+	if _, err := c.FormFile("archive"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "archive zip file is required",
+		})
+		return false
+	}
+	req.JobTitle = c.PostForm("job_title")
+	req.Priority = c.PostForm("priority")
+	req.Blind = c.PostForm("blind") == "true"
+	return true
+}
+
+func (h *BatchHandler) startArchiveBatch(c *gin.Context, params service.StartBatchParams) (*domain.Batch, []service.DocumentPair, error) {
+	header, _ := c.FormFile("archive")
+	if header.Size > h.maxArchiveBytes {
+		return nil, nil, errArchiveTooLarge
+	}
+	archive, err := header.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer archive.Close()
+
+	return h.service.StartArchiveBatch(c.Request.Context(), params, archive, header.Size)
+}
+
+// GetBatch returns a batch's progress and, once finished, its leaderboard
+func (h *BatchHandler) GetBatch(c *gin.Context) {
+	id := c.Param("id")
+	if err := validation.ValidateID(id, "id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	status, err := h.service.GetBatch(id, middleware.TenantID(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Batch not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+var errArchiveTooLarge = errors.New("archive exceeds the batch upload limit")
+
+func batchErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, errArchiveTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, service.ErrInvalidBatch):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrTenantNotFound):
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
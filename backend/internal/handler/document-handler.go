@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"mime/multipart"
 	"net/http"
-	
+
+	"github.com/adyutaa/parsea/internal/middleware"
 	"github.com/adyutaa/parsea/internal/service"
 	"github.com/adyutaa/parsea/internal/validation"
+	"github.com/adyutaa/parsea/pkg/extract"
 	"github.com/gin-gonic/gin"
 )
 
@@ -54,20 +57,17 @@ func (h *DocumentHandler) Upload(c *gin.Context) {
 	}
 
 	// Save CV
-	cvID, err := h.service.SaveDocument(cvFile, "cv")
+	tenantID := middleware.TenantID(c)
+	cvID, err := h.service.SaveDocument(cvFile, "cv", tenantID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to save CV: " + err.Error(),
-		})
+		uploadError(c, "CV", "CV", err)
 		return
 	}
 
 	// Save Project Report
-	reportID, err := h.service.SaveDocument(reportFile, "project_report")
+	reportID, err := h.service.SaveDocument(reportFile, "project_report", tenantID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to save project report: " + err.Error(),
-		})
+		uploadError(c, "project report", "Project report", err)
 		return
 	}
 
@@ -78,6 +78,61 @@ func (h *DocumentHandler) Upload(c *gin.Context) {
 	})
 }
 
+// uploadError reports a failed save: content that doesn't match the file's
+// extension is the client's fault, anything else is ours
+func uploadError(c *gin.Context, name, label string, err error) {
+	var contentErr *extract.ContentError
+	if errors.As(err, &contentErr) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":         label + " validation failed: " + err.Error(),
+			"detected_type": contentErr.Detected,
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": "Failed to save " + name + ": " + err.Error(),
+	})
+}
+
+// GetProfile returns the structured candidate profile extracted from a CV
+func (h *DocumentHandler) GetProfile(c *gin.Context) {
+	id := c.Param("id")
+	if err := validation.ValidateID(id, "id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	doc, err := h.service.GetDocument(id, middleware.TenantID(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Document not found",
+		})
+		return
+	}
+
+	if doc.DocType != "cv" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Profiles are only available for CV documents",
+		})
+		return
+	}
+
+	if doc.Profile == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Profile not available yet",
+			"hint":  "The profile is extracted when the CV is first evaluated",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"document_id": doc.ID,
+		"profile":     doc.Profile,
+	})
+}
+
 // validateFile performs comprehensive file validation
 func (h *DocumentHandler) validateFile(file *multipart.FileHeader) error {
 	// Validate filename
@@ -86,12 +141,12 @@ func (h *DocumentHandler) validateFile(file *multipart.FileHeader) error {
 	}
 
 	// Validate file size
-	if err := validation.ValidateFileSize(file.Size); err != nil {
+	if err := validation.ValidateFileSize(file.Size, h.service.MaxFileSize()); err != nil {
 		return err
 	}
 
 	// Validate MIME type
-	if err := validation.ValidateMimeType(file.Header.Get("Content-Type")); err != nil {
+	if err := validation.ValidateMimeType(file.Header.Get("Content-Type"), file.Filename); err != nil {
 		return err
 	}
 
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/adyutaa/parsea/internal/middleware"
+	"github.com/adyutaa/parsea/internal/service"
+	"github.com/adyutaa/parsea/internal/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// Page size of GET /erasures
+const (
+	defaultErasureLimit = 50
+	maxErasureLimit     = 500
+)
+
+type ErasureHandler struct {
+	service *service.ErasureService
+}
+
+func NewErasureHandler(service *service.ErasureService) *ErasureHandler {
+	return &ErasureHandler{service: service}
+}
+
+// EraseCandidate erases the candidate whose CV has the given ID and returns
+// the audit record of the erasure. The route only admits admin and DPO keys.
+func (h *ErasureHandler) EraseCandidate(c *gin.Context) {
+	cvID := c.Param("id")
+	if err := validation.ValidateID(cvID, "id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"hint":  "Use the cv_id returned by POST /upload",
+		})
+		return
+	}
+	id, err := strconv.ParseUint(cvID, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "id is out of range",
+		})
+		return
+	}
+
+	key := middleware.APIKey(c)
+	if key == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "An API key is required to erase a candidate",
+		})
+		return
+	}
+
+	erasure, err := h.service.EraseCandidate(c.Request.Context(), middleware.TenantID(c), uint(id), key)
+	if errors.Is(err, service.ErrCandidateNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Candidate not found",
+			"cv_id": cvID,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to erase candidate: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Candidate data erased",
+		"erasure": erasure,
+	})
+}
+
+// ListErasures returns the requesting tenant's erasure audit records, newest first
+func (h *ErasureHandler) ListErasures(c *gin.Context) {
+	limit, err := parsePageParam(c.Query("limit"), "limit", defaultErasureLimit)
+	if err == nil && (limit < 1 || limit > maxErasureLimit) {
+		err = fmt.Errorf("limit must be between 1 and %d", maxErasureLimit)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	erasures, err := h.service.List(middleware.TenantID(c), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to load erasures",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"erasures": erasures,
+	})
+}
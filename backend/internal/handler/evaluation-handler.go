@@ -1,10 +1,15 @@
 package handler
 
 import (
+	"errors"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/adyutaa/parsea/internal/domain"
+	"github.com/adyutaa/parsea/internal/middleware"
 	"github.com/adyutaa/parsea/internal/service"
 	"github.com/adyutaa/parsea/internal/validation"
 	"github.com/gin-gonic/gin"
@@ -23,6 +28,9 @@ type EvaluateRequest struct {
 	CVID     uint   `json:"cv_id" binding:"required"`
 	ReportID uint   `json:"report_id" binding:"required"`
 	JobTitle string `json:"job_title" binding:"required"`
+	Blind    bool   `json:"blind"`    // opt-in: hide gender, age, photo, nationality and school names
+	Priority string `json:"priority"` // high, normal (default) or bulk
+	RunAt    string `json:"run_at"`   // optional RFC 3339 time to start the evaluation
 }
 
 // Evaluate creates a new evaluation job
@@ -60,12 +68,55 @@ func (h *EvaluationHandler) Evaluate(c *gin.Context) {
 		return
 	}
 
+	priority, err := domain.ParsePriority(req.Priority)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	runAt, err := validation.ParseRunAt(req.RunAt, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
 	// Start evaluation
-	jobID, err := h.service.StartEvaluation(
-		strconv.FormatUint(uint64(req.CVID), 10),
-		strconv.FormatUint(uint64(req.ReportID), 10),
-		req.JobTitle,
-	)
+	jobID, err := h.service.StartEvaluation(c.Request.Context(), service.StartEvaluationParams{
+		CVID:     strconv.FormatUint(uint64(req.CVID), 10),
+		ReportID: strconv.FormatUint(uint64(req.ReportID), 10),
+		JobTitle: req.JobTitle,
+		TenantID: middleware.TenantID(c),
+		Blind:    req.Blind,
+		Priority: priority,
+		RunAt:    runAt,
+	})
+	var budgetErr *service.BudgetError
+	if errors.As(err, &budgetErr) {
+		c.JSON(http.StatusPaymentRequired, gin.H{
+			"error":     "Monthly LLM budget exhausted",
+			"budget":    budgetErr.Budget,
+			"limit":     budgetErr.Limit,
+			"used":      budgetErr.Used,
+			"resets_at": budgetErr.ResetsAt,
+		})
+		return
+	}
+	if errors.Is(err, service.ErrTenantNotFound) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Unknown tenant",
+		})
+		return
+	}
+	if errors.Is(err, service.ErrDocumentNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to start evaluation: " + err.Error(),
@@ -75,10 +126,17 @@ func (h *EvaluationHandler) Evaluate(c *gin.Context) {
 
 	// Convert jobID string back to int for response
 	jobIDInt, _ := strconv.Atoi(jobID)
-	c.JSON(http.StatusOK, gin.H{
-		"id":     jobIDInt,
-		"status": "queued",
-	})
+	response := gin.H{
+		"id":       jobIDInt,
+		"status":   domain.JobQueued,
+		"blind":    req.Blind,
+		"priority": priority,
+	}
+	if runAt != nil {
+		response["status"] = domain.JobScheduled
+		response["run_at"] = runAt
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 // GetResult retrieves the result of an evaluation job
@@ -94,7 +152,7 @@ func (h *EvaluationHandler) GetResult(c *gin.Context) {
 		return
 	}
 
-	job, err := h.service.GetJobStatus(jobID)
+	job, err := h.service.GetJobStatus(jobID, middleware.TenantID(c))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error":   "Job not found",
@@ -104,19 +162,62 @@ func (h *EvaluationHandler) GetResult(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"id":         job.ID,
 		"job_title":  job.JobTitle,
 		"status":     job.Status,
+		"blind":      job.Blind,
+		"priority":   job.Priority,
 		"result":     job.Result,
 		"created_at": job.CreatedAt,
 		"updated_at": job.UpdatedAt,
-	})
+	}
+
+	if job.Status == domain.JobScheduled && job.RunAt != nil {
+		response["run_at"] = job.RunAt
+		response["eta_seconds"] = math.Max(0, math.Round(time.Until(*job.RunAt).Seconds()))
+	}
+
+	if job.RedactionCounts != nil {
+		response["redaction_counts"] = job.RedactionCounts
+	}
+	if job.BlindCounts != nil {
+		response["blind_counts"] = job.BlindCounts
+	}
+
+	if events, err := h.service.GetJobEvents(job); err == nil {
+		response["events"] = events
+	}
+
+	if usage, err := h.service.GetJobUsage(job); err == nil {
+		response["usage"] = usage
+	}
+
+	if cvDoc, reportDoc, err := h.service.GetJobDocuments(job); err == nil {
+		response["extraction"] = gin.H{
+			"cv":             extractionInfo(cvDoc),
+			"project_report": extractionInfo(reportDoc),
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
-// GetQueueStatus returns current queue information
+// extractionInfo reports how a document's text was obtained
+func extractionInfo(doc *domain.Document) gin.H {
+	info := gin.H{
+		"mime_type": doc.MimeType,
+		"strategy":  doc.ExtractionStrategy,
+	}
+	if doc.ExtractionConfidence != nil {
+		info["ocr_confidence"] = *doc.ExtractionConfidence
+	}
+	return info
+}
+
+// GetQueueStatus reports the depth and oldest job age of each priority queue
 func (h *EvaluationHandler) GetQueueStatus(c *gin.Context) {
-	length, err := h.service.GetQueueLength()
+	queues, err := h.service.GetQueueStats(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to get queue status",
@@ -124,8 +225,14 @@ func (h *EvaluationHandler) GetQueueStatus(c *gin.Context) {
 		return
 	}
 
+	var total int64
+	for _, q := range queues {
+		total += q.Depth
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"queue_length": length,
+		"queue_length": total,
+		"queues":       queues,
 		"status":       "active",
 	})
 }
@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/adyutaa/parsea/internal/domain"
+	"github.com/adyutaa/parsea/internal/middleware"
+	"github.com/adyutaa/parsea/internal/service"
+	"github.com/adyutaa/parsea/internal/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// Page size of GET /openings/:title/ranking
+const (
+	defaultRankingLimit = 50
+	maxRankingLimit     = 200
+)
+
+type RankingHandler struct {
+	service *service.RankingService
+}
+
+func NewRankingHandler(service *service.RankingService) *RankingHandler {
+	return &RankingHandler{service: service}
+}
+
+// GetRanking ranks the completed evaluations of one opening. cv_weight and
+// project_weight override the configured weights; when only one is given it
+// must be between 0 and 1 and the other gets the remainder.
+func (h *RankingHandler) GetRanking(c *gin.Context) {
+	jobTitle := strings.TrimSpace(c.Param("title"))
+	if err := validation.ValidateJobTitle(jobTitle); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	weights, err := parseRankingWeights(c.Query("cv_weight"), c.Query("project_weight"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"hint":  "Use non-negative numbers, e.g. ?cv_weight=0.7&project_weight=0.3",
+		})
+		return
+	}
+
+	limit, err := parsePageParam(c.Query("limit"), "limit", defaultRankingLimit)
+	if err == nil && (limit < 1 || limit > maxRankingLimit) {
+		err = fmt.Errorf("limit must be between 1 and %d", maxRankingLimit)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	offset, err := parsePageParam(c.Query("offset"), "offset", 0)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ranking, err := h.service.Rank(service.RankingParams{
+		TenantID: middleware.TenantID(c),
+		JobTitle: jobTitle,
+		Weights:  weights,
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ranking)
+}
+
+// parseRankingWeights returns nil when neither weight is set
+func parseRankingWeights(cvRaw, projectRaw string) (*domain.RankingWeights, error) {
+	if cvRaw == "" && projectRaw == "" {
+		return nil, nil
+	}
+
+	parse := func(raw, name string) (float64, error) {
+		w, err := strconv.ParseFloat(raw, 64)
+		if err != nil || w < 0 {
+			return 0, fmt.Errorf("%s must be a non-negative number", name)
+		}
+		return w, nil
+	}
+	remainder := func(w float64, name string) (float64, error) {
+		if w > 1 {
+			return 0, fmt.Errorf("%s must be between 0 and 1 when given alone", name)
+		}
+		return 1 - w, nil
+	}
+
+	var weights domain.RankingWeights
+	var err error
+	switch {
+	case projectRaw == "":
+		if weights.CVMatchRate, err = parse(cvRaw, "cv_weight"); err != nil {
+			return nil, err
+		}
+		weights.ProjectScore, err = remainder(weights.CVMatchRate, "cv_weight")
+	case cvRaw == "":
+		if weights.ProjectScore, err = parse(projectRaw, "project_weight"); err != nil {
+			return nil, err
+		}
+		weights.CVMatchRate, err = remainder(weights.ProjectScore, "project_weight")
+	default:
+		if weights.CVMatchRate, err = parse(cvRaw, "cv_weight"); err != nil {
+			return nil, err
+		}
+		if weights.ProjectScore, err = parse(projectRaw, "project_weight"); err != nil {
+			return nil, err
+		}
+		if weights.CVMatchRate+weights.ProjectScore == 0 {
+			err = errors.New("cv_weight and project_weight must not both be zero")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &weights, nil
+}
+
+func parsePageParam(raw, name string, fallback int) (int, error) {
+	if raw == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", name)
+	}
+	return n, nil
+}
+
+// CompareRequest lists the evaluation jobs of the candidates to compare
+type CompareRequest struct {
+	JobIDs []uint `json:"job_ids" binding:"required"`
+}
+
+// Compare returns an LLM-written side-by-side comparison of 2-5 candidates
+// evaluated for the same opening
+func (h *RankingHandler) Compare(c *gin.Context) {
+	var req CompareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid JSON format: " + err.Error(),
+		})
+		return
+	}
+
+	comparison, err := h.service.Compare(c.Request.Context(), middleware.TenantID(c), req.JobIDs)
+	var budgetErr *service.BudgetError
+	if errors.As(err, &budgetErr) {
+		c.JSON(http.StatusPaymentRequired, gin.H{
+			"error":     "Monthly LLM budget exhausted",
+			"budget":    budgetErr.Budget,
+			"limit":     budgetErr.Limit,
+			"used":      budgetErr.Used,
+			"resets_at": budgetErr.ResetsAt,
+		})
+		return
+	}
+	if errors.Is(err, service.ErrTenantNotFound) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Unknown tenant",
+		})
+		return
+	}
+	if errors.Is(err, service.ErrInvalidComparison) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, comparison)
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adyutaa/parsea/internal/domain"
+	"github.com/adyutaa/parsea/internal/middleware"
+	"github.com/adyutaa/parsea/internal/service"
+	"github.com/adyutaa/parsea/internal/validation"
+	"github.com/gin-gonic/gin"
+)
+
+type ReportHandler struct {
+	service *service.ReportService
+}
+
+func NewReportHandler(service *service.ReportService) *ReportHandler {
+	return &ReportHandler{service: service}
+}
+
+// GetPDF downloads the report of a completed evaluation
+func (h *ReportHandler) GetPDF(c *gin.Context) {
+	jobID := c.Param("id")
+	if err := validation.ValidateID(jobID, "id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	doc, job, err := h.service.BuildPDF(jobID, middleware.TenantID(c))
+	if errors.Is(err, service.ErrReportNotReady) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":  "Evaluation is not completed",
+			"status": job.Status,
+			"hint":   "Reports are available once GET /result shows the job as completed",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Job not found",
+			"job_id":  jobID,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="evaluation-%d.pdf"`, job.ID))
+	c.Header("Content-Type", "application/pdf")
+	c.Status(http.StatusOK)
+	if _, err := doc.WriteTo(c.Writer); err != nil {
+		c.Error(err)
+	}
+}
+
+// ExportCSV streams the requesting tenant's jobs as CSV. status, job_title,
+// priority, recommendation, batch_id, from and to narrow the export.
+func (h *ReportHandler) ExportCSV(c *gin.Context) {
+	filter, err := parseJobFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"hint":  "e.g. ?status=completed&job_title=Backend%20Developer&from=2025-01-01",
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="evaluations-%s.csv"`, time.Now().UTC().Format("20060102")))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+
+	rows, err := h.service.ExportCSV(c.Request.Context(), c.Writer, filter, c.Writer.Flush)
+	if err != nil && !c.Writer.Written() {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to export evaluations: " + err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		// Headers are sent, so the client sees a truncated file; log it
+		c.Error(fmt.Errorf("export stopped after %d rows: %w", rows, err))
+	}
+}
+
+func parseJobFilter(c *gin.Context) (domain.JobFilter, error) {
+	filter := domain.JobFilter{
+		TenantID: middleware.TenantID(c),
+		JobTitle: strings.TrimSpace(c.Query("job_title")),
+	}
+
+	if v := c.Query("status"); v != "" {
+		filter.Status = domain.JobStatus(v)
+		valid := false
+		for _, s := range domain.JobStatuses {
+			valid = valid || s == filter.Status
+		}
+		if !valid {
+			return filter, fmt.Errorf("status must be one of scheduled, queued, processing, completed or failed")
+		}
+	}
+	if v := c.Query("priority"); v != "" {
+		p, err := domain.ParsePriority(v)
+		if err != nil {
+			return filter, err
+		}
+		filter.Priority = p
+	}
+	if v := c.Query("recommendation"); v != "" {
+		filter.Recommendation = domain.Recommendation(v)
+		switch filter.Recommendation {
+		case domain.RecommendationStrongHire, domain.RecommendationHire, domain.RecommendationMaybe, domain.RecommendationNoHire:
+		default:
+			return filter, fmt.Errorf("recommendation must be one of strong_hire, hire, maybe or no_hire")
+		}
+	}
+	if v := c.Query("batch_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return filter, fmt.Errorf("batch_id must be a positive integer")
+		}
+		batchID := uint(id)
+		filter.BatchID = &batchID
+	}
+	if v := c.Query("from"); v != "" {
+		from, err := parseTimeParam(v, false)
+		if err != nil {
+			return filter, fmt.Errorf("invalid from: %w", err)
+		}
+		filter.From = &from
+	}
+	if v := c.Query("to"); v != "" {
+		to, err := parseTimeParam(v, true)
+		if err != nil {
+			return filter, fmt.Errorf("invalid to: %w", err)
+		}
+		filter.To = &to
+	}
+	return filter, nil
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/adyutaa/parsea/internal/middleware"
+	"github.com/adyutaa/parsea/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+type TenantHandler struct {
+	repo *repository.TenantRepository
+}
+
+func NewTenantHandler(repo *repository.TenantRepository) *TenantHandler {
+	return &TenantHandler{repo: repo}
+}
+
+// UpdateSettingsRequest represents the request body for tenant settings
+type UpdateSettingsRequest struct {
+	Name      *string `json:"name"`
+	RedactPII *bool   `json:"redact_pii"`
+
+	MonthlyTokenBudget   *int64   `json:"monthly_token_budget"`
+	MonthlyCostBudgetUSD *float64 `json:"monthly_cost_budget_usd"`
+
+	DocumentRetentionDays *int `json:"document_retention_days"`
+}
+
+// GetSettings returns the settings of the requesting tenant
+func (h *TenantHandler) GetSettings(c *gin.Context) {
+	tenant, err := h.repo.GetByID(middleware.TenantID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to load tenant settings",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, tenant)
+}
+
+// UpdateSettings changes the settings of the requesting tenant
+func (h *TenantHandler) UpdateSettings(c *gin.Context) {
+	var req UpdateSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid JSON format: " + err.Error(),
+		})
+		return
+	}
+
+	tenant, err := h.repo.GetByID(middleware.TenantID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to load tenant settings",
+		})
+		return
+	}
+
+	if req.Name != nil {
+		tenant.Name = *req.Name
+	}
+	if req.RedactPII != nil {
+		tenant.RedactPII = *req.RedactPII
+	}
+	if req.MonthlyTokenBudget != nil {
+		if *req.MonthlyTokenBudget < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "monthly_token_budget cannot be negative",
+			})
+			return
+		}
+		tenant.MonthlyTokenBudget = *req.MonthlyTokenBudget
+	}
+	if req.MonthlyCostBudgetUSD != nil {
+		if *req.MonthlyCostBudgetUSD < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "monthly_cost_budget_usd cannot be negative",
+			})
+			return
+		}
+		tenant.MonthlyCostBudgetUSD = *req.MonthlyCostBudgetUSD
+	}
+	if req.DocumentRetentionDays != nil {
+		if *req.DocumentRetentionDays < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "document_retention_days cannot be negative",
+			})
+			return
+		}
+		tenant.DocumentRetentionDays = *req.DocumentRetentionDays
+	}
+
+	if err := h.repo.Save(tenant); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to save tenant settings: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, tenant)
+}
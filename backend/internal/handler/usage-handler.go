@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/adyutaa/parsea/internal/middleware"
+	"github.com/adyutaa/parsea/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type UsageHandler struct {
+	service *service.UsageService
+}
+
+func NewUsageHandler(service *service.UsageService) *UsageHandler {
+	return &UsageHandler{service: service}
+}
+
+// GetUsage reports token usage and estimated cost for the requesting tenant.
+// from and to accept RFC 3339 timestamps or YYYY-MM-DD dates and default to
+// the current month.
+func (h *UsageHandler) GetUsage(c *gin.Context) {
+	now := time.Now().UTC()
+	from := service.MonthStart(now)
+	to := now
+
+	var err error
+	if v := c.Query("from"); v != "" {
+		if from, err = parseTimeParam(v, false); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid from: " + err.Error(),
+				"hint":  "Use RFC 3339 or YYYY-MM-DD, e.g. ?from=2025-01-01&to=2025-01-31",
+			})
+			return
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		if to, err = parseTimeParam(v, true); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid to: " + err.Error(),
+				"hint":  "Use RFC 3339 or YYYY-MM-DD, e.g. ?from=2025-01-01&to=2025-01-31",
+			})
+			return
+		}
+	}
+
+	report, err := h.service.GetReport(middleware.TenantID(c), from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// parseTimeParam parses a query timestamp; a bare date used as an upper
+// bound includes the whole day
+func parseTimeParam(v string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unrecognised time %q", v)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
@@ -0,0 +1,151 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Status values reported per dependency and overall
+const (
+	StatusOK          = "ok"
+	StatusDegraded    = "degraded"
+	StatusUnavailable = "unavailable"
+	StatusDisabled    = "disabled"
+)
+
+// Check probes one dependency
+type Check struct {
+	Name string
+	// Critical dependencies make the service unavailable when they fail;
+	// the others only degrade it
+	Critical bool
+	// Timeout bounds a single probe
+	Timeout time.Duration
+	// CacheFor reuses the last result, for probes that cost money or quota
+	CacheFor time.Duration
+	Probe    func(ctx context.Context) (details map[string]any, err error)
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Status    string         `json:"status"`
+	Critical  bool           `json:"critical"`
+	LatencyMS float64        `json:"latency_ms"`
+	Error     string         `json:"error,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+	CheckedAt time.Time      `json:"checked_at"`
+}
+
+// Report aggregates all check results
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Checker runs a fixed set of checks concurrently
+type Checker struct {
+	checks []Check
+
+	mu     sync.Mutex
+	cached map[string]Result
+}
+
+func NewChecker(checks ...Check) *Checker {
+	return &Checker{
+		checks: checks,
+		cached: make(map[string]Result),
+	}
+}
+
+// Disabled is a check for an optional dependency that is not configured
+func Disabled(name string) Check {
+	return Check{
+		Name: name,
+		Probe: func(context.Context) (map[string]any, error) {
+			return nil, errDisabled
+		},
+	}
+}
+
+var errDisabled = errors.New("not configured")
+
+// Run probes every dependency and derives the overall status
+func (c *Checker) Run(ctx context.Context) *Report {
+	report := &Report{
+		Status: StatusOK,
+		Checks: make(map[string]Result, len(c.checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range c.checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+			result := c.run(ctx, check)
+
+			mu.Lock()
+			report.Checks[check.Name] = result
+			mu.Unlock()
+		}(check)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		switch {
+		case result.Status == StatusUnavailable && result.Critical:
+			report.Status = StatusUnavailable
+		case result.Status != StatusOK && result.Status != StatusDisabled && report.Status == StatusOK:
+			report.Status = StatusDegraded
+		}
+	}
+
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) Result {
+	if check.CacheFor > 0 {
+		c.mu.Lock()
+		cached, ok := c.cached[check.Name]
+		c.mu.Unlock()
+		if ok && time.Since(cached.CheckedAt) < check.CacheFor {
+			return cached
+		}
+	}
+
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := check.Probe(probeCtx)
+	result := Result{
+		Status:    StatusOK,
+		Critical:  check.Critical,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+		Details:   details,
+		CheckedAt: time.Now(),
+	}
+
+	switch {
+	case errors.Is(err, errDisabled):
+		result.Status = StatusDisabled
+		result.LatencyMS = 0
+	case err != nil:
+		result.Status = StatusUnavailable
+		result.Error = err.Error()
+	}
+
+	if check.CacheFor > 0 {
+		c.mu.Lock()
+		c.cached[check.Name] = result
+		c.mu.Unlock()
+	}
+
+	return result
+}
@@ -0,0 +1,232 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// Batch API operations, recorded as LLM call metrics
+const (
+	OperationBatchSubmit = "batch_submit"
+	OperationBatchPoll   = "batch_poll"
+)
+
+// batchDiscount is the share of the synchronous price charged for batch requests
+const batchDiscount = 0.5
+
+// batchLineLimit bounds one line of a batch output file
+const batchLineLimit = 16 << 20
+
+// BatchRequest is one chat completion of a provider batch
+type BatchRequest struct {
+	// CustomID is echoed back with the result so it can be matched to a job
+	CustomID string
+	// Operation names the call, e.g. OperationEvaluateCV
+	Operation string
+	Params    openai.ChatCompletionNewParams
+}
+
+// CVEvaluationRequest builds the batch form of EvaluateCV
+func CVEvaluationRequest(customID, cvText, jobContext string, opts EvaluationOptions) BatchRequest {
+	return BatchRequest{CustomID: customID, Operation: OperationEvaluateCV, Params: cvEvaluationParams(cvText, jobContext, opts)}
+}
+
+// ProjectEvaluationRequest builds the batch form of EvaluateProject
+func ProjectEvaluationRequest(customID, reportText, caseStudyContext string) BatchRequest {
+	return BatchRequest{CustomID: customID, Operation: OperationEvaluateProject, Params: projectEvaluationParams(reportText, caseStudyContext)}
+}
+
+// SummaryRequest builds the batch form of GenerateSummary
+func SummaryRequest(customID, cvFeedback, projectFeedback string, cvMatchRate, projectScore float64) BatchRequest {
+	return BatchRequest{CustomID: customID, Operation: OperationGenerateSummary, Params: summaryParams(cvFeedback, projectFeedback, cvMatchRate, projectScore)}
+}
+
+// BatchResult is the answer to one request of a finished batch
+type BatchResult struct {
+	CustomID         string
+	Model            string
+	Content          string
+	PromptTokens     int64
+	CompletionTokens int64
+	// Err is set when this request failed or got no answer
+	Err error
+}
+
+// batchInputLine is one line of the JSONL input file
+type batchInputLine struct {
+	CustomID string                         `json:"custom_id"`
+	Method   string                         `json:"method"`
+	URL      string                         `json:"url"`
+	Body     openai.ChatCompletionNewParams `json:"body"`
+}
+
+// batchOutputLine is one line of the output or error file
+type batchOutputLine struct {
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int             `json:"status_code"`
+		Body       json.RawMessage `json:"body"`
+	} `json:"response"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// SubmitBatch uploads the requests as a JSONL file and starts a batch,
+// returning the provider's batch ID
+func (c *OpenAIService) SubmitBatch(ctx context.Context, requests []BatchRequest) (batchID string, err error) {
+	if len(requests) == 0 {
+		return "", errors.New("batch has no requests")
+	}
+
+	var input bytes.Buffer
+	encoder := json.NewEncoder(&input)
+	for _, req := range requests {
+		line := batchInputLine{
+			CustomID: req.CustomID,
+			Method:   "POST",
+			URL:      string(openai.BatchNewParamsEndpointV1ChatCompletions),
+			Body:     req.Params,
+		}
+		if err := encoder.Encode(line); err != nil {
+			return "", fmt.Errorf("failed to encode batch request %s: %w", req.CustomID, err)
+		}
+	}
+
+	ctx, done := startCall(ctx, OperationBatchSubmit, string(requests[0].Params.Model))
+	defer func() { done(err) }()
+
+	file, err := c.batchClient.Files.New(ctx, openai.FileNewParams{
+		File:    openai.File(&input, "evaluations.jsonl", "application/jsonl"),
+		Purpose: openai.FilePurposeBatch,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload batch input: %w", err)
+	}
+
+	batch, err := c.batchClient.Batches.New(ctx, openai.BatchNewParams{
+		InputFileID:      file.ID,
+		Endpoint:         openai.BatchNewParamsEndpointV1ChatCompletions,
+		CompletionWindow: openai.BatchNewParamsCompletionWindow24h,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create batch: %w", err)
+	}
+	return batch.ID, nil
+}
+
+// BatchResults checks a batch. While it is running done is false. Once it
+// has finished, results holds an entry for every request that was answered
+// or rejected; requests missing from the map got no answer at all, e.g.
+// because the batch expired.
+func (c *OpenAIService) BatchResults(ctx context.Context, batchID string) (done bool, results map[string]*BatchResult, err error) {
+	ctx, end := startCall(ctx, OperationBatchPoll, "")
+	defer func() { end(err) }()
+
+	batch, err := c.batchClient.Batches.Get(ctx, batchID)
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to get batch %s: %w", batchID, err)
+	}
+
+	switch batch.Status {
+	case openai.BatchStatusCompleted, openai.BatchStatusExpired, openai.BatchStatusCancelled:
+	case openai.BatchStatusFailed:
+		return true, nil, fmt.Errorf("batch %s failed: %s", batchID, batchErrors(batch.Errors))
+	default:
+		return false, nil, nil
+	}
+
+	results = make(map[string]*BatchResult, batch.RequestCounts.Total)
+	for _, fileID := range []string{batch.OutputFileID, batch.ErrorFileID} {
+		if fileID == "" {
+			continue
+		}
+		if err := c.readBatchFile(ctx, fileID, results); err != nil {
+			return true, nil, err
+		}
+	}
+	return true, results, nil
+}
+
+func (c *OpenAIService) readBatchFile(ctx context.Context, fileID string, results map[string]*BatchResult) error {
+	resp, err := c.batchClient.Files.Content(ctx, fileID)
+	if err != nil {
+		return fmt.Errorf("failed to download batch file %s: %w", fileID, err)
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), batchLineLimit)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var line batchOutputLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return fmt.Errorf("malformed line in batch file %s: %w", fileID, err)
+		}
+		results[line.CustomID] = parseBatchLine(line)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read batch file %s: %w", fileID, err)
+	}
+	return nil
+}
+
+func parseBatchLine(line batchOutputLine) *BatchResult {
+	result := &BatchResult{CustomID: line.CustomID}
+	switch {
+	case line.Error != nil:
+		result.Err = fmt.Errorf("%s: %s", line.Error.Code, line.Error.Message)
+		return result
+	case line.Response == nil:
+		result.Err = errors.New("no response")
+		return result
+	case line.Response.StatusCode != 200:
+		result.Err = fmt.Errorf("request failed with status %d: %s", line.Response.StatusCode, line.Response.Body)
+		return result
+	}
+
+	var completion openai.ChatCompletion
+	if err := json.Unmarshal(line.Response.Body, &completion); err != nil {
+		result.Err = fmt.Errorf("failed to parse completion: %w", err)
+		return result
+	}
+	result.Model = completion.Model
+	result.PromptTokens = completion.Usage.PromptTokens
+	result.CompletionTokens = completion.Usage.CompletionTokens
+	if len(completion.Choices) == 0 {
+		result.Err = errors.New("no response from OpenAI")
+		return result
+	}
+	result.Content = completion.Choices[0].Message.Content
+	return result
+}
+
+func batchErrors(errs openai.BatchErrors) string {
+	if len(errs.Data) == 0 {
+		return "no reason given"
+	}
+	messages := make([]string, len(errs.Data))
+	for i, e := range errs.Data {
+		messages[i] = e.Code + ": " + e.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// RecordBatchUsage records the tokens of one batch result at the batch price
+func (c *OpenAIService) RecordBatchUsage(ctx context.Context, operation string, result *BatchResult) {
+	if result.Model == "" {
+		return
+	}
+	c.record(ctx, operation, result.Model, result.PromptTokens, result.CompletionTokens,
+		EstimateCost(result.Model, result.PromptTokens, result.CompletionTokens)*batchDiscount)
+}
@@ -0,0 +1,124 @@
+package llm
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adyutaa/parsea/internal/config"
+	"github.com/adyutaa/parsea/internal/infrastructure/llm/llmstub"
+)
+
+func newStubClient(t *testing.T, stub *llmstub.Server) *OpenAIService {
+	t.Helper()
+	srv := httptest.NewServer(stub.Handler())
+	t.Cleanup(srv.Close)
+	return NewOpenAIClient(config.LLMConfig{
+		APIKey:  "test",
+		Timeout: 10 * time.Second,
+		Batch:   config.LLMBatchConfig{BaseURL: srv.URL + "/v1"},
+	})
+}
+
+func TestBatchResults(t *testing.T) {
+	ctx := context.Background()
+	requests := []BatchRequest{
+		CVEvaluationRequest("job-1:cv", "cv", "context", EvaluationOptions{}),
+		ProjectEvaluationRequest("job-1:project", "report", "context"),
+		CVEvaluationRequest("job-2:cv", "cv", "context", EvaluationOptions{}),
+		ProjectEvaluationRequest("job-2:project", "report", "context"),
+	}
+
+	tests := []struct {
+		name    string
+		fail    []string
+		expire  []string
+		wantErr map[string]string // custom ID to error substring; others succeed
+	}{
+		{name: "completed"},
+		{
+			name:    "failed request",
+			fail:    []string{"job-1:cv"},
+			wantErr: map[string]string{"job-1:cv": "stub_failure"},
+		},
+		{
+			name:    "expired batch",
+			expire:  []string{"job-2:project"},
+			wantErr: map[string]string{"job-2:project": "batch_expired"},
+		},
+		{
+			name:    "failed and expired",
+			fail:    []string{"job-2:cv"},
+			expire:  []string{"job-1:project"},
+			wantErr: map[string]string{"job-2:cv": "stub_failure", "job-1:project": "batch_expired"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := llmstub.NewServer(50 * time.Millisecond)
+			for _, id := range tt.fail {
+				stub.FailCustomIDs[id] = true
+			}
+			for _, id := range tt.expire {
+				stub.ExpireCustomIDs[id] = true
+			}
+			client := newStubClient(t, stub)
+
+			batchID, err := client.SubmitBatch(ctx, requests)
+			if err != nil {
+				t.Fatalf("SubmitBatch: %v", err)
+			}
+
+			done, _, err := client.BatchResults(ctx, batchID)
+			if done || err != nil {
+				t.Fatalf("BatchResults before the delay = done %v, err %v; want still running", done, err)
+			}
+
+			var results map[string]*BatchResult
+			for deadline := time.Now().Add(5 * time.Second); !done; time.Sleep(10 * time.Millisecond) {
+				if time.Now().After(deadline) {
+					t.Fatal("batch not done after 5s")
+				}
+				done, results, err = client.BatchResults(ctx, batchID)
+				if err != nil {
+					t.Fatalf("BatchResults: %v", err)
+				}
+			}
+
+			if len(results) != len(requests) {
+				t.Errorf("got %d results, want %d", len(results), len(requests))
+			}
+			for _, req := range requests {
+				result := results[req.CustomID]
+				if result == nil {
+					t.Errorf("%s: no result", req.CustomID)
+					continue
+				}
+				if want, ok := tt.wantErr[req.CustomID]; ok {
+					if result.Err == nil || !strings.Contains(result.Err.Error(), want) {
+						t.Errorf("%s: err = %v, want %q", req.CustomID, result.Err, want)
+					}
+					continue
+				}
+				if result.Err != nil {
+					t.Errorf("%s: unexpected error %v", req.CustomID, result.Err)
+					continue
+				}
+				if result.Model != llmstub.Model || result.PromptTokens == 0 {
+					t.Errorf("%s: model %q, %d prompt tokens; want the stub's usage", req.CustomID, result.Model, result.PromptTokens)
+				}
+				if strings.HasSuffix(req.CustomID, ":cv") {
+					_, err = ParseCVEvaluation(ctx, result.Content)
+				} else {
+					_, err = ParseProjectEvaluation(ctx, result.Content)
+				}
+				if err != nil {
+					t.Errorf("%s: %v", req.CustomID, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,336 @@
+// Package llmstub is an in-memory stand-in for the OpenAI files, batches and
+// chat completions endpoints. It answers every prompt with canned, valid
+// evaluations so the batch mode can be exercised end to end without an API
+// key or token spend.
+package llmstub
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Canned answers, picked by the custom ID suffix or the prompt
+const (
+	CVAnswer      = `{"match_rate": 0.72, "feedback": "Solid backend experience with Go and PostgreSQL that matches most of the role. Cloud and AI integration experience is thinner than the requirements ask for.", "criteria": {"technical_skills": 4, "experience_level": 3.5, "achievements": 3, "cultural_fit": 4}}`
+	ProjectAnswer = `{"score": 3.8, "feedback": "The implementation covers the required endpoints and handles errors consistently. Retries around the LLM calls and tests for the pipeline would strengthen it.", "criteria": {"correctness": 4, "code_quality": 4, "resilience": 3, "documentation": 4, "creativity": 3.5}}`
+	SummaryAnswer = "A capable backend engineer whose project meets the brief. Strengths are API design and data modelling; gaps are production AI integration. Recommended for interview."
+)
+
+// Model is reported as the model of every stub completion
+const Model = "gpt-3.5-turbo-stub"
+
+// Server implements the subset of the OpenAI API used by the batch mode
+type Server struct {
+	// Delay is how long a batch stays in progress before it completes
+	Delay time.Duration
+	// FailCustomIDs lists custom IDs that are answered with an error line
+	FailCustomIDs map[string]bool
+	// ExpireCustomIDs lists custom IDs left unfinished when the completion
+	// window runs out; a batch holding any of them ends as expired
+	ExpireCustomIDs map[string]bool
+
+	mu      sync.Mutex
+	files   map[string][]byte
+	batches map[string]*batch
+	nextID  int
+}
+
+type batch struct {
+	ID               string         `json:"id"`
+	Object           string         `json:"object"`
+	Endpoint         string         `json:"endpoint"`
+	InputFileID      string         `json:"input_file_id"`
+	CompletionWindow string         `json:"completion_window"`
+	Status           string         `json:"status"`
+	OutputFileID     string         `json:"output_file_id,omitempty"`
+	ErrorFileID      string         `json:"error_file_id,omitempty"`
+	CreatedAt        int64          `json:"created_at"`
+	CompletedAt      int64          `json:"completed_at,omitempty"`
+	ExpiredAt        int64          `json:"expired_at,omitempty"`
+	RequestCounts    map[string]int `json:"request_counts"`
+
+	readyAt time.Time
+	expired bool
+}
+
+func NewServer(delay time.Duration) *Server {
+	return &Server{
+		Delay:           delay,
+		FailCustomIDs:   map[string]bool{},
+		ExpireCustomIDs: map[string]bool{},
+		files:           map[string][]byte{},
+		batches:         map[string]*batch{},
+	}
+}
+
+// Handler serves the stub under /v1, the base URL path the OpenAI client uses
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/files", s.uploadFile)
+	mux.HandleFunc("GET /v1/files/{id}/content", s.fileContent)
+	mux.HandleFunc("POST /v1/batches", s.createBatch)
+	mux.HandleFunc("GET /v1/batches/{id}", s.getBatch)
+	mux.HandleFunc("POST /v1/chat/completions", s.chatCompletion)
+	mux.HandleFunc("GET /v1/models/{id}", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"id": r.PathValue("id"), "object": "model", "owned_by": "stub"})
+	})
+	return mux
+}
+
+func (s *Server) uploadFile(w http.ResponseWriter, r *http.Request) {
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "file is required")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id := s.storeFile(data)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"id":         id,
+		"object":     "file",
+		"bytes":      len(data),
+		"created_at": time.Now().Unix(),
+		"filename":   "input.jsonl",
+		"purpose":    r.FormValue("purpose"),
+		"status":     "processed",
+	})
+}
+
+func (s *Server) fileContent(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	data, ok := s.files[r.PathValue("id")]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "no such file")
+		return
+	}
+	w.Header().Set("Content-Type", "application/jsonl")
+	w.Write(data)
+}
+
+func (s *Server) createBatch(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		InputFileID      string `json:"input_file_id"`
+		Endpoint         string `json:"endpoint"`
+		CompletionWindow string `json:"completion_window"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	input, ok := s.files[req.InputFileID]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusBadRequest, "no such input file")
+		return
+	}
+
+	output, errorsOut, counts, expired, err := s.answerBatch(input)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	b := &batch{
+		ID:               fmt.Sprintf("batch_stub_%d", s.nextID),
+		Object:           "batch",
+		Endpoint:         req.Endpoint,
+		InputFileID:      req.InputFileID,
+		CompletionWindow: req.CompletionWindow,
+		Status:           "in_progress",
+		CreatedAt:        time.Now().Unix(),
+		RequestCounts:    counts,
+		readyAt:          time.Now().Add(s.Delay),
+		expired:          expired,
+	}
+	s.mu.Unlock()
+
+	// Results are computed up front and published once the delay has passed
+	b.OutputFileID = s.storeFile(output)
+	if len(errorsOut) > 0 {
+		b.ErrorFileID = s.storeFile(errorsOut)
+	}
+
+	s.mu.Lock()
+	s.batches[b.ID] = b
+	view := s.view(b)
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, view)
+}
+
+func (s *Server) getBatch(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.batches[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "no such batch")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.view(b))
+}
+
+// view hides the result files until the batch is due; callers hold s.mu
+func (s *Server) view(b *batch) batch {
+	v := *b
+	if time.Now().Before(b.readyAt) {
+		v.OutputFileID, v.ErrorFileID = "", ""
+		return v
+	}
+	if b.expired {
+		v.Status = "expired"
+		v.ExpiredAt = b.readyAt.Unix()
+		return v
+	}
+	v.Status = "completed"
+	v.CompletedAt = b.readyAt.Unix()
+	return v
+}
+
+// answerBatch computes the output and error files for a JSONL input and
+// whether the batch expires before answering every request
+func (s *Server) answerBatch(input []byte) (output, errorsOut []byte, counts map[string]int, expired bool, err error) {
+	var out, errs bytes.Buffer
+	counts = map[string]int{"total": 0, "completed": 0, "failed": 0}
+
+	scanner := bufio.NewScanner(bytes.NewReader(input))
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var line struct {
+			CustomID string          `json:"custom_id"`
+			Body     json.RawMessage `json:"body"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return nil, nil, nil, false, fmt.Errorf("line %d is not valid JSON", counts["total"]+1)
+		}
+		counts["total"]++
+
+		// Like the OpenAI API, requests cut off by expiry are reported in
+		// the error file
+		if s.ExpireCustomIDs[line.CustomID] {
+			expired = true
+			counts["failed"]++
+			json.NewEncoder(&errs).Encode(map[string]any{
+				"id":        "batch_req_" + line.CustomID,
+				"custom_id": line.CustomID,
+				"response":  nil,
+				"error":     map[string]string{"code": "batch_expired", "message": "This request could not be executed before the completion window expired."},
+			})
+			continue
+		}
+
+		if s.FailCustomIDs[line.CustomID] {
+			counts["failed"]++
+			json.NewEncoder(&errs).Encode(map[string]any{
+				"id":        "batch_req_" + line.CustomID,
+				"custom_id": line.CustomID,
+				"response":  nil,
+				"error":     map[string]string{"code": "stub_failure", "message": "request configured to fail"},
+			})
+			continue
+		}
+
+		counts["completed"]++
+		json.NewEncoder(&out).Encode(map[string]any{
+			"id":        "batch_req_" + line.CustomID,
+			"custom_id": line.CustomID,
+			"response": map[string]any{
+				"status_code": 200,
+				"request_id":  "req_" + line.CustomID,
+				"body":        completion(answerFor(line.CustomID, line.Body), len(line.Body)),
+			},
+			"error": nil,
+		})
+	}
+	return out.Bytes(), errs.Bytes(), counts, expired, scanner.Err()
+}
+
+func (s *Server) chatCompletion(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, completion(answerFor("", body), len(body)))
+}
+
+// answerFor picks the canned answer from the custom ID suffix (":cv",
+// ":project", ":summary") or, failing that, from the prompt
+func answerFor(customID string, body []byte) string {
+	switch {
+	case strings.HasSuffix(customID, ":cv"):
+		return CVAnswer
+	case strings.HasSuffix(customID, ":project"):
+		return ProjectAnswer
+	case strings.HasSuffix(customID, ":summary"):
+		return SummaryAnswer
+	}
+
+	prompt := string(body)
+	switch {
+	case strings.Contains(prompt, "match_rate"):
+		return CVAnswer
+	case strings.Contains(prompt, `\"score\"`), strings.Contains(prompt, "project submission"):
+		return ProjectAnswer
+	}
+	return SummaryAnswer
+}
+
+// completion wraps an answer in a chat completion, with token counts
+// estimated at four bytes per token
+func completion(content string, promptBytes int) map[string]any {
+	return map[string]any{
+		"id":      "chatcmpl-stub",
+		"object":  "chat.completion",
+		"created": time.Now().Unix(),
+		"model":   Model,
+		"choices": []map[string]any{{
+			"index":         0,
+			"finish_reason": "stop",
+			"message":       map[string]any{"role": "assistant", "content": content},
+		}},
+		"usage": map[string]int{
+			"prompt_tokens":     promptBytes / 4,
+			"completion_tokens": len(content) / 4,
+			"total_tokens":      promptBytes/4 + len(content)/4,
+		},
+	}
+}
+
+func (s *Server) storeFile(data []byte) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	id := fmt.Sprintf("file_stub_%d", s.nextID)
+	s.files[id] = data
+	return id
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{"error": map[string]string{"message": message, "type": "invalid_request_error"}})
+}
@@ -4,26 +4,74 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/adyutaa/parsea/internal/config"
 	"github.com/adyutaa/parsea/internal/domain"
+	"github.com/adyutaa/parsea/internal/logging"
+	"github.com/adyutaa/parsea/internal/metrics"
+	"github.com/adyutaa/parsea/internal/tracing"
 	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type OpenAIService struct {
 	client *openai.Client
+	// batchClient serves the batch API; it differs from client only when
+	// batch calls go to another provider
+	batchClient    *openai.Client
+	recorder       UsageRecorder
+	timeout        time.Duration
+	summaryTimeout time.Duration
 }
 
-func NewOpenAIClient(apiKey string) *OpenAIService {
-	client := openai.NewClient()
+func NewOpenAIClient(cfg config.LLMConfig) *OpenAIService {
+	client := openai.NewClient(option.WithAPIKey(cfg.APIKey))
+	batchClient := &client
+	if cfg.Batch.BaseURL != "" {
+		c := openai.NewClient(option.WithAPIKey(cfg.APIKey), option.WithBaseURL(cfg.Batch.BaseURL))
+		batchClient = &c
+	}
 	return &OpenAIService{
-		client: &client,
+		client:         &client,
+		batchClient:    batchClient,
+		timeout:        cfg.Timeout,
+		summaryTimeout: cfg.SummaryTimeout,
 	}
 }
 
-func (c *OpenAIService) EvaluateCV(cvText, jobContext string) (*domain.CVEvaluationResult, error) {
+// EvaluationOptions adjusts how a CV is evaluated
+type EvaluationOptions struct {
+	Blind bool // protected attributes were removed; instruct the model not to infer them
+}
+
+const fairnessInstructions = ` This is a blind evaluation: gender, age, photos, nationality and the names of schools have been removed from the CV. Do not guess or infer any of these attributes, do not reward or penalise the prestige of an institution, and base the score only on skills, experience and achievements relevant to the role.`
+
+func (c *OpenAIService) EvaluateCV(ctx context.Context, cvText, jobContext string, opts EvaluationOptions) (*domain.CVEvaluationResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	ctx, done := startCall(ctx, OperationEvaluateCV, openai.ChatModelGPT3_5Turbo)
+	resp, err := c.client.Chat.Completions.New(ctx, cvEvaluationParams(cvText, jobContext, opts))
+	done(err)
+
+	if err != nil {
+		return nil, fmt.Errorf("OpenAI API call failed: %w", err)
+	}
+	c.recordUsage(ctx, OperationEvaluateCV, resp.Model, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("no response from OpenAI")
+	}
+
+	return ParseCVEvaluation(ctx, resp.Choices[0].Message.Content)
+}
+
+func cvEvaluationParams(cvText, jobContext string, opts EvaluationOptions) openai.ChatCompletionNewParams {
 	prompt := fmt.Sprintf(`You are an expert technical recruiter. Analyze this candidate's CV for a Backend Engineer role and respond with specific, personalized feedback.
 
 JOB REQUIREMENTS:
@@ -35,6 +83,7 @@ CANDIDATE CV TEXT:
 TASK: Evaluate the candidate and respond ONLY with valid JSON containing:
 1. "match_rate": decimal between 0.0-1.0 
 2. "feedback": specific evaluation of THIS candidate (3-5 sentences)
+3. "criteria": an object scoring each of %s from 1 (poor) to 5 (excellent)
 
 Your feedback must address:
 - How their technical skills match the job requirements
@@ -43,43 +92,41 @@ Your feedback must address:
 - Specific areas they should improve
 
 Example response format (replace with actual analysis):
-{"match_rate": 0.75, "feedback": "This candidate shows strong backend experience with Go and Python. Their machine learning background aligns well with modern backend requirements. However, they lack enterprise-scale system design experience. Their academic projects demonstrate solid coding fundamentals but need more production experience."}
+{"match_rate": 0.75, "feedback": "This candidate shows strong backend experience with Go and Python. Their machine learning background aligns well with modern backend requirements. However, they lack enterprise-scale system design experience. Their academic projects demonstrate solid coding fundamentals but need more production experience.", "criteria": {"technical_skills": 4, "experience_level": 3, "achievements": 3, "cultural_fit": 4}}
 
-CRITICAL: Write actual specific feedback about THIS candidate, not generic placeholder text.`, jobContext, cvText)
+CRITICAL: Write actual specific feedback about THIS candidate, not generic placeholder text.`, jobContext, cvText, rubricKeys(domain.CVRubric))
 
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
+	systemPrompt := "You are a technical recruiter. Always respond with valid JSON only, no markdown or extra text."
+	if opts.Blind {
+		systemPrompt += fairnessInstructions
+	}
 
-	resp, err := c.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+	return openai.ChatCompletionNewParams{
 		Model: openai.ChatModelGPT3_5Turbo,
 		Messages: []openai.ChatCompletionMessageParamUnion{
-			openai.SystemMessage("You are a technical recruiter. Always respond with valid JSON only, no markdown or extra text."),
+			openai.SystemMessage(systemPrompt),
 			openai.UserMessage(prompt),
 		},
 		Temperature: openai.Float(0.3),
 		MaxTokens:   openai.Int(1000),
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("OpenAI API call failed: %w", err)
-	}
-
-	if len(resp.Choices) == 0 {
-		return nil, fmt.Errorf("no response from OpenAI")
 	}
+}
 
-	content := resp.Choices[0].Message.Content
-	log.Printf("🔍 OpenAI CV Response: %s", content)
+// ParseCVEvaluation validates the model's answer to a CV evaluation prompt
+func ParseCVEvaluation(ctx context.Context, content string) (*domain.CVEvaluationResult, error) {
+	slog.DebugContext(ctx, "openai response", "operation", OperationEvaluateCV, logging.Content("content", content))
 
 	var result domain.CVEvaluationResult
 	if err := json.Unmarshal([]byte(content), &result); err != nil {
-		return nil, fmt.Errorf("failed to parse OpenAI response: %w, content: %s", err, content)
+		slog.DebugContext(ctx, "unparseable openai response", "operation", OperationEvaluateCV, logging.Content("content", content))
+		return nil, fmt.Errorf("failed to parse OpenAI response: %w", err)
 	}
 
 	if strings.Contains(result.Feedback, "Your detailed feedback here") ||
 		strings.Contains(result.Feedback, "Provide your actual") ||
 		strings.Contains(result.Feedback, "placeholder") {
-		return nil, fmt.Errorf("OpenAI returned placeholder text instead of actual feedback. Content: %s", content)
+		slog.DebugContext(ctx, "openai returned placeholder feedback", "operation", OperationEvaluateCV, logging.Content("content", content))
+		return nil, fmt.Errorf("OpenAI returned placeholder text instead of actual feedback")
 	}
 
 	if result.MatchRate < 0 {
@@ -88,11 +135,32 @@ CRITICAL: Write actual specific feedback about THIS candidate, not generic place
 	if result.MatchRate > 1 {
 		result.MatchRate = 1
 	}
+	result.Criteria = result.Criteria.Clean(domain.CVRubric)
 
 	return &result, nil
 }
 
-func (c *OpenAIService) EvaluateProject(reportText, caseStudyContext string) (*domain.ProjectEvaluationResult, error) {
+func (c *OpenAIService) EvaluateProject(ctx context.Context, reportText, caseStudyContext string) (*domain.ProjectEvaluationResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	ctx, done := startCall(ctx, OperationEvaluateProject, openai.ChatModelGPT3_5Turbo)
+	resp, err := c.client.Chat.Completions.New(ctx, projectEvaluationParams(reportText, caseStudyContext))
+	done(err)
+
+	if err != nil {
+		return nil, fmt.Errorf("OpenAI API call failed: %w", err)
+	}
+	c.recordUsage(ctx, OperationEvaluateProject, resp.Model, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("no response from OpenAI")
+	}
+
+	return ParseProjectEvaluation(ctx, resp.Choices[0].Message.Content)
+}
+
+func projectEvaluationParams(reportText, caseStudyContext string) openai.ChatCompletionNewParams {
 	prompt := fmt.Sprintf(`You are an expert technical evaluator assessing a candidate's project submission.
 
 Case Study Requirements and Rubric:
@@ -106,19 +174,17 @@ Analyze this project thoroughly against the provided rubric and requirements.
 Required output format - ONLY JSON:
 {
   "score": [number from 1.0 to 5.0],
-  "feedback": "[Write 4-6 sentences covering: correctness of implementation, code quality assessment, error handling evaluation, and documentation review]"
+  "feedback": "[Write 4-6 sentences covering: correctness of implementation, code quality assessment, error handling evaluation, and documentation review]",
+  "criteria": {[score each of %s from 1.0 to 5.0]}
 }
 
 IMPORTANT:
 - Replace ALL placeholder text with actual project analysis
 - The feedback must be specific to this project submission
 - Score based strictly on the rubric criteria
-- Do not use generic or template language`, caseStudyContext, reportText)
+- Do not use generic or template language`, caseStudyContext, reportText, rubricKeys(domain.ProjectRubric))
 
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
-
-	resp, err := c.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+	return openai.ChatCompletionNewParams{
 		Model: openai.ChatModelGPT3_5Turbo,
 		Messages: []openai.ChatCompletionMessageParamUnion{
 			openai.SystemMessage("You are a technical evaluator. Always respond with valid JSON only, no markdown or extra text."),
@@ -126,21 +192,24 @@ IMPORTANT:
 		},
 		Temperature: openai.Float(0.3),
 		MaxTokens:   openai.Int(1200),
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("OpenAI API call failed: %w", err)
 	}
+}
 
-	if len(resp.Choices) == 0 {
-		return nil, fmt.Errorf("no response from OpenAI")
+// rubricKeys lists the criteria keys for a prompt, e.g. "correctness", "code_quality"
+func rubricKeys(rubric []domain.Criterion) string {
+	keys := make([]string, len(rubric))
+	for i, c := range rubric {
+		keys[i] = strconv.Quote(c.Key)
 	}
+	return strings.Join(keys, ", ")
+}
 
-	content := resp.Choices[0].Message.Content
-
+// ParseProjectEvaluation validates the model's answer to a project evaluation prompt
+func ParseProjectEvaluation(ctx context.Context, content string) (*domain.ProjectEvaluationResult, error) {
 	var result domain.ProjectEvaluationResult
 	if err := json.Unmarshal([]byte(content), &result); err != nil {
-		return nil, fmt.Errorf("failed to parse OpenAI response: %w, content: %s", err, content)
+		slog.DebugContext(ctx, "unparseable openai response", "operation", OperationEvaluateProject, logging.Content("content", content))
+		return nil, fmt.Errorf("failed to parse OpenAI response: %w", err)
 	}
 
 	if result.Score < 1 {
@@ -149,11 +218,32 @@ IMPORTANT:
 	if result.Score > 5 {
 		result.Score = 5
 	}
+	result.Criteria = result.Criteria.Clean(domain.ProjectRubric)
 
 	return &result, nil
 }
 
-func (c *OpenAIService) GenerateSummary(cvFeedback, projectFeedback string, cvMatchRate, projectScore float64) (string, error) {
+func (c *OpenAIService) GenerateSummary(ctx context.Context, cvFeedback, projectFeedback string, cvMatchRate, projectScore float64) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.summaryTimeout)
+	defer cancel()
+
+	ctx, done := startCall(ctx, OperationGenerateSummary, openai.ChatModelGPT3_5Turbo)
+	resp, err := c.client.Chat.Completions.New(ctx, summaryParams(cvFeedback, projectFeedback, cvMatchRate, projectScore))
+	done(err)
+
+	if err != nil {
+		return "", fmt.Errorf("OpenAI API call failed: %w", err)
+	}
+	c.recordUsage(ctx, OperationGenerateSummary, resp.Model, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no response from OpenAI")
+	}
+
+	return resp.Choices[0].Message.Content, nil
+}
+
+func summaryParams(cvFeedback, projectFeedback string, cvMatchRate, projectScore float64) openai.ChatCompletionNewParams {
 	prompt := fmt.Sprintf(`You are an expert hiring manager making a final recommendation.
 
 			CV Evaluation:
@@ -171,10 +261,7 @@ func (c *OpenAIService) GenerateSummary(cvFeedback, projectFeedback string, cvMa
 
 			Return ONLY the summary text, no JSON.`, cvMatchRate, cvFeedback, projectScore, projectFeedback)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 45*time.Second)
-	defer cancel()
-
-	resp, err := c.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+	return openai.ChatCompletionNewParams{
 		Model: openai.ChatModelGPT3_5Turbo,
 		Messages: []openai.ChatCompletionMessageParamUnion{
 			openai.SystemMessage("You are a hiring manager providing concise, actionable recommendations."),
@@ -182,11 +269,33 @@ func (c *OpenAIService) GenerateSummary(cvFeedback, projectFeedback string, cvMa
 		},
 		Temperature: openai.Float(0.4),
 		MaxTokens:   openai.Int(500),
-	})
+	}
+}
+
+// CandidateFeedback is the stored evaluation of one candidate in a comparison
+type CandidateFeedback struct {
+	Label           string // how the model refers to the candidate, e.g. "Candidate A"
+	CVMatchRate     float64
+	CVFeedback      string
+	ProjectScore    float64
+	ProjectFeedback string
+	Summary         string
+}
+
+// CompareCandidates writes a side-by-side comparison of candidates for one
+// opening from their evaluations. The answer is Markdown.
+func (c *OpenAIService) CompareCandidates(ctx context.Context, jobTitle string, candidates []CandidateFeedback, opts EvaluationOptions) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.summaryTimeout)
+	defer cancel()
+
+	ctx, done := startCall(ctx, OperationCompare, openai.ChatModelGPT3_5Turbo)
+	resp, err := c.client.Chat.Completions.New(ctx, compareParams(jobTitle, candidates, opts))
+	done(err)
 
 	if err != nil {
 		return "", fmt.Errorf("OpenAI API call failed: %w", err)
 	}
+	c.recordUsage(ctx, OperationCompare, resp.Model, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
 
 	if len(resp.Choices) == 0 {
 		return "", fmt.Errorf("no response from OpenAI")
@@ -195,20 +304,125 @@ func (c *OpenAIService) GenerateSummary(cvFeedback, projectFeedback string, cvMa
 	return resp.Choices[0].Message.Content, nil
 }
 
+func compareParams(jobTitle string, candidates []CandidateFeedback, opts EvaluationOptions) openai.ChatCompletionNewParams {
+	var evaluations strings.Builder
+	for _, cand := range candidates {
+		fmt.Fprintf(&evaluations, `
+			%s:
+			- CV Match Rate: %.2f
+			- CV Feedback: %s
+			- Project Score: %.1f/5.0
+			- Project Feedback: %s
+			- Summary: %s
+			`, cand.Label, cand.CVMatchRate, cand.CVFeedback, cand.ProjectScore, cand.ProjectFeedback, cand.Summary)
+	}
+
+	prompt := fmt.Sprintf(`You are an expert hiring manager comparing shortlisted candidates for the %s role.
+			Each candidate has already been evaluated; compare them using only these evaluations.
+			%s
+			Write the comparison in Markdown:
+			1. A table with one column per candidate and rows for technical skills, relevant experience, project quality, communication and main risk
+			2. Two or three sentences on how the candidates differ most
+			3. Which candidate fits the role best and why, or what is still unclear if it is too close to call
+
+			Refer to candidates only by their labels.`, jobTitle, evaluations.String())
+
+	system := "You are a hiring manager writing fair, evidence-based candidate comparisons."
+	if opts.Blind {
+		system += fairnessInstructions
+	}
+
+	return openai.ChatCompletionNewParams{
+		Model: openai.ChatModelGPT3_5Turbo,
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage(system),
+			openai.UserMessage(prompt),
+		},
+		Temperature: openai.Float(0.3),
+		MaxTokens:   openai.Int(1200),
+	}
+}
+
+func (c *OpenAIService) ExtractProfile(ctx context.Context, cvText string) (*domain.CandidateProfile, error) {
+	prompt := fmt.Sprintf(`Extract a structured candidate profile from this CV.
+
+CV TEXT:
+%s
+
+Respond ONLY with valid JSON in exactly this shape:
+{
+  "contact": {"name": "", "email": "", "phone": "", "location": ""},
+  "headline": "",
+  "years_experience": 0,
+  "skills": [{"name": "", "years": 0}],
+  "work_history": [{"company": "", "title": "", "start_date": "YYYY-MM", "end_date": "YYYY-MM", "current": false, "description": ""}],
+  "education": [{"institution": "", "degree": "", "field": "", "start_date": "YYYY-MM", "end_date": "YYYY-MM"}],
+  "links": [{"type": "github|linkedin|portfolio|other", "url": ""}]
+}
+
+RULES:
+- Use only information present in the CV; leave fields empty when unknown
+- Dates must be YYYY-MM (use YYYY-01 when only the year is given)
+- Leave end_date empty and set "current": true for ongoing roles
+- Skill years are the years the candidate has used the skill professionally, 0 if unclear`, cvText)
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	ctx, done := startCall(ctx, OperationExtractProfile, openai.ChatModelGPT3_5Turbo)
+	resp, err := c.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model: openai.ChatModelGPT3_5Turbo,
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage("You extract structured data from resumes. Always respond with valid JSON only, no markdown or extra text."),
+			openai.UserMessage(prompt),
+		},
+		Temperature: openai.Float(0),
+		MaxTokens:   openai.Int(1500),
+	})
+	done(err)
+
+	if err != nil {
+		return nil, fmt.Errorf("OpenAI API call failed: %w", err)
+	}
+	c.recordUsage(ctx, OperationExtractProfile, resp.Model, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("no response from OpenAI")
+	}
+
+	content := resp.Choices[0].Message.Content
+
+	var profile domain.CandidateProfile
+	if err := json.Unmarshal([]byte(content), &profile); err != nil {
+		slog.DebugContext(ctx, "unparseable openai response", "operation", OperationExtractProfile, logging.Content("content", content))
+		return nil, fmt.Errorf("failed to parse OpenAI response: %w", err)
+	}
+
+	// Prefer years derived from dated work history over the model's estimate
+	if years := profile.ComputeYearsExperience(time.Now()); years > 0 {
+		profile.YearsExperience = years
+	}
+
+	return &profile, nil
+}
+
 func (c *OpenAIService) GenerateEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
 	embeddings := make([][]float64, len(texts))
 
 	for i, text := range texts {
-		resp, err := c.client.Embeddings.New(ctx, openai.EmbeddingNewParams{
+		callCtx, done := startCall(ctx, OperationEmbedding, openai.EmbeddingModelTextEmbeddingAda002)
+		resp, err := c.client.Embeddings.New(callCtx, openai.EmbeddingNewParams{
 			Model: openai.EmbeddingModelTextEmbeddingAda002,
 			Input: openai.EmbeddingNewParamsInputUnion{
 				OfString: openai.String(text),
 			},
 		})
+		done(err)
 
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate embedding for text %d: %w", i, err)
 		}
+		c.recordUsage(ctx, OperationEmbedding, resp.Model, resp.Usage.PromptTokens, 0)
 
 		if len(resp.Data) == 0 {
 			return nil, fmt.Errorf("no embedding data returned for text %d", i)
@@ -220,4 +434,26 @@ func (c *OpenAIService) GenerateEmbeddings(ctx context.Context, texts []string)
 	}
 
 	return embeddings, nil
-}
\ No newline at end of file
+}
+
+// startCall opens a span for an LLM call; the returned function records
+// its latency and outcome and ends the span
+func startCall(ctx context.Context, operation, model string) (context.Context, func(error)) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "llm."+operation,
+		attribute.String("gen_ai.system", "openai"),
+		attribute.String("gen_ai.operation.name", operation),
+		attribute.String("gen_ai.request.model", model),
+	)
+	return ctx, func(err error) {
+		metrics.ObserveLLMCall(operation, model, start, err)
+		tracing.End(span, err)
+	}
+}
+
+// Ping checks that the API key works and the chat model is available. It
+// makes a models lookup, which is free and does not consume tokens.
+func (c *OpenAIService) Ping(ctx context.Context) error {
+	_, err := c.client.Models.Get(ctx, string(openai.ChatModelGPT3_5Turbo))
+	return err
+}
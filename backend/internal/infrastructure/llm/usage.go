@@ -0,0 +1,102 @@
+package llm
+
+import (
+	"context"
+	"strings"
+
+	"github.com/adyutaa/parsea/internal/domain"
+)
+
+// Operation names recorded with each call
+const (
+	OperationEvaluateCV      = "evaluate_cv"
+	OperationEvaluateProject = "evaluate_project"
+	OperationGenerateSummary = "generate_summary"
+	OperationCompare         = "compare_candidates"
+	OperationExtractProfile  = "extract_profile"
+	OperationEmbedding       = "embedding"
+)
+
+// UsageRecorder persists the usage of a call
+type UsageRecorder func(ctx context.Context, usage *domain.LLMUsage)
+
+type usageScopeKey struct{}
+
+type usageScope struct {
+	tenantID string
+	jobID    *uint
+}
+
+// WithUsageScope attributes the LLM calls made with ctx to a tenant and, optionally, a job
+func WithUsageScope(ctx context.Context, tenantID string, jobID *uint) context.Context {
+	return context.WithValue(ctx, usageScopeKey{}, usageScope{tenantID: tenantID, jobID: jobID})
+}
+
+// modelPrice is the USD price per million tokens
+type modelPrice struct {
+	prompt     float64
+	completion float64
+}
+
+// Prices per million tokens; prefixes match dated model snapshots
+var modelPrices = map[string]modelPrice{
+	"gpt-3.5-turbo":          {prompt: 0.50, completion: 1.50},
+	"gpt-4o-mini":            {prompt: 0.15, completion: 0.60},
+	"gpt-4o":                 {prompt: 2.50, completion: 10.00},
+	"gpt-4-turbo":            {prompt: 10.00, completion: 30.00},
+	"text-embedding-ada-002": {prompt: 0.10},
+	"text-embedding-3-small": {prompt: 0.02},
+	"text-embedding-3-large": {prompt: 0.13},
+}
+
+// EstimateCost returns the estimated USD cost of a call, zero for unknown models
+func EstimateCost(model string, promptTokens, completionTokens int64) float64 {
+	price, ok := modelPrices[model]
+	if !ok {
+		// Dated snapshots such as gpt-3.5-turbo-0125 share their base model's price
+		best := ""
+		for name := range modelPrices {
+			if strings.HasPrefix(model, name) && len(name) > len(best) {
+				best = name
+			}
+		}
+		if best == "" {
+			return 0
+		}
+		price = modelPrices[best]
+	}
+
+	return (float64(promptTokens)*price.prompt + float64(completionTokens)*price.completion) / 1_000_000
+}
+
+// SetUsageRecorder registers where call usage is stored
+func (c *OpenAIService) SetUsageRecorder(recorder UsageRecorder) {
+	c.recorder = recorder
+}
+
+func (c *OpenAIService) recordUsage(ctx context.Context, operation, model string, promptTokens, completionTokens int64) {
+	c.record(ctx, operation, model, promptTokens, completionTokens, EstimateCost(model, promptTokens, completionTokens))
+}
+
+func (c *OpenAIService) record(ctx context.Context, operation, model string, promptTokens, completionTokens int64, costUSD float64) {
+	if c.recorder == nil {
+		return
+	}
+
+	scope, _ := ctx.Value(usageScopeKey{}).(usageScope)
+	tenantID := scope.tenantID
+	if tenantID == "" {
+		tenantID = domain.DefaultTenantID
+	}
+
+	c.recorder(ctx, &domain.LLMUsage{
+		TenantID:         tenantID,
+		JobID:            scope.jobID,
+		Operation:        operation,
+		Model:            model,
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      promptTokens + completionTokens,
+		CostUSD:          costUSD,
+	})
+}
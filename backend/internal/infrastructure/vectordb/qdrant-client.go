@@ -3,10 +3,12 @@ package vectordb
 import (
 	"context"
 	"fmt"
-	"os"
-	"strconv"
+	"log/slog"
 
+	"github.com/adyutaa/parsea/internal/config"
+	"github.com/adyutaa/parsea/internal/tracing"
 	"github.com/qdrant/go-client/qdrant"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type QdrantClient struct {
@@ -14,32 +16,23 @@ type QdrantClient struct {
 	collectionName string
 }
 
-func NewQdrantClient() (*QdrantClient, error) {
-	host := os.Getenv("QDRANT_HOST")
-	portStr := os.Getenv("QDRANT_PORT")
-	apiKey := os.Getenv("QDRANT_API_KEY")
-
-	if host == "" || portStr == "" || apiKey == "" {
+func NewQdrantClient(cfg config.QdrantConfig) (*QdrantClient, error) {
+	if !cfg.Enabled() {
 		return nil, fmt.Errorf("Qdrant configuration not set")
 	}
 
-	port, err := strconv.Atoi(portStr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid QDRANT_PORT: %w", err)
-	}
-
-	// Create Qdrant client
+	// Create Qdrant client; a local server typically runs without TLS or an API key
 	client, err := qdrant.NewClient(&qdrant.Config{
-		Host:   host,
-		Port:   port,
-		APIKey: apiKey,
-		UseTLS: true,
+		Host:   cfg.Host,
+		Port:   cfg.Port,
+		APIKey: cfg.APIKey,
+		UseTLS: cfg.UseTLS,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Qdrant client: %w", err)
 	}
 
-	collectionName := "job_requirements"
+	collectionName := cfg.Collection
 
 	// Check if collection exists
 	ctx := context.Background()
@@ -68,7 +61,7 @@ func NewQdrantClient() (*QdrantClient, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to create collection: %w", err)
 		}
-		fmt.Println("✅ Created Qdrant collection:", collectionName)
+		slog.Info("created qdrant collection", "collection", collectionName)
 	}
 
 	return &QdrantClient{
@@ -121,10 +114,16 @@ func (q *QdrantClient) AddDocuments(ctx context.Context, docs []Document, embedd
 		}
 	}
 
+	ctx, span := tracing.Start(ctx, "qdrant.upsert",
+		attribute.String("db.system", "qdrant"),
+		attribute.String("db.collection.name", q.collectionName),
+		attribute.Int("qdrant.points", len(points)),
+	)
 	_, err := q.client.Upsert(ctx, &qdrant.UpsertPoints{
 		CollectionName: q.collectionName,
 		Points:         points,
 	})
+	tracing.End(span, err)
 
 	return err
 }
@@ -139,12 +138,18 @@ type SearchResult struct {
 
 // Search searches for similar documents using a query embedding
 func (q *QdrantClient) Search(ctx context.Context, queryEmbedding []float32, limit uint64) ([]SearchResult, error) {
+	ctx, span := tracing.Start(ctx, "qdrant.search",
+		attribute.String("db.system", "qdrant"),
+		attribute.String("db.collection.name", q.collectionName),
+		attribute.Int64("qdrant.limit", int64(limit)),
+	)
 	searchResult, err := q.client.Query(ctx, &qdrant.QueryPoints{
 		CollectionName: q.collectionName,
 		Query:          qdrant.NewQuery(queryEmbedding...),
 		Limit:          &limit,
 		WithPayload:    qdrant.NewWithPayload(true),
 	})
+	tracing.End(span, err)
 
 	if err != nil {
 		return nil, fmt.Errorf("search failed: %w", err)
@@ -198,6 +203,67 @@ func (q *QdrantClient) Search(ctx context.Context, queryEmbedding []float32, lim
 	return results, nil
 }
 
+// Payload keys that tie a point to the uploaded document it was derived
+// from. Reference material ingested by scripts/ingest.go has neither.
+const (
+	PayloadDocumentID = "document_id"
+	PayloadTenantID   = "tenant_id"
+)
+
+// DeleteDocumentVectors deletes the points derived from the given documents
+// of a tenant and returns how many there were
+func (q *QdrantClient) DeleteDocumentVectors(ctx context.Context, tenantID string, documentIDs []uint) (int, error) {
+	if len(documentIDs) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]int64, len(documentIDs))
+	for i, id := range documentIDs {
+		ids[i] = int64(id)
+	}
+	filter := &qdrant.Filter{
+		Must: []*qdrant.Condition{
+			qdrant.NewMatch(PayloadTenantID, tenantID),
+			qdrant.NewMatchInts(PayloadDocumentID, ids...),
+		},
+	}
+
+	ctx, span := tracing.Start(ctx, "qdrant.delete",
+		attribute.String("db.system", "qdrant"),
+		attribute.String("db.collection.name", q.collectionName),
+		attribute.Int("qdrant.documents", len(ids)),
+	)
+	exact := true
+	count, err := q.client.Count(ctx, &qdrant.CountPoints{
+		CollectionName: q.collectionName,
+		Filter:         filter,
+		Exact:          &exact,
+	})
+	if err == nil && count > 0 {
+		wait := true
+		_, err = q.client.Delete(ctx, &qdrant.DeletePoints{
+			CollectionName: q.collectionName,
+			Wait:           &wait,
+			Points:         qdrant.NewPointsSelectorFilter(filter),
+		})
+	}
+	tracing.End(span, err)
+
+	if err != nil {
+		return 0, fmt.Errorf("delete document vectors failed: %w", err)
+	}
+	return int(count), nil
+}
+
+// HealthCheck verifies the Qdrant server is reachable and returns its version
+func (q *QdrantClient) HealthCheck(ctx context.Context) (string, error) {
+	reply, err := q.client.HealthCheck(ctx)
+	if err != nil {
+		return "", err
+	}
+	return reply.GetVersion(), nil
+}
+
 // DeleteCollection deletes the collection
 func (q *QdrantClient) DeleteCollection(ctx context.Context) error {
 	return q.client.DeleteCollection(ctx, q.collectionName)
@@ -0,0 +1,168 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Config selects the log level and output format
+type Config struct {
+	Level  string // debug, info, warn or error
+	Format string // json or text
+}
+
+type contextKey int
+
+const (
+	jobIDKey contextKey = iota
+	requestIDKey
+	tenantKey
+)
+
+// Init installs the default slog logger. Every record logged with a context
+// gets the job_id, request_id and tenant stored on it, plus the trace ID.
+func Init(cfg Config) error {
+	logger, err := New(os.Stdout, cfg)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// New builds a logger writing to w
+func New(w io.Writer, cfg Config) (*slog.Logger, error) {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(cfg.Format) {
+	case "", "json":
+		handler = slog.NewJSONHandler(w, opts)
+	case "text":
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("unknown log format %q", cfg.Format)
+	}
+
+	return slog.New(&contextHandler{next: handler}), nil
+}
+
+// ParseLevel converts a level name into a slog.Level
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if name == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q", name)
+	}
+	return level, nil
+}
+
+// WithJobID attaches an evaluation job ID to ctx for logging
+func WithJobID(ctx context.Context, jobID string) context.Context {
+	return context.WithValue(ctx, jobIDKey, jobID)
+}
+
+// WithRequestID attaches the HTTP request ID to ctx for logging
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// WithTenant attaches the tenant ID to ctx for logging
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey, tenantID)
+}
+
+// RequestID returns the request ID stored on ctx, if any
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// Err is the attribute used for errors on every log line
+func Err(err error) slog.Attr {
+	return slog.Any("error", err)
+}
+
+// content marks candidate data or LLM payloads so the handler can withhold them
+type content string
+
+// Content wraps CV text, prompts or LLM responses. The value is only written
+// at debug level; at any other level only its length is logged.
+func Content(key, value string) slog.Attr {
+	return slog.Any(key, content(value))
+}
+
+// contextHandler adds correlation IDs from the context and enforces the
+// content policy before handing the record on
+type contextHandler struct {
+	next slog.Handler
+}
+
+func (h *contextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	out := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+
+	if ctx != nil {
+		for _, field := range []struct {
+			key   string
+			value contextKey
+		}{
+			{"job_id", jobIDKey},
+			{"request_id", requestIDKey},
+			{"tenant", tenantKey},
+		} {
+			if v, ok := ctx.Value(field.value).(string); ok && v != "" {
+				out.AddAttrs(slog.String(field.key, v))
+			}
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			out.AddAttrs(slog.String("trace_id", sc.TraceID().String()))
+		}
+	}
+
+	record.Attrs(func(attr slog.Attr) bool {
+		out.AddAttrs(h.redact(record.Level, attr))
+		return true
+	})
+
+	return h.next.Handle(ctx, out)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	// Attributes bound with With have no level yet, so content is always withheld
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = h.redact(slog.LevelInfo, attr)
+	}
+	return &contextHandler{next: h.next.WithAttrs(redacted)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{next: h.next.WithGroup(name)}
+}
+
+func (h *contextHandler) redact(level slog.Level, attr slog.Attr) slog.Attr {
+	value, ok := attr.Value.Any().(content)
+	if !ok {
+		return attr
+	}
+	if level <= slog.LevelDebug {
+		return slog.String(attr.Key, string(value))
+	}
+	return slog.String(attr.Key, fmt.Sprintf("[withheld: %d bytes]", len(value)))
+}
@@ -0,0 +1,155 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "parsea"
+
+var (
+	// JobsTotal counts evaluation jobs by final status
+	JobsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "jobs_total",
+		Help:      "Evaluation jobs processed, by final status.",
+	}, []string{"status"})
+
+	// StepDuration times each step of the evaluation pipeline
+	StepDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "pipeline_step_duration_seconds",
+		Help:      "Duration of evaluation pipeline steps.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60},
+	}, []string{"step", "outcome"})
+
+	// LLMRequestDuration times LLM and embedding calls
+	LLMRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "llm_request_duration_seconds",
+		Help:      "Latency of LLM API calls.",
+		Buckets:   []float64{0.25, 0.5, 1, 2, 4, 8, 15, 30, 60},
+	}, []string{"method", "model"})
+
+	// LLMErrors counts failed LLM calls
+	LLMErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "llm_errors_total",
+		Help:      "Failed LLM API calls.",
+	}, []string{"method", "model"})
+
+	// ExtractionsTotal counts document text extractions by strategy
+	ExtractionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "extractions_total",
+		Help:      "Document text extractions, by MIME type and strategy.",
+	}, []string{"mime_type", "strategy"})
+
+	// OutboxPublished counts outbox messages published to Redis
+	OutboxPublished = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "outbox_published_total",
+		Help:      "Outbox messages published to the queue.",
+	})
+
+	// LLMBatchesTotal counts provider batches by stage and outcome
+	LLMBatchesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "llm_batches_total",
+		Help:      "LLM batch API batches, by stage and outcome (submitted, completed, failed).",
+	}, []string{"stage", "outcome"})
+
+	// ErasuresTotal counts erasures of candidate data by reason
+	ErasuresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "erasures_total",
+		Help:      "Erasures of candidate data, by reason (request, retention).",
+	}, []string{"reason"})
+
+	// ErasedDocuments counts documents deleted by erasures
+	ErasedDocuments = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "erased_documents_total",
+		Help:      "Documents deleted by erasures, by reason (request, retention).",
+	}, []string{"reason"})
+
+	// HTTPRequestsTotal counts HTTP requests
+	HTTPRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests, by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestDuration times HTTP requests
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency, by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+)
+
+// ObserveStep records how long a pipeline step took since start
+func ObserveStep(step string, start time.Time, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	StepDuration.WithLabelValues(step, outcome).Observe(time.Since(start).Seconds())
+}
+
+// ObserveLLMCall records the latency of an LLM call and counts it as an error if it failed
+func ObserveLLMCall(method, model string, start time.Time, err error) {
+	LLMRequestDuration.WithLabelValues(method, model).Observe(time.Since(start).Seconds())
+	if err != nil {
+		LLMErrors.WithLabelValues(method, model).Inc()
+	}
+}
+
+// RegisterQueueDepth exposes the length of one evaluation queue, read on every scrape
+func RegisterQueueDepth(queue string, depth func() (int64, error)) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "queue_depth",
+		Help:        "Jobs waiting in an evaluation queue.",
+		ConstLabels: prometheus.Labels{"queue": queue},
+	}, func() float64 {
+		n, err := depth()
+		if err != nil {
+			return -1
+		}
+		return float64(n)
+	})
+}
+
+// RegisterOutboxPending exposes how many outbox messages await publishing
+func RegisterOutboxPending(pending func() (int64, error)) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "outbox_pending",
+		Help:      "Outbox messages not yet published to the queue.",
+	}, func() float64 {
+		n, err := pending()
+		if err != nil {
+			return -1
+		}
+		return float64(n)
+	})
+}
+
+// RegisterLLMBatchesInFlight exposes how many provider batches are awaiting results
+func RegisterLLMBatchesInFlight(inFlight func() (int64, error)) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "llm_batches_in_flight",
+		Help:      "Batches submitted to the LLM batch API and not yet finished.",
+	}, func() float64 {
+		n, err := inFlight()
+		if err != nil {
+			return -1
+		}
+		return float64(n)
+	})
+}
@@ -0,0 +1,274 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/adyutaa/parsea/internal/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed from the store
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+var idempotencyKeyPattern = regexp.MustCompile(`^[\x21-\x7e]{1,255}$`)
+
+var errIdempotentBodyTooLarge = errors.New("request body too large")
+
+// idempotencyRecord is what is stored in Redis per key. While the first
+// request is running only Fingerprint is set.
+type idempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"`
+	Done        bool   `json:"done"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Idempotency replays stored responses for requests repeated with the same
+// Idempotency-Key header, so client retries don't create duplicates
+type Idempotency struct {
+	redis *redis.Client
+	// ttl is how long a completed response is replayed
+	ttl time.Duration
+	// lockTTL bounds how long an in-flight request holds its key, in case the
+	// process dies before storing the response
+	lockTTL time.Duration
+	// maxBody caps the request body; larger requests are rejected
+	maxBody int64
+}
+
+func NewIdempotency(rdb *redis.Client, ttl, lockTTL time.Duration, maxBody int64) *Idempotency {
+	return &Idempotency{redis: rdb, ttl: ttl, lockTTL: lockTTL, maxBody: maxBody}
+}
+
+// Handle returns middleware for the named route. Requests without the header
+// pass straight through.
+func (i *Idempotency) Handle(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if !idempotencyKeyPattern.MatchString(key) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": IdempotencyKeyHeader + " must be 1-255 printable ASCII characters",
+			})
+			return
+		}
+
+		ctx := c.Request.Context()
+		fingerprint, cleanup, err := i.fingerprint(c)
+		defer cleanup()
+		if errors.Is(err, errIdempotentBodyTooLarge) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		redisKey := "idempotency:" + TenantID(c) + ":" + name + ":" + key
+		record, acquired, err := i.acquire(ctx, redisKey, fingerprint)
+		if err != nil {
+			// Fail open like the rate limiter: a Redis outage must not block uploads
+			slog.WarnContext(ctx, "idempotency store unavailable, processing request", logging.Err(err))
+			c.Next()
+			return
+		}
+
+		if !acquired {
+			switch {
+			case record.Fingerprint != fingerprint:
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+					"error": IdempotencyKeyHeader + " was already used with a different request body",
+				})
+			case !record.Done:
+				c.Header("Retry-After", "1")
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+					"error": "a request with this " + IdempotencyKeyHeader + " is still in progress",
+				})
+			default:
+				c.Header(IdempotentReplayedHeader, "true")
+				c.Data(record.Status, record.ContentType, record.Body)
+				c.Abort()
+			}
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// Responses that may differ on retry, e.g. after a budget reset or
+		// once the rate limit refills, release the key instead of being stored
+		status := recorder.Status()
+		if !replayable(status) {
+			if err := i.redis.Del(context.Background(), redisKey).Err(); err != nil {
+				slog.WarnContext(ctx, "failed to release idempotency key", logging.Err(err))
+			}
+			return
+		}
+
+		done, _ := json.Marshal(idempotencyRecord{
+			Fingerprint: fingerprint,
+			Done:        true,
+			Status:      status,
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		})
+		if err := i.redis.Set(context.Background(), redisKey, done, i.ttl).Err(); err != nil {
+			slog.WarnContext(ctx, "failed to store idempotent response", logging.Err(err))
+		}
+	}
+}
+
+// replayable reports whether a response is stored for replay: successes and
+// client errors that the same request would get again. Payment required,
+// timeouts, conflicts, throttling and server errors are worth retrying.
+func replayable(status int) bool {
+	switch {
+	case status >= 200 && status < 300:
+		return true
+	case status >= 400 && status < 500:
+		switch status {
+		case http.StatusPaymentRequired, http.StatusRequestTimeout, http.StatusConflict, http.StatusTooManyRequests:
+			return false
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// acquire claims the key for this request, or returns the existing record
+func (i *Idempotency) acquire(ctx context.Context, key, fingerprint string) (*idempotencyRecord, bool, error) {
+	pending, _ := json.Marshal(idempotencyRecord{Fingerprint: fingerprint})
+	ok, err := i.redis.SetNX(ctx, key, pending, i.lockTTL).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if ok {
+		return nil, true, nil
+	}
+
+	raw, err := i.redis.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// Expired between SETNX and GET; try once more
+		ok, err = i.redis.SetNX(ctx, key, pending, i.lockTTL).Result()
+		if err != nil || ok {
+			return nil, ok, err
+		}
+		raw, err = i.redis.Get(ctx, key).Bytes()
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal(raw, &record); err != nil {
+		return nil, false, err
+	}
+	return &record, false, nil
+}
+
+// fingerprint hashes the request body. Multipart bodies are hashed part by
+// part (field name, filename, content) because clients pick a new random
+// boundary for every retry. The body is spooled to a temp file, capped at
+// maxBody, and the request reads it from there; cleanup removes the file
+// and must be called once the request is done.
+func (i *Idempotency) fingerprint(c *gin.Context) (string, func(), error) {
+	noop := func() {}
+	spool, err := os.CreateTemp("", "idempotency-*")
+	if err != nil {
+		return "", noop, errors.New("failed to read request body")
+	}
+	cleanup := func() {
+		spool.Close()
+		os.Remove(spool.Name())
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, i.maxBody)
+	if _, err := io.Copy(spool, body); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return "", cleanup, errIdempotentBodyTooLarge
+		}
+		return "", cleanup, errors.New("failed to read request body")
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return "", cleanup, errors.New("failed to read request body")
+	}
+
+	h := sha256.New()
+	io.WriteString(h, c.Request.Method+" "+c.FullPath()+"\n")
+
+	mediaType, params, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if mediaType == "multipart/form-data" && params["boundary"] != "" {
+		if err := hashMultipart(h, spool, params["boundary"]); err != nil {
+			return "", cleanup, errors.New("malformed multipart body")
+		}
+	} else if _, err := io.Copy(h, spool); err != nil {
+		return "", cleanup, errors.New("failed to read request body")
+	}
+
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return "", cleanup, errors.New("failed to read request body")
+	}
+	c.Request.Body = io.NopCloser(spool)
+	return hex.EncodeToString(h.Sum(nil)), cleanup, nil
+}
+
+func hashMultipart(h hash.Hash, body io.Reader, boundary string) error {
+	reader := multipart.NewReader(body, boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		io.WriteString(h, part.FormName()+"\x00"+part.FileName()+"\x00")
+		partHash := sha256.New()
+		if _, err := io.Copy(partHash, part); err != nil {
+			return err
+		}
+		h.Write(partHash.Sum(nil))
+	}
+}
+
+// responseRecorder keeps a copy of the response body for storage
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
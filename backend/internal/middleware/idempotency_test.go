@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fingerprintOf runs Idempotency.fingerprint on a request and returns the
+// hash and the body the handler would read
+func fingerprintOf(t *testing.T, maxBody int64, contentType string, body []byte) (string, []byte, error) {
+	t.Helper()
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/upload", bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", contentType)
+
+	fingerprint, cleanup, err := (&Idempotency{maxBody: maxBody}).fingerprint(c)
+	defer cleanup()
+	if err != nil {
+		return "", nil, err
+	}
+	seen, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		t.Fatalf("read body after fingerprinting: %v", err)
+	}
+	return fingerprint, seen, nil
+}
+
+func multipartBody(t *testing.T, boundary string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	if err := w.SetBoundary(boundary); err != nil {
+		t.Fatal(err)
+	}
+	part, err := w.CreateFormFile("cv", "cv.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(part, "Jane Doe\nBackend engineer\n")
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestFingerprintIgnoresMultipartBoundary(t *testing.T) {
+	first := multipartBody(t, "boundary-one")
+	second := multipartBody(t, "boundary-two")
+
+	a, body, err := fingerprintOf(t, 1<<20, "multipart/form-data; boundary=boundary-one", first)
+	if err != nil {
+		t.Fatalf("fingerprint: %v", err)
+	}
+	if !bytes.Equal(body, first) {
+		t.Errorf("handler reads %d bytes, want the original %d", len(body), len(first))
+	}
+	b, _, err := fingerprintOf(t, 1<<20, "multipart/form-data; boundary=boundary-two", second)
+	if err != nil {
+		t.Fatalf("fingerprint: %v", err)
+	}
+	if a != b {
+		t.Errorf("fingerprints differ for the same parts under a new boundary")
+	}
+}
+
+func TestFingerprintRejectsLargeBody(t *testing.T) {
+	_, _, err := fingerprintOf(t, 16, "application/json", []byte(strings.Repeat("x", 17)))
+	if !errors.Is(err, errIdempotentBodyTooLarge) {
+		t.Errorf("err = %v, want %v", err, errIdempotentBodyTooLarge)
+	}
+	if _, _, err := fingerprintOf(t, 16, "application/json", []byte(strings.Repeat("x", 16))); err != nil {
+		t.Errorf("body at the limit: %v", err)
+	}
+}
+
+func TestReplayable(t *testing.T) {
+	for status, want := range map[int]bool{
+		http.StatusOK:                  true,
+		http.StatusAccepted:            true,
+		http.StatusBadRequest:          true,
+		http.StatusNotFound:            true,
+		http.StatusPaymentRequired:     false,
+		http.StatusConflict:            false,
+		http.StatusTooManyRequests:     false,
+		http.StatusInternalServerError: false,
+		http.StatusServiceUnavailable:  false,
+	} {
+		if got := replayable(status); got != want {
+			t.Errorf("replayable(%d) = %v, want %v", status, got, want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/adyutaa/parsea/internal/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBuckets refills each bucket in KEYS at rate tokens per second up to
+// burst. A request takes one token from every bucket, or none when any of
+// them is empty, so a rejection by one bucket doesn't drain the others. It
+// returns {allowed, fewest tokens left, ms until every bucket has a token}.
+var tokenBuckets = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local tokens = {}
+local allowed = 1
+local wait = 0
+for i, key in ipairs(KEYS) do
+  local state = redis.call("HMGET", key, "tokens", "ts")
+  local t = tonumber(state[1]) or burst
+  local ts = tonumber(state[2]) or now
+  t = math.min(burst, t + math.max(0, now - ts) / 1000 * rate)
+  if t < 1 then
+    allowed = 0
+    wait = math.max(wait, math.ceil((1 - t) / rate * 1000))
+  end
+  tokens[i] = t
+end
+
+local left = burst
+for i, key in ipairs(KEYS) do
+  if allowed == 1 then
+    tokens[i] = tokens[i] - 1
+  end
+  redis.call("HSET", key, "tokens", tokens[i], "ts", now)
+  redis.call("PEXPIRE", key, math.ceil(burst / rate * 1000) + 1000)
+  left = math.min(left, math.floor(tokens[i]))
+end
+
+return {allowed, left, wait}
+`)
+
+// RateLimiter applies Redis-backed token buckets per client IP and per
+// authenticated API key
+type RateLimiter struct {
+	redis *redis.Client
+	rate  float64 // tokens per second
+	burst int
+}
+
+func NewRateLimiter(rdb *redis.Client, perMinute, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		redis: rdb,
+		rate:  float64(perMinute) / 60,
+		burst: burst,
+	}
+}
+
+// Limit returns middleware that throttles the named route group
+func (l *RateLimiter) Limit(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l.rate <= 0 {
+			c.Next()
+			return
+		}
+
+		keys := []string{fmt.Sprintf("ratelimit:%s:ip:%s", name, c.ClientIP())}
+		if apiKey := APIKey(c); apiKey != nil {
+			keys = append(keys, fmt.Sprintf("ratelimit:%s:key:%d", name, apiKey.ID))
+		}
+
+		allowed, remaining, wait, err := l.take(c.Request.Context(), keys)
+		if err != nil {
+			// Fail open: throttling must not take the API down with Redis
+			slog.WarnContext(c.Request.Context(), "rate limiter unavailable, allowing request", logging.Err(err))
+			c.Next()
+			return
+		}
+
+		if !allowed {
+			retryAfter := int(math.Ceil(wait.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.Header("X-RateLimit-Limit", strconv.Itoa(l.burst))
+			c.Header("X-RateLimit-Remaining", "0")
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":       "rate limit exceeded",
+				"retry_after": retryAfter,
+			})
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(l.burst))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Next()
+	}
+}
+
+// take charges one request against the IP and API key buckets together
+func (l *RateLimiter) take(ctx context.Context, keys []string) (bool, int, time.Duration, error) {
+	res, err := tokenBuckets.Run(ctx, l.redis, keys, l.rate, l.burst, time.Now().UnixMilli()).Int64Slice()
+	if err != nil {
+		return false, 0, 0, err
+	}
+	if len(res) != 3 {
+		return false, 0, 0, fmt.Errorf("unexpected rate limiter reply: %v", res)
+	}
+	return res[0] == 1, int(res[1]), time.Duration(res[2]) * time.Millisecond, nil
+}
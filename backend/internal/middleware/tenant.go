@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/adyutaa/parsea/internal/domain"
+	"github.com/adyutaa/parsea/internal/logging"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	APIKeyHeader     = "X-API-Key"
+	TenantHeader     = "X-Tenant-ID"
+	tenantContextKey = "tenant_id"
+	apiKeyContextKey = "api_key"
+)
+
+// KeyAuthenticator resolves an API key to its stored record, returning nil
+// for keys that don't exist or were revoked
+type KeyAuthenticator interface {
+	Authenticate(key string) (*domain.APIKey, error)
+}
+
+// Authenticate requires a valid X-API-Key on every route except the public
+// ones and scopes the request to the key's tenant. An X-Tenant-ID header is
+// optional; when sent it must name that same tenant.
+func Authenticate(keys KeyAuthenticator, public ...string) gin.HandlerFunc {
+	open := make(map[string]bool, len(public))
+	for _, path := range public {
+		open[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if open[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		plain := c.GetHeader(APIKeyHeader)
+		if plain == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "missing " + APIKeyHeader + " header",
+			})
+			return
+		}
+
+		key, err := keys.Authenticate(plain)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "failed to authenticate request", logging.Err(err))
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"error": "authentication unavailable",
+			})
+			return
+		}
+		if key == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "invalid API key",
+			})
+			return
+		}
+
+		if tenantID := c.GetHeader(TenantHeader); tenantID != "" && tenantID != key.TenantID {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "API key does not belong to tenant " + tenantID,
+			})
+			return
+		}
+
+		c.Set(apiKeyContextKey, key)
+		c.Set(tenantContextKey, key.TenantID)
+		c.Request = c.Request.WithContext(logging.WithTenant(c.Request.Context(), key.TenantID))
+		c.Next()
+	}
+}
+
+// TenantID returns the tenant of the request's API key, empty on public routes
+func TenantID(c *gin.Context) string {
+	return c.GetString(tenantContextKey)
+}
+
+// APIKey returns the key that authenticated the request, nil on public routes
+func APIKey(c *gin.Context) *domain.APIKey {
+	if key, ok := c.Get(apiKeyContextKey); ok {
+		return key.(*domain.APIKey)
+	}
+	return nil
+}
+
+// RequireRole rejects requests whose API key has none of the given roles
+func RequireRole(roles ...domain.APIKeyRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key := APIKey(c); key != nil {
+			for _, role := range roles {
+				if key.Role == role {
+					c.Next()
+					return
+				}
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error": "this API key may not " + c.Request.Method + " " + c.FullPath(),
+		})
+	}
+}
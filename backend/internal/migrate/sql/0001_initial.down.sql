@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.evaluation_jobs;
+DROP TABLE IF EXISTS public.documents;
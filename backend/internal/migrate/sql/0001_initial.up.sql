@@ -1,10 +1,6 @@
-
-
--- Drop existing tables and recreate with auto-incrementing integers
-DROP TABLE IF EXISTS public.evaluation_jobs CASCADE;
-DROP TABLE IF EXISTS public.documents CASCADE;
-
-CREATE TABLE public.documents (
+-- Baseline schema. IF NOT EXISTS lets databases created from the old
+-- schema script adopt migrations without losing data.
+CREATE TABLE IF NOT EXISTS public.documents (
   id SERIAL PRIMARY KEY,
   filename character varying NOT NULL,
   file_path text NOT NULL,
@@ -13,7 +9,7 @@ CREATE TABLE public.documents (
   uploaded_at timestamp without time zone DEFAULT now()
 );
 
-CREATE TABLE public.evaluation_jobs (
+CREATE TABLE IF NOT EXISTS public.evaluation_jobs (
   id SERIAL PRIMARY KEY,
   cv_id INTEGER NOT NULL,
   report_id INTEGER NOT NULL,
@@ -27,6 +23,6 @@ CREATE TABLE public.evaluation_jobs (
   CONSTRAINT evaluation_jobs_report_id_fkey FOREIGN KEY (report_id) REFERENCES public.documents(id)
 );
 
-CREATE INDEX idx_documents_type ON public.documents(doc_type);
-CREATE INDEX idx_jobs_status ON public.evaluation_jobs(status);
-CREATE INDEX idx_jobs_created ON public.evaluation_jobs(created_at);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_documents_type ON public.documents(doc_type);
+CREATE INDEX IF NOT EXISTS idx_jobs_status ON public.evaluation_jobs(status);
+CREATE INDEX IF NOT EXISTS idx_jobs_created ON public.evaluation_jobs(created_at);
@@ -0,0 +1,5 @@
+ALTER TABLE public.documents
+  DROP COLUMN IF EXISTS profile,
+  DROP COLUMN IF EXISTS extraction_confidence,
+  DROP COLUMN IF EXISTS extraction_strategy,
+  DROP COLUMN IF EXISTS mime_type;
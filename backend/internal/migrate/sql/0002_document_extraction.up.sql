@@ -0,0 +1,6 @@
+-- Multi-format extraction, OCR and structured candidate profiles
+ALTER TABLE public.documents
+  ADD COLUMN IF NOT EXISTS mime_type character varying,
+  ADD COLUMN IF NOT EXISTS extraction_strategy character varying,
+  ADD COLUMN IF NOT EXISTS extraction_confidence double precision,
+  ADD COLUMN IF NOT EXISTS profile jsonb;
@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS public.llm_usage;
+
+ALTER TABLE public.evaluation_jobs
+  DROP COLUMN IF EXISTS blind_counts,
+  DROP COLUMN IF EXISTS blind,
+  DROP COLUMN IF EXISTS redaction_counts,
+  DROP COLUMN IF EXISTS tenant_id;
+
+ALTER TABLE public.documents
+  DROP COLUMN IF EXISTS tenant_id;
+
+DROP TABLE IF EXISTS public.tenants;
@@ -0,0 +1,40 @@
+-- Tenants with privacy settings and budgets, blind evaluation and LLM usage
+CREATE TABLE IF NOT EXISTS public.tenants (
+  id character varying PRIMARY KEY,
+  name character varying NOT NULL DEFAULT '',
+  redact_pii boolean NOT NULL DEFAULT true,
+  monthly_token_budget bigint NOT NULL DEFAULT 0,
+  monthly_cost_budget_usd numeric(12, 2) NOT NULL DEFAULT 0,
+  created_at timestamp without time zone DEFAULT now(),
+  updated_at timestamp without time zone DEFAULT now()
+);
+
+INSERT INTO public.tenants (id, name) VALUES ('default', 'Default')
+ON CONFLICT (id) DO NOTHING;
+
+ALTER TABLE public.documents
+  ADD COLUMN IF NOT EXISTS tenant_id character varying NOT NULL DEFAULT 'default';
+
+ALTER TABLE public.evaluation_jobs
+  ADD COLUMN IF NOT EXISTS tenant_id character varying NOT NULL DEFAULT 'default',
+  ADD COLUMN IF NOT EXISTS redaction_counts jsonb,
+  ADD COLUMN IF NOT EXISTS blind boolean NOT NULL DEFAULT false,
+  ADD COLUMN IF NOT EXISTS blind_counts jsonb;
+
+CREATE TABLE IF NOT EXISTS public.llm_usage (
+  id SERIAL PRIMARY KEY,
+  tenant_id character varying NOT NULL DEFAULT 'default',
+  job_id INTEGER REFERENCES public.evaluation_jobs(id) ON DELETE SET NULL,
+  operation character varying NOT NULL,
+  model character varying NOT NULL,
+  prompt_tokens bigint NOT NULL DEFAULT 0,
+  completion_tokens bigint NOT NULL DEFAULT 0,
+  total_tokens bigint NOT NULL DEFAULT 0,
+  cost_usd numeric(12, 6) NOT NULL DEFAULT 0,
+  created_at timestamp without time zone DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_tenant ON public.evaluation_jobs(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_documents_tenant ON public.documents(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_usage_job ON public.llm_usage(job_id);
+CREATE INDEX IF NOT EXISTS idx_usage_tenant_created ON public.llm_usage(tenant_id, created_at);
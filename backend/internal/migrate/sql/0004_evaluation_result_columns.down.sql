@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS public.idx_jobs_recommendation;
+DROP INDEX IF EXISTS public.idx_jobs_title_scores;
+
+ALTER TABLE public.evaluation_jobs
+  DROP COLUMN IF EXISTS recommendation,
+  DROP COLUMN IF EXISTS project_score,
+  DROP COLUMN IF EXISTS cv_match_rate;
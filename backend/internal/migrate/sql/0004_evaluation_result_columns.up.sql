@@ -0,0 +1,33 @@
+-- Typed copies of the result scores, so analytics can filter and sort
+-- without unpacking the jsonb
+ALTER TABLE public.evaluation_jobs
+  ADD COLUMN IF NOT EXISTS cv_match_rate double precision,
+  ADD COLUMN IF NOT EXISTS project_score double precision,
+  ADD COLUMN IF NOT EXISTS recommendation character varying
+    CONSTRAINT evaluation_jobs_recommendation_check
+    CHECK (recommendation IN ('strong_hire', 'hire', 'maybe', 'no_hire'));
+
+-- Backfill completed jobs from the stored result
+UPDATE public.evaluation_jobs
+SET cv_match_rate = (result->>'cv_match_rate')::double precision,
+    project_score = (result->>'project_score')::double precision
+WHERE result IS NOT NULL
+  AND jsonb_typeof(result->'cv_match_rate') = 'number'
+  AND jsonb_typeof(result->'project_score') = 'number';
+
+-- Same thresholds as domain.RecommendationFor
+UPDATE public.evaluation_jobs
+SET recommendation = CASE
+      WHEN (cv_match_rate + (project_score - 1) / 4) / 2 >= 0.8 THEN 'strong_hire'
+      WHEN (cv_match_rate + (project_score - 1) / 4) / 2 >= 0.6 THEN 'hire'
+      WHEN (cv_match_rate + (project_score - 1) / 4) / 2 >= 0.4 THEN 'maybe'
+      ELSE 'no_hire'
+    END
+WHERE cv_match_rate IS NOT NULL AND project_score IS NOT NULL;
+
+UPDATE public.evaluation_jobs
+SET result = result || jsonb_build_object('recommendation', recommendation)
+WHERE recommendation IS NOT NULL AND NOT result ? 'recommendation';
+
+CREATE INDEX IF NOT EXISTS idx_jobs_title_scores ON public.evaluation_jobs(job_title, cv_match_rate, project_score);
+CREATE INDEX IF NOT EXISTS idx_jobs_recommendation ON public.evaluation_jobs(recommendation);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS public.job_events;
+
+ALTER TABLE public.evaluation_jobs
+  DROP CONSTRAINT IF EXISTS evaluation_jobs_status_check,
+  DROP COLUMN IF EXISTS version,
+  ALTER COLUMN status DROP NOT NULL;
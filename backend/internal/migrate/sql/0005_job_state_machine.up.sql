@@ -0,0 +1,26 @@
+-- Guarded status transitions: a fixed set of statuses, a version for
+-- optimistic concurrency and an event per transition
+UPDATE public.evaluation_jobs SET status = 'queued' WHERE status IS NULL;
+
+ALTER TABLE public.evaluation_jobs
+  ALTER COLUMN status SET NOT NULL,
+  ADD COLUMN IF NOT EXISTS version integer NOT NULL DEFAULT 0;
+
+ALTER TABLE public.evaluation_jobs
+  DROP CONSTRAINT IF EXISTS evaluation_jobs_status_check;
+ALTER TABLE public.evaluation_jobs
+  ADD CONSTRAINT evaluation_jobs_status_check
+  CHECK (status IN ('queued', 'processing', 'completed', 'failed'));
+
+CREATE TABLE IF NOT EXISTS public.job_events (
+  id BIGSERIAL PRIMARY KEY,
+  job_id INTEGER NOT NULL REFERENCES public.evaluation_jobs(id) ON DELETE CASCADE,
+  from_status character varying NOT NULL DEFAULT '',
+  to_status character varying NOT NULL,
+  version integer NOT NULL,
+  worker_id character varying NOT NULL DEFAULT '',
+  message text,
+  created_at timestamp without time zone DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_events_job ON public.job_events(job_id, id);
@@ -0,0 +1,14 @@
+-- Transactional outbox: queue messages are written with the job and
+-- published to Redis by the relay
+CREATE TABLE IF NOT EXISTS public.outbox (
+  id BIGSERIAL PRIMARY KEY,
+  queue character varying NOT NULL,
+  payload text NOT NULL,
+  attempts integer NOT NULL DEFAULT 0,
+  last_error text,
+  created_at timestamp without time zone DEFAULT now(),
+  sent_at timestamp without time zone
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_unsent ON public.outbox(id) WHERE sent_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_sent_at ON public.outbox(sent_at) WHERE sent_at IS NOT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE public.evaluation_jobs
+  DROP COLUMN IF EXISTS priority;
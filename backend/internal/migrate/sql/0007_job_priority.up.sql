@@ -0,0 +1,5 @@
+-- Per-job priority selecting the high, normal or bulk queue
+ALTER TABLE public.evaluation_jobs
+  ADD COLUMN IF NOT EXISTS priority character varying NOT NULL DEFAULT 'normal'
+    CONSTRAINT evaluation_jobs_priority_check
+    CHECK (priority IN ('high', 'normal', 'bulk'));
@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS public.idx_jobs_scheduled_run_at;
+
+-- Scheduled jobs can't be represented without run_at, and nothing would
+-- queue them, so fail them visibly
+UPDATE public.evaluation_jobs
+SET status = 'failed', error_message = 'scheduled job cancelled by schema rollback'
+WHERE status = 'scheduled';
+
+ALTER TABLE public.evaluation_jobs
+  DROP CONSTRAINT IF EXISTS evaluation_jobs_status_check;
+ALTER TABLE public.evaluation_jobs
+  ADD CONSTRAINT evaluation_jobs_status_check
+  CHECK (status IN ('queued', 'processing', 'completed', 'failed'));
+
+ALTER TABLE public.evaluation_jobs
+  DROP COLUMN IF EXISTS run_at;
@@ -0,0 +1,13 @@
+-- Delayed evaluations: jobs wait in "scheduled" until run_at, then the
+-- scheduler queues them
+ALTER TABLE public.evaluation_jobs
+  ADD COLUMN IF NOT EXISTS run_at timestamp with time zone;
+
+ALTER TABLE public.evaluation_jobs
+  DROP CONSTRAINT IF EXISTS evaluation_jobs_status_check;
+ALTER TABLE public.evaluation_jobs
+  ADD CONSTRAINT evaluation_jobs_status_check
+  CHECK (status IN ('scheduled', 'queued', 'processing', 'completed', 'failed'));
+
+CREATE INDEX IF NOT EXISTS idx_jobs_scheduled_run_at ON public.evaluation_jobs(run_at)
+  WHERE status = 'scheduled';
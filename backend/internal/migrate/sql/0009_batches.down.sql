@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS public.idx_jobs_batch;
+
+ALTER TABLE public.evaluation_jobs
+  DROP COLUMN IF EXISTS batch_id;
+
+DROP TABLE IF EXISTS public.batches;
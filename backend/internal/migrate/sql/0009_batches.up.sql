@@ -0,0 +1,16 @@
+-- Batches group the jobs of many candidates evaluated for one opening
+CREATE TABLE IF NOT EXISTS public.batches (
+  id SERIAL PRIMARY KEY,
+  tenant_id character varying NOT NULL DEFAULT 'default',
+  job_title character varying NOT NULL,
+  total integer NOT NULL DEFAULT 0,
+  created_at timestamp with time zone DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_batches_tenant ON public.batches(tenant_id);
+
+ALTER TABLE public.evaluation_jobs
+  ADD COLUMN IF NOT EXISTS batch_id integer REFERENCES public.batches(id);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_batch ON public.evaluation_jobs(batch_id)
+  WHERE batch_id IS NOT NULL;
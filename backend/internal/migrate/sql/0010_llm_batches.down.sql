@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.llm_batch_items;
+DROP TABLE IF EXISTS public.llm_batches;
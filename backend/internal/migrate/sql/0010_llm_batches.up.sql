@@ -0,0 +1,27 @@
+-- Provider batch API submissions for bulk evaluations. Each job of a batch
+-- has an item carrying what its next stage needs.
+CREATE TABLE IF NOT EXISTS public.llm_batches (
+  id SERIAL PRIMARY KEY,
+  provider_batch_id character varying NOT NULL UNIQUE,
+  stage character varying NOT NULL
+    CONSTRAINT llm_batches_stage_check CHECK (stage IN ('evaluate', 'summarize')),
+  status character varying NOT NULL DEFAULT 'submitted'
+    CONSTRAINT llm_batches_status_check CHECK (status IN ('submitted', 'completed', 'failed')),
+  request_count integer NOT NULL DEFAULT 0,
+  error_message text,
+  next_poll_at timestamp with time zone NOT NULL DEFAULT now(),
+  created_at timestamp with time zone DEFAULT now(),
+  completed_at timestamp with time zone
+);
+
+CREATE INDEX IF NOT EXISTS idx_llm_batches_submitted ON public.llm_batches(next_poll_at)
+  WHERE status = 'submitted';
+
+CREATE TABLE IF NOT EXISTS public.llm_batch_items (
+  batch_id integer NOT NULL REFERENCES public.llm_batches(id) ON DELETE CASCADE,
+  job_id integer NOT NULL REFERENCES public.evaluation_jobs(id) ON DELETE CASCADE,
+  state jsonb,
+  PRIMARY KEY (batch_id, job_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_llm_batch_items_job ON public.llm_batch_items(job_id);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS public.idx_jobs_opening_completed;
@@ -0,0 +1,4 @@
+-- Rankings list an opening's completed evaluations; titles match case-insensitively
+CREATE INDEX IF NOT EXISTS idx_jobs_opening_completed
+  ON public.evaluation_jobs(tenant_id, lower(job_title))
+  WHERE status = 'completed';
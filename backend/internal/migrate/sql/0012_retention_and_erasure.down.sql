@@ -0,0 +1,24 @@
+DROP TABLE IF EXISTS public.erasures;
+
+DROP INDEX IF EXISTS public.idx_documents_tenant_uploaded;
+DROP INDEX IF EXISTS public.idx_jobs_report;
+DROP INDEX IF EXISTS public.idx_jobs_cv;
+
+-- Jobs of erased candidates have no documents left to point at; NOT NULL
+-- can't be restored while they exist
+DELETE FROM public.evaluation_jobs WHERE cv_id IS NULL OR report_id IS NULL;
+
+ALTER TABLE public.evaluation_jobs
+  DROP CONSTRAINT IF EXISTS evaluation_jobs_cv_id_fkey,
+  DROP CONSTRAINT IF EXISTS evaluation_jobs_report_id_fkey;
+ALTER TABLE public.evaluation_jobs
+  ADD CONSTRAINT evaluation_jobs_cv_id_fkey FOREIGN KEY (cv_id) REFERENCES public.documents(id),
+  ADD CONSTRAINT evaluation_jobs_report_id_fkey FOREIGN KEY (report_id) REFERENCES public.documents(id);
+
+ALTER TABLE public.evaluation_jobs
+  DROP COLUMN IF EXISTS erased_at,
+  ALTER COLUMN cv_id SET NOT NULL,
+  ALTER COLUMN report_id SET NOT NULL;
+
+ALTER TABLE public.tenants
+  DROP COLUMN IF EXISTS document_retention_days;
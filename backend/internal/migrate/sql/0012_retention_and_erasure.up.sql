@@ -0,0 +1,40 @@
+-- Document retention per tenant; zero keeps documents forever
+ALTER TABLE public.tenants
+  ADD COLUMN IF NOT EXISTS document_retention_days integer NOT NULL DEFAULT 0;
+
+-- Erasing a candidate deletes their documents but keeps the scores of their
+-- evaluations, so jobs outlive the documents they point at
+ALTER TABLE public.evaluation_jobs
+  ALTER COLUMN cv_id DROP NOT NULL,
+  ALTER COLUMN report_id DROP NOT NULL,
+  ADD COLUMN IF NOT EXISTS erased_at timestamp with time zone;
+
+ALTER TABLE public.evaluation_jobs
+  DROP CONSTRAINT IF EXISTS evaluation_jobs_cv_id_fkey,
+  DROP CONSTRAINT IF EXISTS evaluation_jobs_report_id_fkey;
+ALTER TABLE public.evaluation_jobs
+  ADD CONSTRAINT evaluation_jobs_cv_id_fkey FOREIGN KEY (cv_id)
+    REFERENCES public.documents(id) ON DELETE SET NULL,
+  ADD CONSTRAINT evaluation_jobs_report_id_fkey FOREIGN KEY (report_id)
+    REFERENCES public.documents(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_cv ON public.evaluation_jobs(cv_id);
+CREATE INDEX IF NOT EXISTS idx_jobs_report ON public.evaluation_jobs(report_id);
+CREATE INDEX IF NOT EXISTS idx_documents_tenant_uploaded ON public.documents(tenant_id, uploaded_at);
+
+-- Audit trail of erasures; it records what was deleted, never the data itself
+CREATE TABLE IF NOT EXISTS public.erasures (
+  id SERIAL PRIMARY KEY,
+  tenant_id character varying NOT NULL,
+  reason character varying NOT NULL CHECK (reason IN ('request', 'retention')),
+  candidate_id integer,
+  request_id character varying NOT NULL DEFAULT '',
+  document_ids jsonb NOT NULL DEFAULT '[]',
+  documents_deleted integer NOT NULL DEFAULT 0,
+  files_deleted integer NOT NULL DEFAULT 0,
+  vectors_deleted integer NOT NULL DEFAULT 0,
+  jobs_anonymised integer NOT NULL DEFAULT 0,
+  created_at timestamp with time zone DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_erasures_tenant_created ON public.erasures(tenant_id, created_at);
@@ -0,0 +1,13 @@
+-- API keys authenticate requests and decide their tenant; only a SHA-256
+-- hash of each key is stored
+CREATE TABLE IF NOT EXISTS public.api_keys (
+  id SERIAL PRIMARY KEY,
+  tenant_id character varying NOT NULL REFERENCES public.tenants(id) ON DELETE CASCADE,
+  name character varying NOT NULL DEFAULT '',
+  prefix character varying NOT NULL,
+  key_hash character varying NOT NULL UNIQUE,
+  created_at timestamp with time zone DEFAULT now(),
+  revoked_at timestamp with time zone
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_tenant ON public.api_keys(tenant_id);
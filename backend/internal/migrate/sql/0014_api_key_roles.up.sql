@@ -0,0 +1,4 @@
+-- Admin keys may change tenant settings and budgets; member keys may not
+ALTER TABLE public.api_keys
+  ADD COLUMN IF NOT EXISTS role character varying NOT NULL DEFAULT 'member'
+    CONSTRAINT api_keys_role_check CHECK (role IN ('member', 'admin'));
@@ -0,0 +1,10 @@
+ALTER TABLE public.erasures
+  DROP COLUMN IF EXISTS actor,
+  DROP COLUMN IF EXISTS api_key_id;
+
+-- DPO keys become member keys, which can no longer erase candidates
+UPDATE public.api_keys SET role = 'member' WHERE role = 'dpo';
+ALTER TABLE public.api_keys
+  DROP CONSTRAINT IF EXISTS api_keys_role_check;
+ALTER TABLE public.api_keys
+  ADD CONSTRAINT api_keys_role_check CHECK (role IN ('member', 'admin'));
@@ -0,0 +1,11 @@
+-- DPO keys may erase candidates and read the erasure audit trail
+ALTER TABLE public.api_keys
+  DROP CONSTRAINT IF EXISTS api_keys_role_check;
+ALTER TABLE public.api_keys
+  ADD CONSTRAINT api_keys_role_check CHECK (role IN ('member', 'admin', 'dpo'));
+
+-- Who asked for an erasure. api_key_id has no foreign key so the audit record
+-- outlives the key; actor names the key, or the retention purger.
+ALTER TABLE public.erasures
+  ADD COLUMN IF NOT EXISTS api_key_id integer,
+  ADD COLUMN IF NOT EXISTS actor character varying NOT NULL DEFAULT '';
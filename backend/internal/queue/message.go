@@ -0,0 +1,85 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adyutaa/parsea/internal/logging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// EvaluationQueue is the Redis list evaluation jobs are pushed to
+const EvaluationQueue = "evaluation_queue"
+
+// Message is the payload pushed onto the evaluation queue
+type Message struct {
+	JobID        uint              `json:"job_id"`
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+	RequestID    string            `json:"request_id,omitempty"`
+	EnqueuedAt   time.Time         `json:"enqueued_at"`
+}
+
+// NewMessage builds a queue message for a job, carrying the trace context and
+// request ID from ctx
+func NewMessage(ctx context.Context, jobID uint) *Message {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	return &Message{
+		JobID:        jobID,
+		TraceContext: carrier,
+		RequestID:    logging.RequestID(ctx),
+		EnqueuedAt:   time.Now(),
+	}
+}
+
+// Encode serialises the message for LPush
+func (m *Message) Encode() (string, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode queue message: %w", err)
+	}
+	return string(data), nil
+}
+
+// Decode parses a queue payload. Bare job IDs pushed before messages were
+// structured are still accepted so in-flight jobs survive a deploy.
+func Decode(payload string) (*Message, error) {
+	payload = strings.TrimSpace(payload)
+	if !strings.HasPrefix(payload, "{") {
+		id, err := strconv.ParseUint(payload, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid job ID format: %w", err)
+		}
+		return &Message{JobID: uint(id)}, nil
+	}
+
+	var m Message
+	if err := json.Unmarshal([]byte(payload), &m); err != nil {
+		return nil, fmt.Errorf("failed to decode queue message: %w", err)
+	}
+	if m.JobID == 0 {
+		return nil, fmt.Errorf("queue message has no job ID")
+	}
+	return &m, nil
+}
+
+// Context returns ctx with the producer's trace context and request ID
+// attached, so the worker's spans and log lines join the request that
+// enqueued the job
+func (m *Message) Context(ctx context.Context) context.Context {
+	if m.RequestID != "" {
+		ctx = logging.WithRequestID(ctx, m.RequestID)
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(m.TraceContext))
+}
+
+// JobIDString returns the job ID in the string form the repositories take
+func (m *Message) JobIDString() string {
+	return strconv.FormatUint(uint64(m.JobID), 10)
+}
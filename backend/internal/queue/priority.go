@@ -0,0 +1,75 @@
+package queue
+
+import (
+	"math/rand/v2"
+
+	"github.com/adyutaa/parsea/internal/domain"
+)
+
+// Per-priority queues. Normal keeps the original list name so messages queued
+// before priorities existed are still consumed.
+const (
+	HighQueue = EvaluationQueue + ":high"
+	BulkQueue = EvaluationQueue + ":bulk"
+)
+
+// NameFor returns the Redis list a job of the given priority is pushed to
+func NameFor(p domain.Priority) string {
+	switch p {
+	case domain.PriorityHigh:
+		return HighQueue
+	case domain.PriorityBulk:
+		return BulkQueue
+	default:
+		return EvaluationQueue
+	}
+}
+
+// Weights sets how often each queue is checked first. With 6/3/1, a worker
+// prefers the high queue on 60% of pops but still takes from the bulk queue
+// first on 10% of them, so a large bulk backlog keeps moving without
+// delaying urgent jobs for long.
+type Weights struct {
+	High   int
+	Normal int
+	Bulk   int
+}
+
+// PopOrder returns the queues for one BRPOP. The first queue is drawn at
+// random by weight and the rest follow in priority order; BRPOP takes from
+// the first non-empty list, so empty queues never cost a turn.
+func (w Weights) PopOrder() []string {
+	weights := []struct {
+		queue  string
+		weight int
+	}{
+		{HighQueue, w.High},
+		{EvaluationQueue, w.Normal},
+		{BulkQueue, w.Bulk},
+	}
+
+	total := 0
+	for _, q := range weights {
+		total += max(q.weight, 0)
+	}
+
+	first := 0
+	if total > 0 {
+		n := rand.IntN(total)
+		for i, q := range weights {
+			if n < max(q.weight, 0) {
+				first = i
+				break
+			}
+			n -= max(q.weight, 0)
+		}
+	}
+
+	order := []string{weights[first].queue}
+	for i, q := range weights {
+		if i != first {
+			order = append(order, q.queue)
+		}
+	}
+	return order
+}
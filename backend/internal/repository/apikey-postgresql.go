@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/adyutaa/parsea/internal/domain"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// Create stores a new API key
+func (r *APIKeyRepository) Create(key *domain.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+// GetActiveByHash retrieves the unrevoked key with the given hash
+func (r *APIKeyRepository) GetActiveByHash(hash string) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := r.db.Where("key_hash = ? AND revoked_at IS NULL", hash).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// List returns a tenant's keys, revoked ones included, oldest first
+func (r *APIKeyRepository) List(tenantID string) ([]domain.APIKey, error) {
+	var keys []domain.APIKey
+	err := r.db.Where("tenant_id = ?", tenantID).Order("id ASC").Find(&keys).Error
+	return keys, err
+}
+
+// Revoke stops a key from authenticating; revoking it again is a no-op
+func (r *APIKeyRepository) Revoke(id uint) error {
+	result := r.db.Model(&domain.APIKey{}).Where("id = ?", id).
+		Update("revoked_at", gorm.Expr("COALESCE(revoked_at, ?)", time.Now()))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
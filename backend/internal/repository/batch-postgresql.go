@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/adyutaa/parsea/internal/domain"
+	"gorm.io/gorm"
+)
+
+type BatchRepository struct {
+	db *gorm.DB
+}
+
+func NewBatchRepository(db *gorm.DB) *BatchRepository {
+	return &BatchRepository{db: db}
+}
+
+// CreateWithJobs saves a batch and all of its jobs, each with its outbox
+// message, in one transaction, so a batch is never left half created
+func (r *BatchRepository) CreateWithJobs(batch *domain.Batch, jobs []*domain.EvaluationJob, queueFor func(job *domain.EvaluationJob) string, encode func(jobID uint) (string, error)) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		batch.Total = len(jobs)
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+
+		evalRepo := NewEvaluationRepository(tx)
+		for _, job := range jobs {
+			job.BatchID = &batch.ID
+			if err := evalRepo.CreateAndEnqueue(job, queueFor(job), encode); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetByID retrieves a batch by ID
+func (r *BatchRepository) GetByID(id uint) (*domain.Batch, error) {
+	var batch domain.Batch
+	if err := r.db.Where("id = ?", id).First(&batch).Error; err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// Progress counts a batch's jobs by status
+func (r *BatchRepository) Progress(batch *domain.Batch) (*domain.BatchProgress, error) {
+	var rows []struct {
+		Status    domain.JobStatus
+		Count     int
+		UpdatedAt time.Time
+	}
+	if err := r.db.Model(&domain.EvaluationJob{}).
+		Select("status, COUNT(*) AS count, MAX(updated_at) AS updated_at").
+		Where("batch_id = ?", batch.ID).
+		Group("status").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	progress := &domain.BatchProgress{Total: batch.Total, ByStatus: map[domain.JobStatus]int{}}
+	var lastUpdate time.Time
+	for _, row := range rows {
+		progress.ByStatus[row.Status] = row.Count
+		if row.Status.Terminal() {
+			progress.Finished += row.Count
+		}
+		if row.UpdatedAt.After(lastUpdate) {
+			lastUpdate = row.UpdatedAt
+		}
+	}
+	if progress.Total > 0 {
+		progress.Percent = float64(progress.Finished) * 100 / float64(progress.Total)
+	}
+	if progress.Done() {
+		progress.FinishedAt = &lastUpdate
+	}
+	return progress, nil
+}
+
+// CompletedJobs returns a batch's completed jobs with their CV filenames;
+// erased candidates are listed without one
+func (r *BatchRepository) CompletedJobs(batchID uint) ([]domain.LeaderboardEntry, error) {
+	var entries []domain.LeaderboardEntry
+	err := r.db.Table("evaluation_jobs AS j").
+		Select(`j.id AS job_id, COALESCE(j.cv_id, 0) AS cv_id, COALESCE(j.report_id, 0) AS report_id,
+			COALESCE(d.filename, '') AS candidate,
+			j.cv_match_rate, j.project_score, j.recommendation`).
+		Joins("LEFT JOIN documents d ON d.id = j.cv_id").
+		Where("j.batch_id = ? AND j.status = ? AND j.cv_match_rate IS NOT NULL AND j.project_score IS NOT NULL",
+			batchID, domain.JobCompleted).
+		Scan(&entries).Error
+	return entries, err
+}
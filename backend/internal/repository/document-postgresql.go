@@ -29,6 +29,34 @@ func (r *DocumentRepository) GetByID(id uint) (*domain.Document, error) {
 	return &doc, nil
 }
 
+// GetByIDs retrieves the documents with the given IDs; unknown IDs are skipped
+func (r *DocumentRepository) GetByIDs(ids []uint) ([]domain.Document, error) {
+	var docs []domain.Document
+	err := r.db.Where("id IN ?", ids).Find(&docs).Error
+	return docs, err
+}
+
+// Delete removes documents by ID
+func (r *DocumentRepository) Delete(ids []uint) error {
+	return r.db.Where("id IN ?", ids).Delete(&domain.Document{}).Error
+}
+
+// UpdateExtraction records which strategy produced the document text and,
+// for OCR, the recognition confidence
+func (r *DocumentRepository) UpdateExtraction(id uint, strategy string, confidence *float64) error {
+	return r.db.Model(&domain.Document{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"extraction_strategy":   strategy,
+			"extraction_confidence": confidence,
+		}).Error
+}
+
+// UpdateProfile stores the structured candidate profile extracted from a CV
+func (r *DocumentRepository) UpdateProfile(id uint, profile *domain.CandidateProfile) error {
+	return r.db.Model(&domain.Document{}).Where("id = ?", id).
+		Update("profile", profile).Error
+}
+
 // GetByType retrieves all documents of a specific type
 func (r *DocumentRepository) GetByType(docType string) ([]domain.Document, error) {
 	var docs []domain.Document
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/adyutaa/parsea/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrDocumentsInUse means a document to purge is used by a job that hasn't finished
+var ErrDocumentsInUse = errors.New("documents are used by unfinished jobs")
+
+// activeJobStatuses are the statuses of jobs that still read their documents
+var activeJobStatuses = []domain.JobStatus{domain.JobScheduled, domain.JobQueued, domain.JobProcessing}
+
+type ErasureRepository struct {
+	db *gorm.DB
+}
+
+func NewErasureRepository(db *gorm.DB) *ErasureRepository {
+	return &ErasureRepository{db: db}
+}
+
+// CandidateDocuments returns everything stored about the candidate whose CV
+// is cvID: that CV, the tenant's other CVs whose profile has the same email
+// address, and the project reports evaluated together with any of them
+func (r *ErasureRepository) CandidateDocuments(tenantID string, cvID uint) ([]domain.Document, error) {
+	var cv domain.Document
+	if err := r.db.Where("id = ? AND tenant_id = ? AND doc_type = ?", cvID, tenantID, "cv").First(&cv).Error; err != nil {
+		return nil, err
+	}
+
+	cvIDs := []uint{cv.ID}
+	if cv.Profile != nil && strings.TrimSpace(cv.Profile.Contact.Email) != "" {
+		if err := r.db.Model(&domain.Document{}).
+			Where("tenant_id = ? AND doc_type = ? AND id <> ?", tenantID, "cv", cv.ID).
+			Where("lower(profile->'contact'->>'email') = lower(?)", strings.TrimSpace(cv.Profile.Contact.Email)).
+			Pluck("id", &cvIDs).Error; err != nil {
+			return nil, err
+		}
+		cvIDs = append(cvIDs, cv.ID)
+	}
+
+	var docs []domain.Document
+	err := r.db.Where("tenant_id = ?", tenantID).
+		Where("id IN ? OR id IN (?)", cvIDs,
+			r.db.Model(&domain.EvaluationJob{}).Select("report_id").Where("cv_id IN ? AND report_id IS NOT NULL", cvIDs)).
+		Order("id ASC").
+		Find(&docs).Error
+	return docs, err
+}
+
+// Expired returns up to limit documents uploaded longer ago than their
+// tenant's retention period, oldest first. Documents still used by an
+// unfinished job are left for a later run.
+func (r *ErasureRepository) Expired(now time.Time, limit int) ([]domain.Document, error) {
+	var docs []domain.Document
+	err := r.db.Table("documents AS d").
+		Select("d.*").
+		Joins("JOIN tenants t ON t.id = d.tenant_id").
+		Where("t.document_retention_days > 0").
+		Where("d.uploaded_at < ? - make_interval(days => t.document_retention_days)", now).
+		Where(`NOT EXISTS (SELECT 1 FROM evaluation_jobs j
+			WHERE (j.cv_id = d.id OR j.report_id = d.id) AND j.status IN ?)`, activeJobStatuses).
+		Order("d.uploaded_at ASC").
+		Limit(limit).
+		Find(&docs).Error
+	return docs, err
+}
+
+// Erase deletes documents and anonymises the jobs that used them in one
+// transaction, then writes the audit record. Jobs keep their scores,
+// criteria and recommendation; their feedback, summary, error message and
+// event messages are cleared, their LLM batch items deleted, and their
+// document IDs become NULL.
+//
+// Unfinished jobs are failed when failActive is set; otherwise they make
+// Erase return ErrDocumentsInUse. purge runs last, before the commit, to
+// remove what lives outside the database; it fills in the erasure's file
+// and vector counts, and an error from it rolls everything back.
+func (r *ErasureRepository) Erase(erasure *domain.Erasure, docs []domain.Document, failActive bool, purge func(erasure *domain.Erasure) error) error {
+	docIDs := make(domain.IDList, len(docs))
+	for i := range docs {
+		docIDs[i] = docs[i].ID
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var jobs []domain.EvaluationJob
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("cv_id IN ? OR report_id IN ?", []uint(docIDs), []uint(docIDs)).
+			Order("id ASC").
+			Find(&jobs).Error; err != nil {
+			return err
+		}
+
+		jobIDs := make([]uint, len(jobs))
+		for i := range jobs {
+			jobIDs[i] = jobs[i].ID
+			if !jobs[i].Status.Terminal() && !failActive {
+				return ErrDocumentsInUse
+			}
+		}
+
+		if len(jobIDs) > 0 {
+			// UpdateColumns leaves updated_at alone, which rankings report as the completion time
+			if err := tx.Model(&domain.EvaluationJob{}).Where("id IN ?", jobIDs).
+				UpdateColumns(map[string]interface{}{
+					"result":        gorm.Expr("result - 'cv_feedback' - 'project_feedback' - 'overall_summary'"),
+					"error_message": "",
+					"erased_at":     time.Now(),
+				}).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&domain.JobEvent{}).Where("job_id IN ?", jobIDs).
+				Update("message", "").Error; err != nil {
+				return err
+			}
+			// Batch items carry the PII originals of redactions and partial
+			// feedback; a pending batch skips jobs that lost their item
+			if err := tx.Where("job_id IN ?", jobIDs).Delete(&domain.LLMBatchItem{}).Error; err != nil {
+				return err
+			}
+
+			txRepo := NewEvaluationRepository(tx)
+			for i := range jobs {
+				if !jobs[i].Status.Terminal() {
+					if err := txRepo.MarkFailed(&jobs[i], "candidate data erased", ""); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		// The foreign keys set the jobs' cv_id and report_id to NULL
+		if len(docIDs) > 0 {
+			if err := tx.Where("id IN ?", []uint(docIDs)).Delete(&domain.Document{}).Error; err != nil {
+				return err
+			}
+		}
+
+		erasure.DocumentIDs = docIDs
+		erasure.DocumentsDeleted = len(docIDs)
+		erasure.JobsAnonymised = len(jobIDs)
+		if purge != nil {
+			if err := purge(erasure); err != nil {
+				return err
+			}
+		}
+		return tx.Create(erasure).Error
+	})
+}
+
+// List returns a tenant's erasure records, newest first
+func (r *ErasureRepository) List(tenantID string, limit int) ([]domain.Erasure, error) {
+	var erasures []domain.Erasure
+	err := r.db.Where("tenant_id = ?", tenantID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&erasures).Error
+	return erasures, err
+}
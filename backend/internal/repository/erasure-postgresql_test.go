@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adyutaa/parsea/internal/domain"
+	"github.com/adyutaa/parsea/internal/testdb"
+)
+
+func TestEraseDeletesLLMBatchItems(t *testing.T) {
+	tx := testdb.Tx(t)
+
+	tenant := domain.DefaultTenant("erasure-test")
+	if err := NewTenantRepository(tx).Create(tenant); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+	docRepo := NewDocumentRepository(tx)
+	cv := &domain.Document{TenantID: tenant.ID, Filename: "cv.txt", FilePath: "/nonexistent/cv.txt", DocType: "cv", MimeType: "text/plain"}
+	report := &domain.Document{TenantID: tenant.ID, Filename: "report.txt", FilePath: "/nonexistent/report.txt", DocType: "project_report", MimeType: "text/plain"}
+	for _, doc := range []*domain.Document{cv, report} {
+		if err := docRepo.Create(doc); err != nil {
+			t.Fatalf("create document: %v", err)
+		}
+	}
+
+	evalRepo := NewEvaluationRepository(tx)
+	job := newQueuedJob(tenant.ID, cv.ID, report.ID)
+	if err := evalRepo.Create(job); err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	if err := evalRepo.MarkProcessing(job, "test"); err != nil {
+		t.Fatalf("MarkProcessing: %v", err)
+	}
+
+	// The job waits on a provider batch, carrying the originals of its redactions
+	batch := &domain.LLMBatch{ProviderBatchID: "batch_erasure_test", Stage: domain.LLMBatchEvaluate, Status: domain.LLMBatchSubmitted, RequestCount: 2, NextPollAt: time.Now()}
+	state := &domain.LLMBatchState{Redactions: map[string]string{"[NAME_1]": "Jane Doe", "[EMAIL_1]": "jane@example.com"}}
+	if err := NewLLMBatchRepository(tx).Create(batch, []domain.LLMBatchItem{{JobID: job.ID, State: state}}); err != nil {
+		t.Fatalf("create llm batch: %v", err)
+	}
+
+	docs, err := NewErasureRepository(tx).CandidateDocuments(tenant.ID, cv.ID)
+	if err != nil {
+		t.Fatalf("CandidateDocuments: %v", err)
+	}
+	erasure := &domain.Erasure{TenantID: tenant.ID, Reason: domain.ErasureRequest, CandidateID: &cv.ID}
+	if err := NewErasureRepository(tx).Erase(erasure, docs, true, nil); err != nil {
+		t.Fatalf("Erase: %v", err)
+	}
+
+	var items int64
+	if err := tx.Model(&domain.LLMBatchItem{}).Where("job_id = ?", job.ID).Count(&items).Error; err != nil {
+		t.Fatalf("count llm batch items: %v", err)
+	}
+	if items != 0 {
+		t.Errorf("%d llm batch items left for the erased job, want 0", items)
+	}
+
+	stored, err := evalRepo.GetByID(job.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if stored.Status != domain.JobFailed || stored.ErasedAt == nil {
+		t.Errorf("erased job = status %q, erased_at %v; want failed and erased", stored.Status, stored.ErasedAt)
+	}
+}
@@ -1,12 +1,12 @@
 package repository
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
 	"time"
 
 	"github.com/adyutaa/parsea/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type EvaluationRepository struct {
@@ -17,9 +17,74 @@ func NewEvaluationRepository(db *gorm.DB) *EvaluationRepository {
 	return &EvaluationRepository{db: db}
 }
 
-// Create saves a new evaluation job
+// Create saves a new evaluation job and records its initial status
 func (r *EvaluationRepository) Create(job *domain.EvaluationJob) error {
-	return r.db.Create(job).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(job).Error; err != nil {
+			return err
+		}
+		return tx.Create(&domain.JobEvent{
+			JobID:    job.ID,
+			ToStatus: job.Status,
+			Version:  job.Version,
+		}).Error
+	})
+}
+
+// CreateAndEnqueue saves a new job together with its initial event and the
+// outbox message that queues it, so the job is never stored without being
+// queued or queued without being stored. encode builds the message payload
+// once the job ID is known.
+func (r *EvaluationRepository) CreateAndEnqueue(job *domain.EvaluationJob, queueName string, encode func(jobID uint) (string, error)) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := NewEvaluationRepository(tx).Create(job); err != nil {
+			return err
+		}
+		payload, err := encode(job.ID)
+		if err != nil {
+			return err
+		}
+		return tx.Create(&domain.OutboxMessage{Queue: queueName, Payload: payload}).Error
+	})
+}
+
+// EnqueueDue moves up to limit scheduled jobs whose run_at has passed to
+// queued, writing each one's outbox message in the same transaction. SKIP
+// LOCKED lets several schedulers run without claiming the same job. It
+// returns how many jobs were queued.
+func (r *EvaluationRepository) EnqueueDue(now time.Time, limit int, workerID string, queueFor func(job *domain.EvaluationJob) string, encode func(jobID uint) (string, error)) (int, error) {
+	queued := 0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var jobs []domain.EvaluationJob
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND run_at <= ?", domain.JobScheduled, now).
+			Order("run_at ASC").
+			Limit(limit).
+			Find(&jobs).Error; err != nil {
+			return err
+		}
+
+		txRepo := NewEvaluationRepository(tx)
+		for i := range jobs {
+			job := &jobs[i]
+			if err := txRepo.Transition(job, domain.JobQueued, workerID, nil, "run_at reached"); err != nil {
+				return err
+			}
+			payload, err := encode(job.ID)
+			if err != nil {
+				return err
+			}
+			if err := tx.Create(&domain.OutboxMessage{Queue: queueFor(job), Payload: payload}).Error; err != nil {
+				return err
+			}
+			queued++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return queued, nil
 }
 
 // GetByID retrieves an evaluation job by ID
@@ -37,54 +102,243 @@ func (r *EvaluationRepository) GetDB() *gorm.DB {
 	return r.db
 }
 
-// UpdateStatus updates the status of a job
-func (r *EvaluationRepository) UpdateStatus(id string, status string) error {
-	return r.db.Model(&domain.EvaluationJob{}).Where("id = ?", id).
-		Updates(map[string]interface{}{
-			"status":     status,
-			"updated_at": time.Now(),
-		}).Error
-}
+// Transition moves a job to a new status. The update only applies while the
+// row still has the status and version the caller read, so a late or
+// duplicate worker can't overwrite a job that has moved on; fields are
+// written in the same statement. The transition is recorded in job_events
+// and job is updated in place on success.
+func (r *EvaluationRepository) Transition(job *domain.EvaluationJob, to domain.JobStatus, workerID string, fields map[string]interface{}, message string) error {
+	from := job.Status
+	if !from.CanTransitionTo(to) {
+		return &domain.TransitionError{JobID: job.ID, From: from, To: to, Err: domain.ErrInvalidTransition}
+	}
 
-// UpdateResult updates the job with final results
-func (r *EvaluationRepository) UpdateResult(id string, result *domain.EvaluationResult) error {
-	resultMap := map[string]interface{}{
-		"cv_match_rate":    result.CVMatchRate,
-		"cv_feedback":      result.CVFeedback,
-		"project_score":    result.ProjectScore,
-		"project_feedback": result.ProjectFeedback,
-		"overall_summary":  result.OverallSummary,
+	updates := map[string]interface{}{
+		"status":     to,
+		"version":    gorm.Expr("version + 1"),
+		"updated_at": time.Now(),
 	}
-	
-	resultJSON, err := json.Marshal(resultMap)
-	if err != nil {
-		return fmt.Errorf("failed to marshal result to JSON: %w", err)
+	for k, v := range fields {
+		updates[k] = v
 	}
-	
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&domain.EvaluationJob{}).
+			Where("id = ? AND status = ? AND version = ?", job.ID, from, job.Version).
+			Updates(updates)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return &domain.TransitionError{JobID: job.ID, From: from, To: to, Err: domain.ErrJobConflict}
+		}
+
+		if err := tx.Create(&domain.JobEvent{
+			JobID:      job.ID,
+			FromStatus: from,
+			ToStatus:   to,
+			Version:    job.Version + 1,
+			WorkerID:   workerID,
+			Message:    message,
+		}).Error; err != nil {
+			return err
+		}
+
+		job.Status = to
+		job.Version++
+		return nil
+	})
+}
+
+// MarkProcessing claims a queued job for a worker
+func (r *EvaluationRepository) MarkProcessing(job *domain.EvaluationJob, workerID string) error {
+	return r.Transition(job, domain.JobProcessing, workerID, nil, "")
+}
+
+// MarkCompleted stores the final result as jsonb, copies its scores into the
+// typed columns and completes the job
+func (r *EvaluationRepository) MarkCompleted(job *domain.EvaluationJob, result *domain.EvaluationResult, workerID string) error {
+	return r.Transition(job, domain.JobCompleted, workerID, map[string]interface{}{
+		"result":         result,
+		"cv_match_rate":  result.CVMatchRate,
+		"project_score":  result.ProjectScore,
+		"recommendation": result.Recommendation,
+	}, "")
+}
+
+// MarkFailed fails the job with an error message
+func (r *EvaluationRepository) MarkFailed(job *domain.EvaluationJob, errMsg string, workerID string) error {
+	return r.Transition(job, domain.JobFailed, workerID, map[string]interface{}{
+		"error_message": errMsg,
+	}, errMsg)
+}
+
+// ListStale returns up to limit queued or processing jobs not updated since
+// before, oldest first, with the worker that claimed each one. Jobs waiting
+// on a submitted provider batch are left out; the batch runner owns them.
+func (r *EvaluationRepository) ListStale(before time.Time, limit int) ([]domain.StaleJob, error) {
+	var jobs []domain.StaleJob
+	err := r.db.Table("evaluation_jobs AS j").
+		Select(`j.*,
+			COALESCE((SELECT e.worker_id FROM job_events e
+				WHERE e.job_id = j.id AND e.to_status = ?
+				ORDER BY e.id DESC LIMIT 1), '') AS claimed_by,
+			(SELECT COUNT(*) FROM job_events e
+				WHERE e.job_id = j.id AND e.from_status = ? AND e.to_status = ?) AS requeues`,
+			domain.JobProcessing, domain.JobProcessing, domain.JobQueued).
+		Where("j.status IN ? AND j.updated_at < ?", []domain.JobStatus{domain.JobQueued, domain.JobProcessing}, before).
+		Where(`NOT EXISTS (SELECT 1 FROM llm_batch_items i
+			JOIN llm_batches b ON b.id = i.batch_id
+			WHERE i.job_id = j.id AND b.status = ?)`, domain.LLMBatchSubmitted).
+		Order("j.updated_at ASC").
+		Limit(limit).
+		Scan(&jobs).Error
+	return jobs, err
+}
+
+// Requeue writes a new outbox message for a job whose message was lost. A
+// processing job moves back to queued with reason as the event message; a
+// queued job keeps its status but has updated_at refreshed, so it isn't
+// requeued again before the new message could arrive. Either update is
+// guarded like Transition, so two reapers can't both requeue the same job.
+func (r *EvaluationRepository) Requeue(job *domain.EvaluationJob, workerID, reason, queueName string, encode func(jobID uint) (string, error)) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if job.Status == domain.JobQueued {
+			now := time.Now()
+			res := tx.Model(&domain.EvaluationJob{}).
+				Where("id = ? AND status = ? AND version = ? AND updated_at = ?", job.ID, job.Status, job.Version, job.UpdatedAt).
+				Update("updated_at", now)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return &domain.TransitionError{JobID: job.ID, From: job.Status, To: job.Status, Err: domain.ErrJobConflict}
+			}
+			job.UpdatedAt = now
+		} else if err := NewEvaluationRepository(tx).Transition(job, domain.JobQueued, workerID, nil, reason); err != nil {
+			return err
+		}
+
+		payload, err := encode(job.ID)
+		if err != nil {
+			return err
+		}
+		return tx.Create(&domain.OutboxMessage{Queue: queueName, Payload: payload}).Error
+	})
+}
+
+// ListEvents returns a job's status transitions, oldest first
+func (r *EvaluationRepository) ListEvents(jobID uint) ([]domain.JobEvent, error) {
+	var events []domain.JobEvent
+	err := r.db.Where("job_id = ?", jobID).Order("id ASC").Find(&events).Error
+	return events, err
+}
+
+// UpdateRedaction records how many PII values were redacted for a job
+func (r *EvaluationRepository) UpdateRedaction(id string, counts domain.JSON) error {
 	return r.db.Model(&domain.EvaluationJob{}).Where("id = ?", id).
-		Updates(map[string]interface{}{
-			"result":     string(resultJSON),
-			"status":     "completed",
-			"updated_at": time.Now(),
-		}).Error
+		Update("redaction_counts", counts).Error
 }
 
-// UpdateError updates the job with error message
-func (r *EvaluationRepository) UpdateError(id string, errMsg string) error {
+// UpdateBlindCounts records which protected attributes were removed in a blind evaluation
+func (r *EvaluationRepository) UpdateBlindCounts(id string, counts domain.JSON) error {
 	return r.db.Model(&domain.EvaluationJob{}).Where("id = ?", id).
-		Updates(map[string]interface{}{
-			"status":        "failed",
-			"error_message": errMsg,
-			"updated_at":    time.Now(),
-		}).Error
+		Update("blind_counts", counts).Error
 }
 
 // GetPendingJobs retrieves all jobs with status "queued"
 func (r *EvaluationRepository) GetPendingJobs(limit int) ([]domain.EvaluationJob, error) {
 	var jobs []domain.EvaluationJob
-	err := r.db.Where("status = ?", "queued").
+	err := r.db.Where("status = ?", domain.JobQueued).
 		Order("created_at ASC").
 		Limit(limit).
 		Find(&jobs).Error
 	return jobs, err
 }
+
+// RankCompleted lists the completed jobs of one opening, matched on the job
+// title case-insensitively, ordered by the weighted score of the normalized
+// weights. It also returns how many jobs the ranking holds in total.
+// Erased candidates keep their place with an empty name.
+func (r *EvaluationRepository) RankCompleted(tenantID, jobTitle string, weights domain.RankingWeights, limit, offset int) ([]domain.RankedCandidate, int64, error) {
+	query := r.db.Table("evaluation_jobs AS j").
+		Where("j.tenant_id = ? AND lower(j.job_title) = lower(?) AND j.status = ?", tenantID, jobTitle, domain.JobCompleted).
+		Where("j.cv_match_rate IS NOT NULL AND j.project_score IS NOT NULL").
+		Session(&gorm.Session{}) // shared by the count and the page query
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	score, args := weights.ScoreSQL("j.cv_match_rate", "j.project_score")
+	var candidates []domain.RankedCandidate
+	err := query.
+		Select(`j.id AS job_id, COALESCE(j.cv_id, 0) AS cv_id, COALESCE(j.report_id, 0) AS report_id,
+			COALESCE(d.filename, '') AS candidate,
+			`+score+` AS score,
+			j.cv_match_rate, j.project_score, j.recommendation, j.blind, j.updated_at AS completed_at`,
+			args...).
+		Joins("LEFT JOIN documents d ON d.id = j.cv_id").
+		Order("score DESC, j.cv_match_rate DESC, j.id ASC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&candidates).Error
+	return candidates, total, err
+}
+
+// EachForExport calls fn for every job matching the filter, oldest first.
+// Rows are read one at a time from the cursor, so memory use doesn't grow
+// with the number of jobs.
+func (r *EvaluationRepository) EachForExport(ctx context.Context, filter domain.JobFilter, fn func(row *domain.JobExportRow) error) error {
+	query := r.db.WithContext(ctx).Table("evaluation_jobs AS j").
+		Select(`j.id, j.job_title, j.status, j.priority, j.blind, j.batch_id,
+			j.cv_id, d.filename AS candidate, j.report_id,
+			j.cv_match_rate, j.project_score, j.recommendation,
+			j.result->>'cv_feedback' AS cv_feedback,
+			j.result->>'project_feedback' AS project_feedback,
+			j.result->>'overall_summary' AS overall_summary,
+			j.error_message, j.run_at, j.created_at, j.updated_at, j.erased_at`).
+		Joins("LEFT JOIN documents d ON d.id = j.cv_id").
+		Where("j.tenant_id = ?", filter.TenantID).
+		Order("j.id ASC")
+
+	if filter.Status != "" {
+		query = query.Where("j.status = ?", filter.Status)
+	}
+	if filter.JobTitle != "" {
+		query = query.Where("lower(j.job_title) = lower(?)", filter.JobTitle)
+	}
+	if filter.Priority != "" {
+		query = query.Where("j.priority = ?", filter.Priority)
+	}
+	if filter.Recommendation != "" {
+		query = query.Where("j.recommendation = ?", filter.Recommendation)
+	}
+	if filter.BatchID != nil {
+		query = query.Where("j.batch_id = ?", *filter.BatchID)
+	}
+	if filter.From != nil {
+		query = query.Where("j.created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("j.created_at < ?", *filter.To)
+	}
+
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row domain.JobExportRow
+		if err := r.db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
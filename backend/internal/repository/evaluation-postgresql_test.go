@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adyutaa/parsea/internal/domain"
+	"github.com/adyutaa/parsea/internal/testdb"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func newQueuedJob(tenantID string, cvID, reportID uint) *domain.EvaluationJob {
+	return &domain.EvaluationJob{
+		TenantID: tenantID,
+		CVID:     cvID,
+		ReportID: reportID,
+		JobTitle: "Backend Engineer",
+		Status:   domain.JobQueued,
+	}
+}
+
+// The recommendation column only accepts known values or NULL, so an
+// unscored job must not write an empty string into it
+func TestCreateJobLeavesRecommendationNull(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stmt := db.Create(newQueuedJob("acme", 1, 2)).Statement
+	columns, _, _ := strings.Cut(stmt.SQL.String(), "VALUES")
+	if strings.Contains(columns, `"recommendation"`) {
+		t.Errorf("INSERT writes recommendation for an unscored job: %s", db.Dialector.Explain(stmt.SQL.String(), stmt.Vars...))
+	}
+}
+
+func TestCreateQueuedJob(t *testing.T) {
+	tx := testdb.Tx(t)
+
+	tenant := domain.DefaultTenant("repo-test")
+	if err := NewTenantRepository(tx).Create(tenant); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+	docRepo := NewDocumentRepository(tx)
+	cv := &domain.Document{TenantID: tenant.ID, Filename: "cv.txt", FilePath: "/tmp/cv.txt", DocType: "cv", MimeType: "text/plain"}
+	report := &domain.Document{TenantID: tenant.ID, Filename: "report.txt", FilePath: "/tmp/report.txt", DocType: "project_report", MimeType: "text/plain"}
+	for _, doc := range []*domain.Document{cv, report} {
+		if err := docRepo.Create(doc); err != nil {
+			t.Fatalf("create document: %v", err)
+		}
+	}
+
+	evalRepo := NewEvaluationRepository(tx)
+	encode := func(jobID uint) (string, error) { return "payload", nil }
+
+	created := newQueuedJob(tenant.ID, cv.ID, report.ID)
+	if err := evalRepo.Create(created); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	enqueued := newQueuedJob(tenant.ID, cv.ID, report.ID)
+	if err := evalRepo.CreateAndEnqueue(enqueued, "evaluation:normal", encode); err != nil {
+		t.Fatalf("CreateAndEnqueue: %v", err)
+	}
+	batched := newQueuedJob(tenant.ID, cv.ID, report.ID)
+	batch := &domain.Batch{TenantID: tenant.ID, JobTitle: batched.JobTitle}
+	err := NewBatchRepository(tx).CreateWithJobs(batch, []*domain.EvaluationJob{batched},
+		func(*domain.EvaluationJob) string { return "evaluation:bulk" }, encode)
+	if err != nil {
+		t.Fatalf("CreateWithJobs: %v", err)
+	}
+
+	for _, job := range []*domain.EvaluationJob{created, enqueued, batched} {
+		var recommendation *string
+		if err := tx.Table("evaluation_jobs").Select("recommendation").Where("id = ?", job.ID).Scan(&recommendation).Error; err != nil {
+			t.Fatalf("load job %d: %v", job.ID, err)
+		}
+		if recommendation != nil {
+			t.Errorf("job %d recommendation = %q, want NULL", job.ID, *recommendation)
+		}
+
+		stored, err := evalRepo.GetByID(job.ID)
+		if err != nil {
+			t.Fatalf("GetByID(%d): %v", job.ID, err)
+		}
+		if stored.Status != domain.JobQueued || stored.Recommendation != "" {
+			t.Errorf("job %d = status %q, recommendation %q; want queued without recommendation", job.ID, stored.Status, stored.Recommendation)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/adyutaa/parsea/internal/domain"
+	"gorm.io/gorm"
+)
+
+type LLMBatchRepository struct {
+	db *gorm.DB
+}
+
+func NewLLMBatchRepository(db *gorm.DB) *LLMBatchRepository {
+	return &LLMBatchRepository{db: db}
+}
+
+// Create saves a submitted provider batch and its items
+func (r *LLMBatchRepository) Create(batch *domain.LLMBatch, items []domain.LLMBatchItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return createBatch(tx, batch, items)
+	})
+}
+
+func createBatch(tx *gorm.DB, batch *domain.LLMBatch, items []domain.LLMBatchItem) error {
+	if err := tx.Create(batch).Error; err != nil {
+		return err
+	}
+	for i := range items {
+		items[i].BatchID = batch.ID
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return tx.Create(&items).Error
+}
+
+// ClaimDue leases the submitted batch that has waited longest for a poll by
+// pushing its next_poll_at lease into the future, so concurrent runners
+// never poll the same batch. It returns nil when no batch is due.
+func (r *LLMBatchRepository) ClaimDue(now time.Time, lease time.Duration) (*domain.LLMBatch, error) {
+	var batch domain.LLMBatch
+	err := r.db.Raw(`
+		UPDATE llm_batches SET next_poll_at = ?
+		WHERE id = (
+			SELECT id FROM llm_batches
+			WHERE status = ? AND next_poll_at <= ?
+			ORDER BY next_poll_at
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING *`,
+		now.Add(lease), domain.LLMBatchSubmitted, now,
+	).Scan(&batch).Error
+	if err != nil {
+		return nil, err
+	}
+	if batch.ID == 0 {
+		return nil, nil
+	}
+	return &batch, nil
+}
+
+// Items returns the jobs of a batch with their carried state
+func (r *LLMBatchRepository) Items(batchID uint) ([]domain.LLMBatchItem, error) {
+	var items []domain.LLMBatchItem
+	err := r.db.Where("batch_id = ?", batchID).Order("job_id ASC").Find(&items).Error
+	return items, err
+}
+
+// Finish records a batch's final status, clears its items' state and, when
+// next is given, saves the batch that continues the pipeline, all in one
+// transaction
+func (r *LLMBatchRepository) Finish(batch *domain.LLMBatch, next *domain.LLMBatch, nextItems []domain.LLMBatchItem) error {
+	now := time.Now()
+	batch.CompletedAt = &now
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.LLMBatch{}).Where("id = ?", batch.ID).
+			Updates(map[string]interface{}{
+				"status":        batch.Status,
+				"error_message": batch.ErrorMessage,
+				"completed_at":  now,
+			}).Error; err != nil {
+			return err
+		}
+		// The state may hold PII placeholders; it isn't needed once the
+		// stage is done
+		if err := tx.Model(&domain.LLMBatchItem{}).Where("batch_id = ?", batch.ID).
+			Update("state", nil).Error; err != nil {
+			return err
+		}
+		if next == nil {
+			return nil
+		}
+		return createBatch(tx, next, nextItems)
+	})
+}
+
+// ClearState drops the carried state of a job's item once the job has
+// finished or failed; it holds the PII originals of its redactions
+func (r *LLMBatchRepository) ClearState(batchID, jobID uint) error {
+	return r.db.Model(&domain.LLMBatchItem{}).
+		Where("batch_id = ? AND job_id = ?", batchID, jobID).
+		Update("state", nil).Error
+}
+
+// CountSubmitted returns how many batches are waiting on the provider
+func (r *LLMBatchRepository) CountSubmitted() (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.LLMBatch{}).Where("status = ?", domain.LLMBatchSubmitted).Count(&count).Error
+	return count, err
+}
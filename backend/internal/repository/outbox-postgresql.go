@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/adyutaa/parsea/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// PublishFunc sends a batch of outbox messages, returning one error per
+// message (nil when sent)
+type PublishFunc func(messages []domain.OutboxMessage) []error
+
+// Relay locks up to limit unsent messages, publishes them and marks the
+// successful ones sent, all in one transaction. SKIP LOCKED lets several
+// relays run side by side without publishing the same row concurrently. If
+// the commit fails after publishing, the rows are published again on the
+// next pass, so delivery is at-least-once. It returns how many were sent.
+func (r *OutboxRepository) Relay(limit int, publish PublishFunc) (int, error) {
+	sent := 0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var messages []domain.OutboxMessage
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("sent_at IS NULL").
+			Order("id ASC").
+			Limit(limit).
+			Find(&messages).Error; err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+
+		errs := publish(messages)
+		var sentIDs []uint
+		for i, msg := range messages {
+			if errs[i] == nil {
+				sentIDs = append(sentIDs, msg.ID)
+				continue
+			}
+			if err := tx.Model(&domain.OutboxMessage{}).Where("id = ?", msg.ID).
+				Updates(map[string]interface{}{
+					"attempts":   gorm.Expr("attempts + 1"),
+					"last_error": errs[i].Error(),
+				}).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(sentIDs) > 0 {
+			if err := tx.Model(&domain.OutboxMessage{}).Where("id IN ?", sentIDs).
+				Updates(map[string]interface{}{
+					"sent_at":  time.Now(),
+					"attempts": gorm.Expr("attempts + 1"),
+				}).Error; err != nil {
+				return err
+			}
+		}
+		sent = len(sentIDs)
+		return nil
+	})
+	return sent, err
+}
+
+// CountPending returns how many messages are waiting to be published
+func (r *OutboxRepository) CountPending() (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.OutboxMessage{}).Where("sent_at IS NULL").Count(&count).Error
+	return count, err
+}
+
+// DeleteSentBefore removes messages published before cutoff
+func (r *OutboxRepository) DeleteSentBefore(cutoff time.Time) (int64, error) {
+	res := r.db.Where("sent_at IS NOT NULL AND sent_at < ?", cutoff).Delete(&domain.OutboxMessage{})
+	return res.RowsAffected, res.Error
+}
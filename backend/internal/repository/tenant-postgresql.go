@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/adyutaa/parsea/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TenantRepository struct {
+	db *gorm.DB
+}
+
+func NewTenantRepository(db *gorm.DB) *TenantRepository {
+	return &TenantRepository{db: db}
+}
+
+// GetByID retrieves a tenant; unknown tenants are gorm.ErrRecordNotFound
+func (r *TenantRepository) GetByID(id string) (*domain.Tenant, error) {
+	var tenant domain.Tenant
+	err := r.db.Where("id = ?", id).First(&tenant).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+// Save creates or updates a tenant's settings
+func (r *TenantRepository) Save(tenant *domain.Tenant) error {
+	tenant.UpdatedAt = time.Now()
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"name", "redact_pii", "monthly_token_budget", "monthly_cost_budget_usd",
+			"document_retention_days", "updated_at",
+		}),
+	}).Create(tenant).Error
+}
+
+// Create stores a new tenant, failing if the ID is taken
+func (r *TenantRepository) Create(tenant *domain.Tenant) error {
+	return r.db.Create(tenant).Error
+}
+
+// List returns every tenant, ordered by ID
+func (r *TenantRepository) List() ([]domain.Tenant, error) {
+	var tenants []domain.Tenant
+	err := r.db.Order("id ASC").Find(&tenants).Error
+	return tenants, err
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/adyutaa/parsea/internal/domain"
+	"gorm.io/gorm"
+)
+
+type UsageRepository struct {
+	db *gorm.DB
+}
+
+func NewUsageRepository(db *gorm.DB) *UsageRepository {
+	return &UsageRepository{db: db}
+}
+
+const usageSummaryColumns = `COUNT(*) AS calls,
+	COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens,
+	COALESCE(SUM(completion_tokens), 0) AS completion_tokens,
+	COALESCE(SUM(total_tokens), 0) AS total_tokens,
+	COALESCE(SUM(cost_usd), 0) AS cost_usd`
+
+// Create saves the usage of a single call
+func (r *UsageRepository) Create(usage *domain.LLMUsage) error {
+	return r.db.Create(usage).Error
+}
+
+// SummaryForJob aggregates the usage of all calls made for a job
+func (r *UsageRepository) SummaryForJob(jobID uint) (*domain.UsageSummary, error) {
+	var summary domain.UsageSummary
+	err := r.db.Model(&domain.LLMUsage{}).
+		Select(usageSummaryColumns).
+		Where("job_id = ?", jobID).
+		Scan(&summary).Error
+	return &summary, err
+}
+
+// SummaryForTenant aggregates a tenant's usage in [from, to)
+func (r *UsageRepository) SummaryForTenant(tenantID string, from, to time.Time) (*domain.UsageSummary, error) {
+	var summary domain.UsageSummary
+	err := r.db.Model(&domain.LLMUsage{}).
+		Select(usageSummaryColumns).
+		Where("tenant_id = ? AND created_at >= ? AND created_at < ?", tenantID, from, to).
+		Scan(&summary).Error
+	return &summary, err
+}
+
+// BreakdownForTenant aggregates a tenant's usage in [from, to) per model and operation
+func (r *UsageRepository) BreakdownForTenant(tenantID string, from, to time.Time) ([]domain.UsageBreakdown, error) {
+	var breakdown []domain.UsageBreakdown
+	err := r.db.Model(&domain.LLMUsage{}).
+		Select("model, operation, "+usageSummaryColumns).
+		Where("tenant_id = ? AND created_at >= ? AND created_at < ?", tenantID, from, to).
+		Group("model, operation").
+		Order("cost_usd DESC").
+		Scan(&breakdown).Error
+	return breakdown, err
+}
+
+// CountJobsForTenant counts the distinct jobs that made calls in [from, to)
+func (r *UsageRepository) CountJobsForTenant(tenantID string, from, to time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.LLMUsage{}).
+		Where("tenant_id = ? AND created_at >= ? AND created_at < ? AND job_id IS NOT NULL", tenantID, from, to).
+		Distinct("job_id").
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/adyutaa/parsea/internal/domain"
+	"github.com/adyutaa/parsea/internal/repository"
+	"gorm.io/gorm"
+)
+
+// apiKeyPrefix starts every generated key, so leaked keys are easy to spot
+const apiKeyPrefix = "psk_"
+
+// APIKeyService issues API keys and resolves requests' keys to their tenant
+type APIKeyService struct {
+	repo       *repository.APIKeyRepository
+	tenantRepo *repository.TenantRepository
+}
+
+func NewAPIKeyService(repo *repository.APIKeyRepository, tenantRepo *repository.TenantRepository) *APIKeyService {
+	return &APIKeyService{repo: repo, tenantRepo: tenantRepo}
+}
+
+// Create issues a key for an existing tenant and returns it with its secret,
+// which can't be recovered later
+func (s *APIKeyService) Create(tenantID, name string, role domain.APIKeyRole) (*domain.APIKey, string, error) {
+	if _, err := s.tenantRepo.GetByID(tenantID); errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, "", fmt.Errorf("%w: %s", ErrTenantNotFound, tenantID)
+	} else if err != nil {
+		return nil, "", fmt.Errorf("failed to load tenant: %w", err)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	plain := apiKeyPrefix + hex.EncodeToString(secret)
+
+	key := &domain.APIKey{
+		TenantID: tenantID,
+		Name:     name,
+		Role:     role,
+		Prefix:   plain[:len(apiKeyPrefix)+8],
+		KeyHash:  hashAPIKey(plain),
+	}
+	if err := s.repo.Create(key); err != nil {
+		return nil, "", fmt.Errorf("failed to save key: %w", err)
+	}
+	return key, plain, nil
+}
+
+// Authenticate returns the active key matching plain, or nil when there is none
+func (s *APIKeyService) Authenticate(plain string) (*domain.APIKey, error) {
+	key, err := s.repo.GetActiveByHash(hashAPIKey(plain))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up API key: %w", err)
+	}
+	return key, nil
+}
+
+// List returns a tenant's keys without their secrets
+func (s *APIKeyService) List(tenantID string) ([]domain.APIKey, error) {
+	return s.repo.List(tenantID)
+}
+
+// Revoke stops a key from authenticating
+func (s *APIKeyService) Revoke(id uint) error {
+	return s.repo.Revoke(id)
+}
+
+func hashAPIKey(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,379 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adyutaa/parsea/internal/domain"
+	"github.com/adyutaa/parsea/internal/logging"
+	"github.com/adyutaa/parsea/internal/queue"
+	"github.com/adyutaa/parsea/internal/repository"
+	"github.com/adyutaa/parsea/internal/tracing"
+	"github.com/adyutaa/parsea/internal/validation"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// MaxBatchSize is the most candidates one batch may contain
+const MaxBatchSize = 100
+
+// ErrInvalidBatch wraps problems with the submitted pairs or archive
+var ErrInvalidBatch = errors.New("invalid batch")
+
+// DocumentPair is one candidate's CV and project report
+type DocumentPair struct {
+	CVID     uint `json:"cv_id"`
+	ReportID uint `json:"report_id"`
+}
+
+// StartBatchParams describes a new batch
+type StartBatchParams struct {
+	JobTitle string
+	TenantID string
+	Blind    bool
+	Priority domain.Priority
+	Pairs    []DocumentPair
+}
+
+// BatchStatus is the progress of a batch and, once finished, its leaderboard
+type BatchStatus struct {
+	Batch       *domain.Batch             `json:"batch"`
+	Status      string                    `json:"status"` // processing or completed
+	Progress    *domain.BatchProgress     `json:"progress"`
+	Leaderboard []domain.LeaderboardEntry `json:"leaderboard,omitempty"`
+}
+
+type BatchService struct {
+	batchRepo   *repository.BatchRepository
+	docRepo     *repository.DocumentRepository
+	docService  *DocumentService
+	evalService *EvaluationService
+	// weights rank the leaderboard, the same defaults as an opening's ranking
+	weights domain.RankingWeights
+}
+
+func NewBatchService(batchRepo *repository.BatchRepository, docRepo *repository.DocumentRepository, docService *DocumentService, evalService *EvaluationService, weights domain.RankingWeights) *BatchService {
+	return &BatchService{
+		batchRepo:   batchRepo,
+		docRepo:     docRepo,
+		docService:  docService,
+		evalService: evalService,
+		weights:     weights,
+	}
+}
+
+// StartBatch validates every pair, then creates the batch and one queued job
+// per pair in a single transaction
+func (s *BatchService) StartBatch(ctx context.Context, params StartBatchParams) (batch *domain.Batch, err error) {
+	ctx, span := tracing.Start(ctx, "batch.start",
+		attribute.String("tenant.id", params.TenantID),
+		attribute.Int("batch.size", len(params.Pairs)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	if len(params.Pairs) == 0 {
+		return nil, fmt.Errorf("%w: at least one CV/report pair is required", ErrInvalidBatch)
+	}
+	if len(params.Pairs) > MaxBatchSize {
+		return nil, fmt.Errorf("%w: at most %d pairs per batch", ErrInvalidBatch, MaxBatchSize)
+	}
+
+	for i, pair := range params.Pairs {
+		if err := s.checkDocument(pair.CVID, "cv", params.TenantID); err != nil {
+			return nil, fmt.Errorf("%w: pair %d: %v", ErrInvalidBatch, i+1, err)
+		}
+		if err := s.checkDocument(pair.ReportID, "project_report", params.TenantID); err != nil {
+			return nil, fmt.Errorf("%w: pair %d: %v", ErrInvalidBatch, i+1, err)
+		}
+	}
+
+	if err := s.evalService.checkBudget(params.TenantID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	jobs := make([]*domain.EvaluationJob, len(params.Pairs))
+	for i, pair := range params.Pairs {
+		jobs[i] = &domain.EvaluationJob{
+			TenantID:  params.TenantID,
+			CVID:      pair.CVID,
+			ReportID:  pair.ReportID,
+			JobTitle:  params.JobTitle,
+			Status:    domain.JobQueued,
+			Blind:     params.Blind,
+			Priority:  params.Priority,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+	}
+
+	batch = &domain.Batch{TenantID: params.TenantID, JobTitle: params.JobTitle, CreatedAt: now}
+	err = s.batchRepo.CreateWithJobs(batch, jobs,
+		func(job *domain.EvaluationJob) string { return queue.NameFor(job.Priority) },
+		func(jobID uint) (string, error) { return queue.NewMessage(ctx, jobID).Encode() },
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create batch: %w", err)
+	}
+	s.evalService.notifyOutbox()
+
+	span.SetAttributes(attribute.String("batch.id", strconv.FormatUint(uint64(batch.ID), 10)))
+	return batch, nil
+}
+
+// StartArchiveBatch stores the CVs and reports of a zip upload and starts a
+// batch for them, returning the batch and the stored pairs. The budget is
+// checked before anything is stored, and the stored documents are deleted
+// again if the batch can't be started.
+func (s *BatchService) StartArchiveBatch(ctx context.Context, params StartBatchParams, archive io.ReaderAt, size int64) (*domain.Batch, []DocumentPair, error) {
+	if err := s.evalService.checkBudget(params.TenantID); err != nil {
+		return nil, nil, err
+	}
+
+	pairs, err := s.saveArchive(archive, size, params.TenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	params.Pairs = pairs
+	batch, err := s.StartBatch(ctx, params)
+	if err != nil {
+		s.discard(ctx, pairs)
+		return nil, nil, err
+	}
+	return batch, pairs, nil
+}
+
+// saveArchive stores the CVs and reports of a zip upload and returns their
+// pairs. Each candidate is either a folder holding a file named cv* or
+// resume* and one named report* or project*, or a pair of top-level files
+// named <candidate>_cv.<ext> and <candidate>_report.<ext>. Names and
+// pairing are checked before anything is stored; if storing a file fails,
+// those already stored are deleted.
+func (s *BatchService) saveArchive(archive io.ReaderAt, size int64, tenantID string) ([]DocumentPair, error) {
+	reader, err := zip.NewReader(archive, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: not a valid zip archive", ErrInvalidBatch)
+	}
+
+	// Room for folders and archiver metadata next to two files per candidate
+	if len(reader.File) > 4*MaxBatchSize {
+		return nil, fmt.Errorf("%w: archive has too many entries", ErrInvalidBatch)
+	}
+
+	type candidateFiles struct{ cv, report *zip.File }
+	candidates := map[string]*candidateFiles{}
+	for _, f := range reader.File {
+		if f.FileInfo().IsDir() || ignoredArchiveEntry(f.Name) {
+			continue
+		}
+
+		// Reject unsupported files before anything is stored
+		if err := validation.ValidateFilename(path.Base(f.Name)); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidBatch, f.Name, err)
+		}
+		name, role := classifyArchiveEntry(f.Name)
+		if role == "" {
+			return nil, fmt.Errorf("%w: can't tell whether %q is a CV or a report; name it cv.* / report.* inside a folder per candidate, or <candidate>_cv.* / <candidate>_report.*", ErrInvalidBatch, f.Name)
+		}
+		c := candidates[name]
+		if c == nil {
+			c = &candidateFiles{}
+			candidates[name] = c
+		}
+		slot := &c.cv
+		if role == "project_report" {
+			slot = &c.report
+		}
+		if *slot != nil {
+			return nil, fmt.Errorf("%w: candidate %q has more than one %s", ErrInvalidBatch, name, role)
+		}
+		*slot = f
+	}
+
+	names := make([]string, 0, len(candidates))
+	for name, c := range candidates {
+		if c.cv == nil || c.report == nil {
+			return nil, fmt.Errorf("%w: candidate %q needs both a CV and a project report", ErrInvalidBatch, name)
+		}
+		names = append(names, name)
+	}
+	if len(names) > MaxBatchSize {
+		return nil, fmt.Errorf("%w: at most %d candidates per batch", ErrInvalidBatch, MaxBatchSize)
+	}
+	sort.Strings(names)
+
+	pairs := make([]DocumentPair, 0, len(names))
+	for _, name := range names {
+		c := candidates[name]
+		cvID, err := s.saveEntry(c.cv, name, "cv", tenantID)
+		if err != nil {
+			s.discard(context.Background(), pairs)
+			return nil, err
+		}
+		reportID, err := s.saveEntry(c.report, name, "project_report", tenantID)
+		if err != nil {
+			s.discard(context.Background(), append(pairs, DocumentPair{CVID: cvID}))
+			return nil, err
+		}
+		pairs = append(pairs, DocumentPair{CVID: cvID, ReportID: reportID})
+	}
+	return pairs, nil
+}
+
+// discard deletes the documents of an archive whose batch was never started
+func (s *BatchService) discard(ctx context.Context, pairs []DocumentPair) {
+	ids := make([]uint, 0, 2*len(pairs))
+	for _, pair := range pairs {
+		ids = append(ids, pair.CVID)
+		if pair.ReportID != 0 {
+			ids = append(ids, pair.ReportID)
+		}
+	}
+	if err := s.docService.DeleteDocuments(ids); err != nil {
+		slog.ErrorContext(ctx, "failed to delete documents of an unstarted batch", "document_ids", ids, logging.Err(err))
+	}
+}
+
+// saveEntry stores one archive file, named <candidate>/<file> for display
+func (s *BatchService) saveEntry(f *zip.File, candidate, docType, tenantID string) (uint, error) {
+	base := path.Base(f.Name)
+	if f.UncompressedSize64 > uint64(s.docService.MaxFileSize()) {
+		return 0, fmt.Errorf("%w: %s is larger than the upload limit", ErrInvalidBatch, f.Name)
+	}
+
+	src, err := f.Open()
+	if err != nil {
+		return 0, fmt.Errorf("%w: can't read %s: %v", ErrInvalidBatch, f.Name, err)
+	}
+	defer src.Close()
+
+	id, err := s.docService.SaveFile(candidate+"/"+base, int64(f.UncompressedSize64), src, docType, tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s: %v", ErrInvalidBatch, f.Name, err)
+	}
+	return id, nil
+}
+
+// GetBatch returns a batch's progress, with the leaderboard once every job
+// has finished
+func (s *BatchService) GetBatch(id, tenantID string) (*BatchStatus, error) {
+	idUint, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid batch ID format: %w", err)
+	}
+
+	batch, err := s.batchRepo.GetByID(uint(idUint))
+	if err != nil {
+		return nil, fmt.Errorf("batch not found: %w", err)
+	}
+	if batch.TenantID != tenantID {
+		return nil, fmt.Errorf("batch not found")
+	}
+
+	progress, err := s.batchRepo.Progress(batch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load batch progress: %w", err)
+	}
+
+	status := &BatchStatus{Batch: batch, Status: "processing", Progress: progress}
+	if !progress.Done() {
+		return status, nil
+	}
+
+	status.Status = "completed"
+	entries, err := s.batchRepo.CompletedJobs(batch.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load batch results: %w", err)
+	}
+	weights, err := s.weights.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	status.Leaderboard = rankCandidates(entries, weights)
+	return status, nil
+}
+
+// rankCandidates orders candidates by their weighted score, breaking ties on
+// the CV match rate and then job ID so the order is stable. It matches the
+// order of an opening's ranking with the same weights.
+func rankCandidates(entries []domain.LeaderboardEntry, weights domain.RankingWeights) []domain.LeaderboardEntry {
+	for i := range entries {
+		entries[i].Score = weights.Score(entries[i].CVMatchRate, entries[i].ProjectScore)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.CVMatchRate != b.CVMatchRate {
+			return a.CVMatchRate > b.CVMatchRate
+		}
+		return a.JobID < b.JobID
+	})
+	for i := range entries {
+		entries[i].Rank = i + 1
+	}
+	return entries
+}
+
+func (s *BatchService) checkDocument(id uint, docType, tenantID string) error {
+	if id == 0 {
+		return fmt.Errorf("%s document ID is required", docType)
+	}
+	doc, err := s.docRepo.GetByID(id)
+	if err != nil || doc.TenantID != tenantID {
+		return fmt.Errorf("%s document %d not found", docType, id)
+	}
+	if doc.DocType != docType {
+		return fmt.Errorf("document %d is a %s, not a %s", id, doc.DocType, docType)
+	}
+	return nil
+}
+
+// ignoredArchiveEntry skips metadata that archivers add, such as macOS
+// resource forks and hidden files
+func ignoredArchiveEntry(name string) bool {
+	if strings.HasPrefix(name, "__MACOSX/") {
+		return true
+	}
+	return strings.HasPrefix(path.Base(name), ".")
+}
+
+// classifyArchiveEntry returns the candidate an archive file belongs to and
+// whether it is the CV or the project report ("" when unknown)
+func classifyArchiveEntry(name string) (candidate, role string) {
+	base := path.Base(name)
+	stem := strings.ToLower(strings.TrimSuffix(base, path.Ext(base)))
+
+	if dir := path.Dir(name); dir != "." {
+		candidate = path.Base(dir)
+		switch {
+		case strings.HasPrefix(stem, "cv"), strings.HasPrefix(stem, "resume"):
+			return candidate, "cv"
+		case strings.HasPrefix(stem, "report"), strings.HasPrefix(stem, "project"):
+			return candidate, "project_report"
+		}
+		return candidate, ""
+	}
+
+	for _, suffix := range []string{"_cv", "-cv", "_resume", "-resume"} {
+		if strings.HasSuffix(stem, suffix) {
+			return stem[:len(stem)-len(suffix)], "cv"
+		}
+	}
+	for _, suffix := range []string{"_report", "-report", "_project", "-project"} {
+		if strings.HasSuffix(stem, suffix) {
+			return stem[:len(stem)-len(suffix)], "project_report"
+		}
+	}
+	return stem, ""
+}
@@ -1,8 +1,11 @@
 package service
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"mime/multipart"
 	"os"
 	"path/filepath"
@@ -11,37 +14,35 @@ import (
 
 	"github.com/adyutaa/parsea/internal/domain"
 	"github.com/adyutaa/parsea/internal/repository"
+	"github.com/adyutaa/parsea/internal/validation"
+	"github.com/adyutaa/parsea/pkg/extract"
 )
 
 type DocumentService struct {
-	repo       *repository.DocumentRepository
-	uploadPath string
+	repo        *repository.DocumentRepository
+	uploadPath  string
+	maxFileSize int64
 }
 
-func NewDocumentService(repo *repository.DocumentRepository, uploadPath string) *DocumentService {
+func NewDocumentService(repo *repository.DocumentRepository, uploadPath string, maxFileSize int64) *DocumentService {
 	return &DocumentService{
-		repo:       repo,
-		uploadPath: uploadPath,
+		repo:        repo,
+		uploadPath:  uploadPath,
+		maxFileSize: maxFileSize,
 	}
 }
 
-// SaveDocument saves an uploaded file and stores its metadata
-func (s *DocumentService) SaveDocument(file *multipart.FileHeader, docType string) (uint, error) {
-	// Validate file type
-	ext := filepath.Ext(file.Filename)
-	if ext != ".pdf" {
-		return 0, fmt.Errorf("only PDF files are allowed")
-	}
+// MaxFileSize is the largest upload accepted, in bytes
+func (s *DocumentService) MaxFileSize() int64 {
+	return s.maxFileSize
+}
 
-	// Validate file size (max 10MB)
-	if file.Size > 10*1024*1024 {
-		return 0, fmt.Errorf("file size exceeds 10MB limit")
+// SaveDocument saves an uploaded file and stores its metadata
+func (s *DocumentService) SaveDocument(file *multipart.FileHeader, docType, tenantID string) (uint, error) {
+	if err := validation.ValidateFileSize(file.Size, s.maxFileSize); err != nil {
+		return 0, err
 	}
 
-	// Create file path with timestamp for uniqueness
-	filename := fmt.Sprintf("%d_%s", time.Now().UnixNano(), file.Filename)
-	filePath := filepath.Join(s.uploadPath, filename)
-
 	// Open uploaded file
 	src, err := file.Open()
 	if err != nil {
@@ -49,6 +50,31 @@ func (s *DocumentService) SaveDocument(file *multipart.FileHeader, docType strin
 	}
 	defer src.Close()
 
+	return s.SaveFile(file.Filename, file.Size, src, docType, tenantID)
+}
+
+// SaveFile stores a document read from src. filename is kept as the display
+// name; only its base name is used on disk.
+func (s *DocumentService) SaveFile(filename string, size int64, src io.Reader, docType, tenantID string) (uint, error) {
+	if err := validation.ValidateFileSize(size, s.maxFileSize); err != nil {
+		return 0, err
+	}
+
+	// Detect file type from the extension and sniffed content
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return 0, fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+	mimeType, err := extract.DetectMimeType(filename, head[:n])
+	if err != nil {
+		return 0, err
+	}
+
+	// Create file path with timestamp for uniqueness
+	diskName := fmt.Sprintf("%d_%s", time.Now().UnixNano(), filepath.Base(filename))
+	filePath := filepath.Join(s.uploadPath, diskName)
+
 	// Create destination file
 	dst, err := os.Create(filePath)
 	if err != nil {
@@ -56,17 +82,25 @@ func (s *DocumentService) SaveDocument(file *multipart.FileHeader, docType strin
 	}
 	defer dst.Close()
 
-	// Copy file content
-	if _, err := io.Copy(dst, src); err != nil {
+	// Copy file content, never more than the declared size
+	written, err := io.Copy(dst, io.LimitReader(io.MultiReader(bytes.NewReader(head[:n]), src), s.maxFileSize+1))
+	if err != nil {
+		os.Remove(filePath)
 		return 0, fmt.Errorf("failed to save file: %w", err)
 	}
+	if written > s.maxFileSize {
+		os.Remove(filePath)
+		return 0, validation.ValidateFileSize(written, s.maxFileSize)
+	}
 
 	// Save metadata to database
 	doc := &domain.Document{
-		Filename:   file.Filename,
+		TenantID:   tenantID,
+		Filename:   filename,
 		FilePath:   filePath,
 		DocType:    docType,
-		FileSize:   file.Size,
+		MimeType:   mimeType,
+		FileSize:   written,
 		UploadedAt: time.Now(),
 	}
 
@@ -79,13 +113,43 @@ func (s *DocumentService) SaveDocument(file *multipart.FileHeader, docType strin
 	return doc.ID, nil
 }
 
-// GetDocument retrieves document metadata by ID
-func (s *DocumentService) GetDocument(id string) (*domain.Document, error) {
+// DeleteDocuments removes documents and their files. It is meant for
+// documents that no job refers to yet.
+func (s *DocumentService) DeleteDocuments(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	docs, err := s.repo.GetByIDs(ids)
+	if err != nil {
+		return fmt.Errorf("failed to load documents: %w", err)
+	}
+	if err := s.repo.Delete(ids); err != nil {
+		return fmt.Errorf("failed to delete documents: %w", err)
+	}
+
+	var errs []error
+	for _, doc := range docs {
+		if err := os.Remove(doc.FilePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GetDocument retrieves document metadata by ID within a tenant
+func (s *DocumentService) GetDocument(id, tenantID string) (*domain.Document, error) {
 	// Convert string ID to uint
 	idUint, err := strconv.ParseUint(id, 10, 32)
 	if err != nil {
 		return nil, fmt.Errorf("invalid document ID format: %w", err)
 	}
 
-	return s.repo.GetByID(uint(idUint))
+	doc, err := s.repo.GetByID(uint(idUint))
+	if err != nil {
+		return nil, err
+	}
+	if doc.TenantID != tenantID {
+		return nil, fmt.Errorf("document %s not found", id)
+	}
+	return doc, nil
 }
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/adyutaa/parsea/internal/domain"
+	"github.com/adyutaa/parsea/internal/infrastructure/vectordb"
+	"github.com/adyutaa/parsea/internal/logging"
+	"github.com/adyutaa/parsea/internal/metrics"
+	"github.com/adyutaa/parsea/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ErrCandidateNotFound is returned when erasing a CV the tenant doesn't have
+var ErrCandidateNotFound = errors.New("candidate not found")
+
+// ErasureService deletes candidate data, on request or once the tenant's
+// retention period has passed, keeping only anonymised scores
+type ErasureService struct {
+	repo    *repository.ErasureRepository
+	vectors *vectordb.QdrantClient // nil when Qdrant isn't configured
+}
+
+func NewErasureService(repo *repository.ErasureRepository, vectors *vectordb.QdrantClient) *ErasureService {
+	return &ErasureService{repo: repo, vectors: vectors}
+}
+
+// EraseCandidate erases everything stored about the candidate whose CV is
+// cvID: their CVs, project reports, files and vectors, and the feedback of
+// their evaluations. Unfinished evaluations are failed. The scores stay,
+// detached from the candidate, and the erasure is recorded for audit with the
+// key that asked for it.
+func (s *ErasureService) EraseCandidate(ctx context.Context, tenantID string, cvID uint, by *domain.APIKey) (*domain.Erasure, error) {
+	docs, err := s.repo.CandidateDocuments(tenantID, cvID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: CV %d", ErrCandidateNotFound, cvID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find candidate documents: %w", err)
+	}
+
+	erasure := &domain.Erasure{
+		TenantID:    tenantID,
+		Reason:      domain.ErasureRequest,
+		CandidateID: &cvID,
+		RequestID:   logging.RequestID(ctx),
+		APIKeyID:    &by.ID,
+		Actor:       by.Label(),
+	}
+	if err := s.repo.Erase(erasure, docs, true, s.purgeFunc(ctx, tenantID, docs)); err != nil {
+		return nil, fmt.Errorf("failed to erase candidate: %w", err)
+	}
+
+	s.observe(erasure)
+	slog.InfoContext(ctx, "candidate erased",
+		"erasure_id", erasure.ID,
+		"candidate_id", cvID,
+		"api_key_id", by.ID,
+		"actor", erasure.Actor,
+		"documents", erasure.DocumentsDeleted,
+		"jobs", erasure.JobsAnonymised,
+	)
+	return erasure, nil
+}
+
+// PurgeExpired erases up to limit documents that outlived their tenant's
+// retention period, writing one erasure record per tenant, and returns how
+// many documents were erased
+func (s *ErasureService) PurgeExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	docs, err := s.repo.Expired(now, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find expired documents: %w", err)
+	}
+
+	var tenants []string
+	byTenant := make(map[string][]domain.Document)
+	for _, doc := range docs {
+		if _, ok := byTenant[doc.TenantID]; !ok {
+			tenants = append(tenants, doc.TenantID)
+		}
+		byTenant[doc.TenantID] = append(byTenant[doc.TenantID], doc)
+	}
+
+	purged := 0
+	for _, tenantID := range tenants {
+		tenantDocs := byTenant[tenantID]
+		erasure := &domain.Erasure{TenantID: tenantID, Reason: domain.ErasureRetention, Actor: domain.ErasureActorPurger}
+		err := s.repo.Erase(erasure, tenantDocs, false, s.purgeFunc(ctx, tenantID, tenantDocs))
+		if errors.Is(err, repository.ErrDocumentsInUse) {
+			// A job was created for one of them since they were listed; try again next run
+			slog.DebugContext(ctx, "expired documents in use, purge postponed", "tenant_id", tenantID)
+			continue
+		}
+		if err != nil {
+			return purged, fmt.Errorf("failed to purge documents of tenant %s: %w", tenantID, err)
+		}
+
+		s.observe(erasure)
+		purged += erasure.DocumentsDeleted
+		slog.InfoContext(ctx, "expired documents purged",
+			"erasure_id", erasure.ID,
+			"tenant_id", tenantID,
+			"documents", erasure.DocumentsDeleted,
+			"jobs", erasure.JobsAnonymised,
+		)
+	}
+	return purged, nil
+}
+
+// List returns a tenant's most recent erasure records
+func (s *ErasureService) List(tenantID string, limit int) ([]domain.Erasure, error) {
+	return s.repo.List(tenantID, limit)
+}
+
+// purgeFunc deletes the documents' vectors and files. It runs inside the
+// erasure transaction, so a failure keeps the rows and a later attempt
+// finishes the job; files already gone count as deleted by that earlier run.
+func (s *ErasureService) purgeFunc(ctx context.Context, tenantID string, docs []domain.Document) func(erasure *domain.Erasure) error {
+	return func(erasure *domain.Erasure) error {
+		if s.vectors != nil {
+			n, err := s.vectors.DeleteDocumentVectors(ctx, tenantID, erasure.DocumentIDs)
+			if err != nil {
+				return err
+			}
+			erasure.VectorsDeleted = n
+		}
+
+		for _, doc := range docs {
+			err := os.Remove(doc.FilePath)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to delete file of document %d: %w", doc.ID, err)
+			}
+			erasure.FilesDeleted++
+		}
+		return nil
+	}
+}
+
+func (s *ErasureService) observe(erasure *domain.Erasure) {
+	reason := string(erasure.Reason)
+	metrics.ErasuresTotal.WithLabelValues(reason).Inc()
+	metrics.ErasedDocuments.WithLabelValues(reason).Add(float64(erasure.DocumentsDeleted))
+}
@@ -2,31 +2,95 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/adyutaa/parsea/internal/domain"
+	"github.com/adyutaa/parsea/internal/queue"
 	"github.com/adyutaa/parsea/internal/repository"
+	"github.com/adyutaa/parsea/internal/tracing"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
 	"gorm.io/gorm"
 )
 
+// ErrBudgetExceeded is returned when a tenant has spent its monthly LLM budget
+var ErrBudgetExceeded = errors.New("monthly LLM budget exceeded")
+
+// ErrTenantNotFound is returned for a tenant ID with no stored tenant
+var ErrTenantNotFound = errors.New("tenant not found")
+
+// ErrDocumentNotFound is returned for a document that doesn't exist or
+// belongs to another tenant; the two aren't told apart
+var ErrDocumentNotFound = errors.New("document not found")
+
+// BudgetError describes which budget was spent and when it resets
+type BudgetError struct {
+	Budget   string // "tokens" or "cost_usd"
+	Limit    float64
+	Used     float64
+	ResetsAt time.Time
+}
+
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("%s: used %.2f of %.2f %s, resets at %s",
+		ErrBudgetExceeded, e.Used, e.Limit, e.Budget, e.ResetsAt.Format(time.RFC3339))
+}
+
+func (e *BudgetError) Unwrap() error {
+	return ErrBudgetExceeded
+}
+
 type EvaluationService struct {
-	repo    *repository.EvaluationRepository
-	docRepo *repository.DocumentRepository
-	redis   *redis.Client
+	repo       *repository.EvaluationRepository
+	docRepo    *repository.DocumentRepository
+	usageRepo  *repository.UsageRepository
+	tenantRepo *repository.TenantRepository
+	redis      *redis.Client
+	notify     func() // wakes the outbox relay after a job is committed
 }
 
-func NewEvaluationService(repo *repository.EvaluationRepository, docRepo *repository.DocumentRepository, redis *redis.Client) *EvaluationService {
+func NewEvaluationService(repo *repository.EvaluationRepository, docRepo *repository.DocumentRepository, usageRepo *repository.UsageRepository, tenantRepo *repository.TenantRepository, redis *redis.Client) *EvaluationService {
 	return &EvaluationService{
-		repo:    repo,
-		docRepo: docRepo,
-		redis:   redis,
+		repo:       repo,
+		docRepo:    docRepo,
+		usageRepo:  usageRepo,
+		tenantRepo: tenantRepo,
+		redis:      redis,
 	}
 }
 
-func (s *EvaluationService) StartEvaluation(cvID, reportID, jobTitle string) (string, error) {
+// SetOutboxNotifier registers a function called after each job is committed,
+// so the outbox relay publishes it without waiting for its next poll
+func (s *EvaluationService) SetOutboxNotifier(notify func()) {
+	s.notify = notify
+}
+
+func (s *EvaluationService) notifyOutbox() {
+	if s.notify != nil {
+		s.notify()
+	}
+}
+
+// StartEvaluationParams describes a new evaluation job
+type StartEvaluationParams struct {
+	CVID     string
+	ReportID string
+	JobTitle string
+	TenantID string
+	Blind    bool // strip protected attributes before the CV is evaluated
+	Priority domain.Priority
+	RunAt    *time.Time // when set, the job waits in "scheduled" until then
+}
+
+func (s *EvaluationService) StartEvaluation(ctx context.Context, params StartEvaluationParams) (jobID string, err error) {
+	ctx, span := tracing.Start(ctx, "evaluation.start", attribute.String("tenant.id", params.TenantID))
+	defer func() { tracing.End(span, err) }()
+
+	cvID, reportID, jobTitle, tenantID := params.CVID, params.ReportID, params.JobTitle, params.TenantID
+
 	// Convert string IDs to uint
 	cvIDUint, err := strconv.ParseUint(cvID, 10, 32)
 	if err != nil {
@@ -38,39 +102,70 @@ func (s *EvaluationService) StartEvaluation(cvID, reportID, jobTitle string) (st
 		return "", fmt.Errorf("invalid Report ID format: %w", err)
 	}
 
-	if _, err := s.docRepo.GetByID(uint(cvIDUint)); err != nil {
-		return "", fmt.Errorf("CV document not found: %w", err)
+	if err := s.checkOwnDocument(uint(cvIDUint), tenantID, "CV"); err != nil {
+		return "", err
+	}
+	if err := s.checkOwnDocument(uint(reportIDUint), tenantID, "report"); err != nil {
+		return "", err
 	}
 
-	if _, err := s.docRepo.GetByID(uint(reportIDUint)); err != nil {
-		return "", fmt.Errorf("report document not found: %w", err)
+	if err := s.checkBudget(tenantID); err != nil {
+		return "", err
 	}
 
 	job := &domain.EvaluationJob{
+		TenantID:  tenantID,
 		CVID:      uint(cvIDUint),
 		ReportID:  uint(reportIDUint),
 		JobTitle:  jobTitle,
-		Status:    "queued",
+		Status:    domain.JobQueued,
+		Blind:     params.Blind,
+		Priority:  params.Priority,
+		RunAt:     params.RunAt,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
-	if err := s.repo.Create(job); err != nil {
-		return "", fmt.Errorf("failed to create job: %w", err)
+	if job.RunAt != nil {
+		// The scheduler queues it once run_at has passed
+		job.Status = domain.JobScheduled
+		if err := s.repo.Create(job); err != nil {
+			return "", fmt.Errorf("failed to create job: %w", err)
+		}
+	} else {
+		// The job and its queue message commit together; the outbox relay
+		// publishes the message, carrying the trace so the worker's spans join it
+		msg := queue.NewMessage(ctx, 0)
+		err = s.repo.CreateAndEnqueue(job, queue.NameFor(job.Priority), func(jobID uint) (string, error) {
+			msg.JobID = jobID
+			return msg.Encode()
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to create job: %w", err)
+		}
+		s.notifyOutbox()
 	}
 
-	// PUSH REDIS queue
-	ctx := context.Background()
 	jobIDStr := strconv.FormatUint(uint64(job.ID), 10)
-	if err := s.redis.LPush(ctx, "evaluation_queue", jobIDStr).Err(); err != nil {
-		s.repo.UpdateError(jobIDStr, "failed to queue job")
-		return "", fmt.Errorf("failed to queue job: %w", err)
-	}
+	span.SetAttributes(attribute.String("job.id", jobIDStr))
 
 	return jobIDStr, nil
 }
 
-func (s *EvaluationService) GetJobStatus(id string) (*domain.EvaluationJob, error) {
+// checkOwnDocument returns ErrDocumentNotFound unless the document exists and
+// belongs to the tenant
+func (s *EvaluationService) checkOwnDocument(id uint, tenantID, kind string) error {
+	doc, err := s.docRepo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && doc.TenantID != tenantID) {
+		return fmt.Errorf("%s %w", kind, ErrDocumentNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load %s document: %w", kind, err)
+	}
+	return nil
+}
+
+func (s *EvaluationService) GetJobStatus(id, tenantID string) (*domain.EvaluationJob, error) {
 	// Convert string ID to uint
 	idUint, err := strconv.ParseUint(id, 10, 32)
 	if err != nil {
@@ -81,18 +176,121 @@ func (s *EvaluationService) GetJobStatus(id string) (*domain.EvaluationJob, erro
 	if err != nil {
 		return nil, fmt.Errorf("job not found: %w", err)
 	}
+	if job.TenantID != tenantID {
+		return nil, fmt.Errorf("job not found")
+	}
 	return job, nil
 }
 
+// checkBudget rejects new jobs once the tenant's monthly token or cost budget
+// is spent, and any job for a tenant that doesn't exist
+func (s *EvaluationService) checkBudget(tenantID string) error {
+	tenant, err := s.tenantRepo.GetByID(tenantID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: %s", ErrTenantNotFound, tenantID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load tenant settings: %w", err)
+	}
+	if tenant.MonthlyTokenBudget <= 0 && tenant.MonthlyCostBudgetUSD <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	from := MonthStart(now)
+	usage, err := s.usageRepo.SummaryForTenant(tenantID, from, now)
+	if err != nil {
+		return fmt.Errorf("failed to load usage: %w", err)
+	}
+
+	resetsAt := from.AddDate(0, 1, 0)
+	if tenant.MonthlyTokenBudget > 0 && usage.TotalTokens >= tenant.MonthlyTokenBudget {
+		return &BudgetError{
+			Budget:   "tokens",
+			Limit:    float64(tenant.MonthlyTokenBudget),
+			Used:     float64(usage.TotalTokens),
+			ResetsAt: resetsAt,
+		}
+	}
+	if tenant.MonthlyCostBudgetUSD > 0 && usage.CostUSD >= tenant.MonthlyCostBudgetUSD {
+		return &BudgetError{
+			Budget:   "cost_usd",
+			Limit:    tenant.MonthlyCostBudgetUSD,
+			Used:     usage.CostUSD,
+			ResetsAt: resetsAt,
+		}
+	}
+
+	return nil
+}
+
+// GetJobDocuments returns the CV and project report a job was created for
+func (s *EvaluationService) GetJobDocuments(job *domain.EvaluationJob) (*domain.Document, *domain.Document, error) {
+	cvDoc, err := s.docRepo.GetByID(job.CVID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("CV document not found: %w", err)
+	}
+
+	reportDoc, err := s.docRepo.GetByID(job.ReportID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("report document not found: %w", err)
+	}
+
+	return cvDoc, reportDoc, nil
+}
+
+// GetJobEvents returns a job's status history
+func (s *EvaluationService) GetJobEvents(job *domain.EvaluationJob) ([]domain.JobEvent, error) {
+	return s.repo.ListEvents(job.ID)
+}
+
+// GetJobUsage returns the tokens and estimated cost spent on a job
+func (s *EvaluationService) GetJobUsage(job *domain.EvaluationJob) (*domain.UsageSummary, error) {
+	return s.usageRepo.SummaryForJob(job.ID)
+}
+
 func (s *EvaluationService) GetDB() *gorm.DB {
 	return s.repo.GetDB()
 }
 
-func (s *EvaluationService) GetQueueLength() (int64, error) {
-	ctx := context.Background()
-	length, err := s.redis.LLen(ctx, "evaluation_queue").Result()
-	if err != nil {
-		return 0, err
+// QueueStats describes one priority queue
+type QueueStats struct {
+	Priority domain.Priority `json:"priority"`
+	Queue    string          `json:"queue"`
+	Depth    int64           `json:"depth"`
+	// OldestJobAgeSeconds is how long the next job to be popped has waited;
+	// nil when the queue is empty
+	OldestJobAgeSeconds *float64 `json:"oldest_job_age_seconds"`
+}
+
+// GetQueueStats reports the depth and oldest job age of every priority queue
+func (s *EvaluationService) GetQueueStats(ctx context.Context) ([]QueueStats, error) {
+	stats := make([]QueueStats, 0, len(domain.Priorities))
+	for _, p := range domain.Priorities {
+		name := queue.NameFor(p)
+		depth, err := s.GetQueueDepth(name)
+		if err != nil {
+			return nil, err
+		}
+		st := QueueStats{Priority: p, Queue: name, Depth: depth}
+
+		// Jobs are LPUSHed and BRPOPed, so the oldest is at the tail
+		if depth > 0 {
+			payload, err := s.redis.LIndex(ctx, name, -1).Result()
+			if err != nil && !errors.Is(err, redis.Nil) {
+				return nil, err
+			}
+			if msg, err := queue.Decode(payload); err == nil && !msg.EnqueuedAt.IsZero() {
+				age := time.Since(msg.EnqueuedAt).Seconds()
+				st.OldestJobAgeSeconds = &age
+			}
+		}
+		stats = append(stats, st)
 	}
-	return length, nil
+	return stats, nil
+}
+
+// GetQueueDepth returns the number of jobs waiting in one queue
+func (s *EvaluationService) GetQueueDepth(name string) (int64, error) {
+	return s.redis.LLen(context.Background(), name).Result()
 }
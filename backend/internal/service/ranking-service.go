@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/adyutaa/parsea/internal/domain"
+	"github.com/adyutaa/parsea/internal/infrastructure/llm"
+	"github.com/adyutaa/parsea/internal/logging"
+	"github.com/adyutaa/parsea/internal/repository"
+	"github.com/adyutaa/parsea/internal/tracing"
+	"github.com/adyutaa/parsea/pkg/redact"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Limits on the number of candidates in one comparison
+const (
+	MinCompareCandidates = 2
+	MaxCompareCandidates = 5
+)
+
+// ErrInvalidComparison is returned when the jobs to compare can't be
+// compared, e.g. they are unfinished or belong to different openings
+var ErrInvalidComparison = errors.New("invalid comparison")
+
+type RankingService struct {
+	evalRepo       *repository.EvaluationRepository
+	docRepo        *repository.DocumentRepository
+	tenantRepo     *repository.TenantRepository
+	evalService    *EvaluationService
+	llmClient      *llm.OpenAIService
+	defaultWeights domain.RankingWeights
+}
+
+// NewRankingService builds the service; defaultWeights apply when a ranking
+// request doesn't set its own
+func NewRankingService(evalRepo *repository.EvaluationRepository, docRepo *repository.DocumentRepository, tenantRepo *repository.TenantRepository, evalService *EvaluationService, llmClient *llm.OpenAIService, defaultWeights domain.RankingWeights) *RankingService {
+	return &RankingService{
+		evalRepo:       evalRepo,
+		docRepo:        docRepo,
+		tenantRepo:     tenantRepo,
+		evalService:    evalService,
+		llmClient:      llmClient,
+		defaultWeights: defaultWeights,
+	}
+}
+
+// RankingParams selects a page of an opening's ranking
+type RankingParams struct {
+	TenantID string
+	JobTitle string
+	Weights  *domain.RankingWeights // nil for the configured default
+	Limit    int
+	Offset   int
+}
+
+// Rank orders an opening's completed evaluations by the weighted score
+func (s *RankingService) Rank(params RankingParams) (*domain.Ranking, error) {
+	weights := s.defaultWeights
+	if params.Weights != nil {
+		weights = *params.Weights
+	}
+	weights, err := weights.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, total, err := s.evalRepo.RankCompleted(params.TenantID, params.JobTitle, weights, params.Limit, params.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ranking: %w", err)
+	}
+	for i := range candidates {
+		candidates[i].Rank = params.Offset + i + 1
+	}
+	if candidates == nil {
+		candidates = []domain.RankedCandidate{}
+	}
+
+	return &domain.Ranking{
+		JobTitle:   params.JobTitle,
+		Weights:    weights,
+		Total:      total,
+		Limit:      params.Limit,
+		Offset:     params.Offset,
+		Candidates: candidates,
+	}, nil
+}
+
+// ComparedCandidate is one candidate of a comparison; Label is how the
+// comparison text refers to them
+type ComparedCandidate struct {
+	Label          string                `json:"label"`
+	JobID          uint                  `json:"job_id"`
+	CVID           uint                  `json:"cv_id"`
+	Candidate      string                `json:"candidate"`
+	CVMatchRate    float64               `json:"cv_match_rate"`
+	ProjectScore   float64               `json:"project_score"`
+	Recommendation domain.Recommendation `json:"recommendation"`
+}
+
+// Comparison is the LLM-written side-by-side comparison of candidates
+type Comparison struct {
+	JobTitle   string              `json:"job_title"`
+	Candidates []ComparedCandidate `json:"candidates"`
+	Comparison string              `json:"comparison"`
+}
+
+// Compare asks the LLM to compare 2-5 candidates for the same opening from
+// their stored evaluations. Candidates are sent as "Candidate A", "Candidate
+// B", ... and, if the tenant redacts PII, their names and contact details
+// are redacted from the feedback and restored in the answer.
+func (s *RankingService) Compare(ctx context.Context, tenantID string, jobIDs []uint) (result *Comparison, err error) {
+	ctx, span := tracing.Start(ctx, "ranking.compare",
+		attribute.String("tenant.id", tenantID),
+		attribute.Int("compare.candidates", len(jobIDs)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	if len(jobIDs) < MinCompareCandidates || len(jobIDs) > MaxCompareCandidates {
+		return nil, fmt.Errorf("%w: compare between %d and %d candidates", ErrInvalidComparison, MinCompareCandidates, MaxCompareCandidates)
+	}
+
+	jobs := make([]*domain.EvaluationJob, 0, len(jobIDs))
+	seen := make(map[uint]bool, len(jobIDs))
+	for _, id := range jobIDs {
+		if seen[id] {
+			return nil, fmt.Errorf("%w: job %d is listed twice", ErrInvalidComparison, id)
+		}
+		seen[id] = true
+
+		job, err := s.evalRepo.GetByID(id)
+		if err != nil || job.TenantID != tenantID {
+			return nil, fmt.Errorf("%w: job %d not found", ErrInvalidComparison, id)
+		}
+		if job.Status != domain.JobCompleted || job.Result == nil {
+			return nil, fmt.Errorf("%w: job %d is %s, only completed evaluations can be compared", ErrInvalidComparison, id, job.Status)
+		}
+		if job.ErasedAt != nil {
+			return nil, fmt.Errorf("%w: job %d belongs to an erased candidate", ErrInvalidComparison, id)
+		}
+		if len(jobs) > 0 && !strings.EqualFold(strings.TrimSpace(job.JobTitle), strings.TrimSpace(jobs[0].JobTitle)) {
+			return nil, fmt.Errorf("%w: job %d is for %q, not %q", ErrInvalidComparison, id, job.JobTitle, jobs[0].JobTitle)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := s.evalService.checkBudget(tenantID); err != nil {
+		return nil, err
+	}
+
+	redactor := s.redactorFor(ctx, tenantID, jobs)
+	result = &Comparison{JobTitle: jobs[0].JobTitle, Candidates: make([]ComparedCandidate, len(jobs))}
+	feedback := make([]llm.CandidateFeedback, len(jobs))
+	var opts llm.EvaluationOptions
+	for i, job := range jobs {
+		label := fmt.Sprintf("Candidate %c", 'A'+i)
+		candidate := ""
+		if doc, err := s.docRepo.GetByID(job.CVID); err == nil {
+			candidate = doc.Filename
+		}
+		result.Candidates[i] = ComparedCandidate{
+			Label:          label,
+			JobID:          job.ID,
+			CVID:           job.CVID,
+			Candidate:      candidate,
+			CVMatchRate:    job.Result.CVMatchRate,
+			ProjectScore:   job.Result.ProjectScore,
+			Recommendation: job.Recommendation,
+		}
+		feedback[i] = llm.CandidateFeedback{
+			Label:           label,
+			CVMatchRate:     job.Result.CVMatchRate,
+			CVFeedback:      job.Result.CVFeedback,
+			ProjectScore:    job.Result.ProjectScore,
+			ProjectFeedback: job.Result.ProjectFeedback,
+			Summary:         job.Result.OverallSummary,
+		}
+		if redactor != nil {
+			feedback[i].CVFeedback = redactor.Redact(feedback[i].CVFeedback)
+			feedback[i].ProjectFeedback = redactor.Redact(feedback[i].ProjectFeedback)
+			feedback[i].Summary = redactor.Redact(feedback[i].Summary)
+		}
+		// One blind evaluation makes the whole comparison blind
+		opts.Blind = opts.Blind || job.Blind
+	}
+
+	text, err := s.llmClient.CompareCandidates(llm.WithUsageScope(ctx, tenantID, nil), result.JobTitle, feedback, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare candidates: %w", err)
+	}
+	if redactor != nil {
+		text = redactor.Restore(text)
+	}
+	result.Comparison = text
+	return result, nil
+}
+
+// redactorFor returns a redactor primed with the candidates' names, or nil
+// when the tenant doesn't redact PII
+func (s *RankingService) redactorFor(ctx context.Context, tenantID string, jobs []*domain.EvaluationJob) *redact.Redactor {
+	tenant, err := s.tenantRepo.GetByID(tenantID)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load tenant settings, redacting by default", logging.Err(err))
+		tenant = domain.DefaultTenant(tenantID)
+	}
+	if !tenant.RedactPII {
+		return nil
+	}
+
+	redactor := redact.New()
+	for _, job := range jobs {
+		if doc, err := s.docRepo.GetByID(job.CVID); err == nil && doc.Profile != nil && doc.Profile.Contact.Name != "" {
+			redactor.AddNames(doc.Profile.Contact.Name)
+		}
+	}
+	return redactor
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adyutaa/parsea/internal/domain"
+	"github.com/adyutaa/parsea/internal/repository"
+	"github.com/adyutaa/parsea/pkg/pdf"
+)
+
+// ErrReportNotReady is returned for a report on a job that hasn't completed
+var ErrReportNotReady = errors.New("evaluation is not completed")
+
+// exportFlushRows is how many CSV rows are buffered before flushing to the client
+const exportFlushRows = 100
+
+// ExportColumns is the header row of the CSV export
+var ExportColumns = []string{
+	"id", "job_title", "status", "priority", "blind", "batch_id",
+	"cv_id", "candidate", "report_id",
+	"cv_match_rate", "project_score", "combined_score", "recommendation",
+	"cv_feedback", "project_feedback", "overall_summary", "error_message",
+	"run_at", "created_at", "updated_at", "erased_at",
+}
+
+type ReportService struct {
+	evalRepo *repository.EvaluationRepository
+	docRepo  *repository.DocumentRepository
+}
+
+func NewReportService(evalRepo *repository.EvaluationRepository, docRepo *repository.DocumentRepository) *ReportService {
+	return &ReportService{evalRepo: evalRepo, docRepo: docRepo}
+}
+
+// BuildPDF lays out the report of a completed job: headline scores, the
+// per-criterion breakdown, feedback and summary
+func (s *ReportService) BuildPDF(id, tenantID string) (*pdf.Document, *domain.EvaluationJob, error) {
+	idUint, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid job ID format: %w", err)
+	}
+	job, err := s.evalRepo.GetByID(uint(idUint))
+	if err != nil {
+		return nil, nil, fmt.Errorf("job not found: %w", err)
+	}
+	if job.TenantID != tenantID {
+		return nil, nil, fmt.Errorf("job not found")
+	}
+	if job.Status != domain.JobCompleted || job.Result == nil {
+		return nil, job, fmt.Errorf("%w: job %d is %s", ErrReportNotReady, job.ID, job.Status)
+	}
+
+	candidate := fmt.Sprintf("CV #%d", job.CVID)
+	if job.ErasedAt != nil {
+		candidate = "Erased candidate"
+	} else if doc, err := s.docRepo.GetByID(job.CVID); err == nil {
+		candidate = doc.Filename
+	}
+	return renderReport(job, candidate), job, nil
+}
+
+func renderReport(job *domain.EvaluationJob, candidate string) *pdf.Document {
+	result := job.Result
+
+	doc := pdf.NewDocument(
+		fmt.Sprintf("Evaluation %d - %s", job.ID, job.JobTitle),
+		fmt.Sprintf("Evaluation %d - generated %s", job.ID, time.Now().UTC().Format("2006-01-02 15:04 MST")),
+	)
+	heading := pdf.Style{Size: 13, Bold: true, Color: pdf.Black}
+	body := pdf.Style{Size: 10, Color: pdf.Black}
+	muted := pdf.Style{Size: 9, Color: pdf.Gray}
+
+	doc.Text("Evaluation Report", pdf.Style{Size: 20, Bold: true, Color: pdf.Black})
+	doc.Space(4)
+	doc.Text(job.JobTitle, pdf.Style{Size: 13, Color: pdf.Gray})
+	doc.Space(10)
+
+	details := [][2]string{
+		{"Candidate", candidate},
+		{"Evaluation", fmt.Sprintf("#%d", job.ID)},
+		{"Completed", job.UpdatedAt.UTC().Format("2 January 2006, 15:04 MST")},
+	}
+	if job.Blind {
+		details = append(details, [2]string{"Mode", "Blind - protected attributes were removed before scoring"})
+	}
+	for _, d := range details {
+		doc.Row([]float64{0, 110}, []string{d[0], d[1]}, body)
+	}
+	doc.Space(6)
+	doc.Rule(pdf.LightGray)
+	doc.Space(10)
+
+	combined := domain.CombinedScore(result.CVMatchRate, result.ProjectScore)
+	recommendation := result.Recommendation
+	if recommendation == "" {
+		recommendation = domain.RecommendationFor(result.CVMatchRate, result.ProjectScore)
+	}
+	doc.Text("Scores", heading)
+	doc.Space(4)
+	doc.Bar("CV match rate", result.CVMatchRate, 1, fmt.Sprintf("%.0f%%", result.CVMatchRate*100), scoreColor(result.CVMatchRate))
+	doc.Bar("Project score", result.ProjectScore, 5, fmt.Sprintf("%.1f / 5", result.ProjectScore), scoreColor((result.ProjectScore-1)/4))
+	doc.Bar("Combined score", combined, 1, fmt.Sprintf("%.2f", combined), scoreColor(combined))
+	doc.Space(4)
+	doc.Row([]float64{0, 190}, []string{"Recommendation", recommendationLabel(recommendation)}, pdf.Style{Size: 11, Bold: true, Color: pdf.Black})
+	doc.Space(12)
+
+	doc.Text("Breakdown by Criterion", heading)
+	doc.Space(4)
+	if len(result.CVCriteria) == 0 && len(result.ProjectCriteria) == 0 {
+		doc.Text("Criterion scores were not recorded for this evaluation; it predates them. The headline scores above cover the CV and the project as a whole.", muted)
+	}
+	writeCriteria(doc, "CV", domain.CVRubric, result.CVCriteria)
+	writeCriteria(doc, "Project", domain.ProjectRubric, result.ProjectCriteria)
+	doc.Space(12)
+
+	for _, section := range []struct{ title, text string }{
+		{"CV Feedback", result.CVFeedback},
+		{"Project Feedback", result.ProjectFeedback},
+		{"Overall Summary", result.OverallSummary},
+	} {
+		if strings.TrimSpace(section.text) == "" {
+			continue
+		}
+		doc.Text(section.title, heading)
+		doc.Space(4)
+		doc.Text(section.text, body)
+		doc.Space(12)
+	}
+	return doc
+}
+
+func writeCriteria(doc *pdf.Document, title string, rubric []domain.Criterion, scores domain.CriterionScores) {
+	if len(scores) == 0 {
+		return
+	}
+	doc.Space(4)
+	doc.Text(title, pdf.Style{Size: 10, Bold: true, Color: pdf.Gray})
+	for _, c := range rubric {
+		score, ok := scores[c.Key]
+		if !ok {
+			continue
+		}
+		doc.Bar(c.Label, score, 5, fmt.Sprintf("%.1f / 5", score), scoreColor((score-1)/4))
+	}
+}
+
+// scoreColor shades a 0-1 score from red through amber to green
+func scoreColor(score float64) pdf.Color {
+	switch {
+	case score >= 0.7:
+		return pdf.Color{R: 0.18, G: 0.55, B: 0.34}
+	case score >= 0.4:
+		return pdf.Color{R: 0.9, G: 0.6, B: 0.1}
+	default:
+		return pdf.Color{R: 0.8, G: 0.25, B: 0.2}
+	}
+}
+
+func recommendationLabel(r domain.Recommendation) string {
+	switch r {
+	case domain.RecommendationStrongHire:
+		return "Strong hire"
+	case domain.RecommendationHire:
+		return "Hire"
+	case domain.RecommendationMaybe:
+		return "Maybe"
+	case domain.RecommendationNoHire:
+		return "No hire"
+	}
+	return string(r)
+}
+
+// ExportCSV writes the jobs matching filter as CSV, header first, flushing
+// every few rows so the export streams to the client as it is read
+func (s *ReportService) ExportCSV(ctx context.Context, w io.Writer, filter domain.JobFilter, flush func()) (rows int, err error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(ExportColumns); err != nil {
+		return 0, err
+	}
+
+	err = s.evalRepo.EachForExport(ctx, filter, func(row *domain.JobExportRow) error {
+		if err := cw.Write(exportRecord(row)); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushRows == 0 {
+			cw.Flush()
+			if err := cw.Error(); err != nil {
+				return err
+			}
+			if flush != nil {
+				flush()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		// Rows still buffered are dropped, so an export that fails early
+		// writes nothing and the caller can still report the error
+		return rows, err
+	}
+	cw.Flush()
+	return rows, cw.Error()
+}
+
+func exportRecord(row *domain.JobExportRow) []string {
+	combined := ""
+	if row.CVMatchRate != nil && row.ProjectScore != nil {
+		combined = strconv.FormatFloat(domain.CombinedScore(*row.CVMatchRate, *row.ProjectScore), 'f', 3, 64)
+	}
+	return []string{
+		strconv.FormatUint(uint64(row.ID), 10),
+		csvSafe(row.JobTitle),
+		string(row.Status),
+		string(row.Priority),
+		strconv.FormatBool(row.Blind),
+		formatUintPtr(row.BatchID),
+		formatUintPtr(row.CVID),
+		csvSafe(row.Candidate),
+		formatUintPtr(row.ReportID),
+		formatFloatPtr(row.CVMatchRate),
+		formatFloatPtr(row.ProjectScore),
+		combined,
+		string(row.Recommendation),
+		csvSafe(row.CVFeedback),
+		csvSafe(row.ProjectFeedback),
+		csvSafe(row.OverallSummary),
+		csvSafe(row.ErrorMessage),
+		formatTimePtr(row.RunAt),
+		row.CreatedAt.UTC().Format(time.RFC3339),
+		row.UpdatedAt.UTC().Format(time.RFC3339),
+		formatTimePtr(row.ErasedAt),
+	}
+}
+
+// csvSafe stops spreadsheet apps from running free text, such as a file
+// name or LLM feedback, as a formula
+func csvSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func formatFloatPtr(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
+
+func formatUintPtr(u *uint) string {
+	if u == nil {
+		return ""
+	}
+	return strconv.FormatUint(uint64(*u), 10)
+}
+
+func formatTimePtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
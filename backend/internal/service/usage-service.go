@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/adyutaa/parsea/internal/domain"
+	"github.com/adyutaa/parsea/internal/logging"
+	"github.com/adyutaa/parsea/internal/repository"
+)
+
+type UsageService struct {
+	repo *repository.UsageRepository
+}
+
+func NewUsageService(repo *repository.UsageRepository) *UsageService {
+	return &UsageService{repo: repo}
+}
+
+// Record stores the usage of an LLM call; it is registered as the LLM client's
+// usage recorder and never fails the call it accounts for
+func (s *UsageService) Record(ctx context.Context, usage *domain.LLMUsage) {
+	if err := s.repo.Create(usage); err != nil {
+		slog.ErrorContext(ctx, "failed to record LLM usage",
+			"operation", usage.Operation,
+			"total_tokens", usage.TotalTokens,
+			logging.Err(err),
+		)
+	}
+}
+
+// GetJobUsage returns the aggregated usage of an evaluation job
+func (s *UsageService) GetJobUsage(jobID uint) (*domain.UsageSummary, error) {
+	return s.repo.SummaryForJob(jobID)
+}
+
+// GetReport returns a tenant's usage in [from, to) broken down by model and operation
+func (s *UsageService) GetReport(tenantID string, from, to time.Time) (*domain.UsageReport, error) {
+	if !to.After(from) {
+		return nil, fmt.Errorf("'to' must be after 'from'")
+	}
+
+	total, err := s.repo.SummaryForTenant(tenantID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarise usage: %w", err)
+	}
+
+	breakdown, err := s.repo.BreakdownForTenant(tenantID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to break down usage: %w", err)
+	}
+
+	jobs, err := s.repo.CountJobsForTenant(tenantID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count jobs: %w", err)
+	}
+
+	return &domain.UsageReport{
+		TenantID:  tenantID,
+		From:      from,
+		To:        to,
+		Total:     *total,
+		Jobs:      jobs,
+		Breakdown: breakdown,
+	}, nil
+}
+
+// MonthStart returns the first instant of t's month in UTC
+func MonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
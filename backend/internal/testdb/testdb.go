@@ -0,0 +1,71 @@
+// Package testdb gives tests a migrated PostgreSQL database. Tests that use
+// it are skipped unless TEST_DATABASE_URL points at a database they may
+// write to, e.g. the one from docker-compose.
+package testdb
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/adyutaa/parsea/internal/migrate"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// EnvVar names the connection string of the test database
+const EnvVar = "TEST_DATABASE_URL"
+
+var (
+	migrateOnce sync.Once
+	migrateErr  error
+)
+
+// Open connects to the test database and brings its schema up to date,
+// skipping the test when EnvVar isn't set
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	url := os.Getenv(EnvVar)
+	if url == "" {
+		t.Skipf("%s not set; skipping test that needs PostgreSQL", EnvVar)
+	}
+
+	db, err := gorm.Open(postgres.Open(url), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("connect to test database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("connect to test database: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	migrateOnce.Do(func() {
+		migrator, err := migrate.New(sqlDB)
+		if err != nil {
+			migrateErr = err
+			return
+		}
+		_, migrateErr = migrator.Up(context.Background())
+	})
+	if migrateErr != nil {
+		t.Fatalf("migrate test database: %v", migrateErr)
+	}
+	return db
+}
+
+// Tx opens the test database and returns a transaction that is rolled back
+// when the test ends, so tests leave no rows behind
+func Tx(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	tx := Open(t).Begin()
+	if tx.Error != nil {
+		t.Fatalf("begin transaction: %v", tx.Error)
+	}
+	t.Cleanup(func() { tx.Rollback() })
+	return tx
+}
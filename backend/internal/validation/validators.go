@@ -2,10 +2,13 @@ package validation
 
 import (
 	"fmt"
-	"path/filepath"
+	"mime"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/adyutaa/parsea/pkg/extract"
 )
 
 func IsValidID(s string) bool {
@@ -48,6 +51,28 @@ func ValidateJobTitle(jobTitle string) error {
 	return nil
 }
 
+// MaxScheduleAhead bounds how far in the future an evaluation can be scheduled
+const MaxScheduleAhead = 90 * 24 * time.Hour
+
+// ParseRunAt parses an optional RFC 3339 run_at. Times in the past mean
+// "now" and return nil, so clients with a skewed clock aren't rejected.
+func ParseRunAt(raw string, now time.Time) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	runAt, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, fmt.Errorf("run_at must be an RFC 3339 timestamp, e.g. 2025-01-31T22:00:00Z")
+	}
+	if !runAt.After(now) {
+		return nil, nil
+	}
+	if runAt.Sub(now) > MaxScheduleAhead {
+		return nil, fmt.Errorf("run_at cannot be more than %d days ahead", int(MaxScheduleAhead.Hours()/24))
+	}
+	return &runAt, nil
+}
+
 func ValidateFilename(filename string) error {
 	if filename == "" {
 		return fmt.Errorf("filename is required")
@@ -57,38 +82,77 @@ func ValidateFilename(filename string) error {
 		return fmt.Errorf("filename too long (max 255 characters)")
 	}
 
-	ext := strings.ToLower(filepath.Ext(filename))
-	if ext != ".pdf" {
-		return fmt.Errorf("only PDF files are allowed")
+	if _, err := extract.MimeTypeFromFilename(filename); err != nil {
+		return err
 	}
 
 	return nil
 }
 
-func ValidateFileSize(size int64) error {
-	const maxSize = 10 * 1024 * 1024
-
+func ValidateFileSize(size, maxSize int64) error {
 	if size <= 0 {
 		return fmt.Errorf("file is empty")
 	}
 
 	if size > maxSize {
-		return fmt.Errorf("file size exceeds 10MB limit")
+		return fmt.Errorf("file size exceeds %s limit", formatBytes(maxSize))
 	}
 
 	return nil
 }
 
-func ValidateMimeType(mimeType string) error {
+// genericMimeTypes are what browsers and curl declare for files they don't
+// recognise, e.g. .md and sometimes .docx; the extension decides instead
+var genericMimeTypes = map[string]bool{
+	"application/octet-stream":     true,
+	"binary/octet-stream":          true,
+	"application/zip":              true,
+	"application/x-zip-compressed": true,
+}
+
+// ValidateMimeType checks the Content-Type declared for an uploaded part. A
+// missing or generic type falls back to the filename's extension; the
+// content itself is checked against the extension when the file is saved.
+func ValidateMimeType(mimeType, filename string) error {
 	allowedTypes := []string{
-		"application/pdf",
+		extract.MimePDF,
+		extract.MimeDOCX,
+		extract.MimeText,
+		extract.MimeMarkdown,
+		"text/x-markdown",
+	}
+
+	if mimeType == "" {
+		_, err := extract.MimeTypeFromFilename(filename)
+		return err
+	}
+
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return fmt.Errorf("invalid file type: %s", mimeType)
+	}
+	if genericMimeTypes[mediaType] {
+		_, err := extract.MimeTypeFromFilename(filename)
+		return err
 	}
 
 	for _, allowed := range allowedTypes {
-		if mimeType == allowed {
+		if mediaType == allowed {
 			return nil
 		}
 	}
 
 	return fmt.Errorf("invalid file type: %s", mimeType)
 }
+
+// formatBytes renders a size limit the way users expect to read it, e.g. "10MB"
+func formatBytes(n int64) string {
+	const mb = 1024 * 1024
+	if n >= mb && n%mb == 0 {
+		return fmt.Sprintf("%dMB", n/mb)
+	}
+	if n >= 1024 && n%1024 == 0 {
+		return fmt.Sprintf("%dKB", n/1024)
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
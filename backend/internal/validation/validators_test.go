@@ -0,0 +1,29 @@
+package validation
+
+import "testing"
+
+func TestValidateMimeType(t *testing.T) {
+	tests := []struct {
+		mimeType string
+		filename string
+		wantErr  bool
+	}{
+		{"application/pdf", "cv.pdf", false},
+		{"text/markdown; charset=utf-8", "cv.md", false},
+		{"", "cv.md", false},
+		{"application/octet-stream", "cv.md", false},
+		{"application/octet-stream", "cv.docx", false},
+		{"application/zip", "cv.docx", false},
+		{"application/octet-stream", "cv.exe", true},
+		{"", "cv", true},
+		{"image/png", "cv.pdf", true},
+		{"not a type", "cv.pdf", true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateMimeType(tt.mimeType, tt.filename)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateMimeType(%q, %q) = %v, want error %v", tt.mimeType, tt.filename, err, tt.wantErr)
+		}
+	}
+}
@@ -2,54 +2,136 @@ package worker
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/adyutaa/parsea/internal/domain"
 	"github.com/adyutaa/parsea/internal/infrastructure/llm"
+	"github.com/adyutaa/parsea/internal/logging"
+	"github.com/adyutaa/parsea/internal/metrics"
+	"github.com/adyutaa/parsea/internal/queue"
 	"github.com/adyutaa/parsea/internal/repository"
 	"github.com/adyutaa/parsea/internal/service"
+	"github.com/adyutaa/parsea/internal/tracing"
+	"github.com/adyutaa/parsea/pkg/extract"
 	"github.com/adyutaa/parsea/pkg/pdf"
+	"github.com/adyutaa/parsea/pkg/redact"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Pipeline step names, used as metric labels and span names
+const (
+	stepExtractCV        = "extract_cv"
+	stepExtractProfile   = "extract_profile"
+	stepJobContext       = "job_context"
+	stepEvaluateCV       = "evaluate_cv"
+	stepExtractReport    = "extract_report"
+	stepCaseStudyContext = "case_study_context"
+	stepEvaluateProject  = "evaluate_project"
+	stepGenerateSummary  = "generate_summary"
+)
+
+const (
+	// HeartbeatKeyPrefix namespaces the Redis keys workers refresh while running
+	HeartbeatKeyPrefix = "worker:heartbeat:"
+	// HeartbeatInterval is how often a running worker refreshes its key
+	HeartbeatInterval = 5 * time.Second
 )
 
 type EvaluationWorker struct {
+	id             string
 	redis          *redis.Client
 	evalRepo       *repository.EvaluationRepository
 	docRepo        *repository.DocumentRepository
+	tenantRepo     *repository.TenantRepository
 	llmClient      *llm.OpenAIService
 	contextService *service.ContextService
-	pdfParser      *pdf.Parser
+	extractors     *extract.Registry
+	jobTimeout     time.Duration
+	queueWeights   queue.Weights
+	// skipQueues are consumed elsewhere, e.g. the bulk queue in batch mode
+	skipQueues map[string]bool
 }
 
 func NewEvaluationWorker(
 	redis *redis.Client,
 	evalRepo *repository.EvaluationRepository,
 	docRepo *repository.DocumentRepository,
+	tenantRepo *repository.TenantRepository,
 	llmClient *llm.OpenAIService,
 	contextService *service.ContextService,
+	extractors *extract.Registry,
+	jobTimeout time.Duration,
+	queueWeights queue.Weights,
 ) *EvaluationWorker {
+	hostname, _ := os.Hostname()
 	return &EvaluationWorker{
+		// The random suffix keeps a restarted container, which often has
+		// the same hostname and PID, from looking like the worker it replaced
+		id:             fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), rand.Text()[:6]),
 		redis:          redis,
 		evalRepo:       evalRepo,
 		docRepo:        docRepo,
+		tenantRepo:     tenantRepo,
 		llmClient:      llmClient,
 		contextService: contextService,
-		pdfParser:      pdf.NewParser(),
+		extractors:     extractors,
+		jobTimeout:     jobTimeout,
+		queueWeights:   queueWeights,
+		skipQueues:     map[string]bool{},
 	}
 }
 
+// SkipQueue stops the worker from popping the named queue. Call it before Start.
+func (w *EvaluationWorker) SkipQueue(name string) {
+	w.skipQueues[name] = true
+}
+
+// popOrder returns the queues for the next pop, without skipped queues
+func (w *EvaluationWorker) popOrder() []string {
+	order := w.queueWeights.PopOrder()
+	kept := order[:0]
+	for _, name := range order {
+		if !w.skipQueues[name] {
+			kept = append(kept, name)
+		}
+	}
+	return kept
+}
+
+// ID identifies this worker process
+func (w *EvaluationWorker) ID() string {
+	return w.id
+}
+
 // Start begins processing jobs from the queue
 func (w *EvaluationWorker) Start(ctx context.Context) {
-	log.Println("🔄 Worker started, waiting for jobs...")
+	var queues []string
+	for _, name := range []string{queue.HighQueue, queue.EvaluationQueue, queue.BulkQueue} {
+		if !w.skipQueues[name] {
+			queues = append(queues, name)
+		}
+	}
+	slog.InfoContext(ctx, "worker started, waiting for jobs",
+		"queues", queues,
+		"weights", w.queueWeights,
+		"worker_id", w.id,
+	)
+	go w.heartbeat(ctx)
 
 	for {
 		select {
 		case <-ctx.Done():
-			log.Println("👋 Worker shutting down...")
+			slog.InfoContext(ctx, "worker shutting down")
 			return
 		default:
 			w.processNextJob(ctx)
@@ -57,12 +139,70 @@ func (w *EvaluationWorker) Start(ctx context.Context) {
 	}
 }
 
+// heartbeat refreshes the worker's key until ctx is cancelled, so readiness
+// checks can tell whether any worker is alive
+func (w *EvaluationWorker) heartbeat(ctx context.Context) {
+	key := HeartbeatKeyPrefix + w.id
+	ticker := time.NewTicker(HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now().UTC().Format(time.RFC3339Nano)
+		if err := w.redis.Set(ctx, key, now, 3*HeartbeatInterval).Err(); err != nil && ctx.Err() == nil {
+			slog.WarnContext(ctx, "failed to write worker heartbeat", logging.Err(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			w.redis.Del(context.Background(), key)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Heartbeats returns the last heartbeat of every live worker, keyed by worker ID
+func Heartbeats(ctx context.Context, rdb *redis.Client) (map[string]time.Time, error) {
+	var keys []string
+	iter := rdb.Scan(ctx, 0, HeartbeatKeyPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	beats := make(map[string]time.Time, len(keys))
+	if len(keys) == 0 {
+		return beats, nil
+	}
+
+	values, err := rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			continue // expired between SCAN and MGET
+		}
+		at, err := time.Parse(time.RFC3339Nano, str)
+		if err != nil {
+			continue
+		}
+		beats[strings.TrimPrefix(keys[i], HeartbeatKeyPrefix)] = at
+	}
+
+	return beats, nil
+}
+
 func (w *EvaluationWorker) processNextJob(ctx context.Context) {
-	// Block and wait for job (timeout 1 second for faster shutdown)
-	result, err := w.redis.BRPop(ctx, 1*time.Second, "evaluation_queue").Result()
+	// Block and wait for job (timeout 1 second for faster shutdown), checking
+	// the priority queues in a weighted order so bulk work can't starve
+	result, err := w.redis.BRPop(ctx, 1*time.Second, w.popOrder()...).Result()
 	if err != nil {
 		if err.Error() != "redis: nil" && err != context.Canceled {
-			log.Printf("⚠️  Failed to pop from queue: %v\n", err)
+			slog.ErrorContext(ctx, "failed to pop from queue", logging.Err(err))
 		}
 		return
 	}
@@ -71,179 +211,434 @@ func (w *EvaluationWorker) processNextJob(ctx context.Context) {
 		return
 	}
 
-	jobID := result[1]
-	log.Printf("\n" + strings.Repeat("=", 60))
-	log.Printf("📋 Processing job: %s", jobID)
-	log.Printf(strings.Repeat("=", 60) + "\n")
+	msg, err := queue.Decode(result[1])
+	if err != nil {
+		slog.ErrorContext(ctx, "dropping malformed queue message", "payload", result[1], logging.Err(err))
+		return
+	}
+
+	jobID := msg.JobIDString()
 
-	// Process the job with timeout
-	jobCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	// Process the job with timeout, continuing the trace and request that enqueued it
+	jobCtx, cancel := context.WithTimeout(logging.WithJobID(msg.Context(context.Background()), jobID), w.jobTimeout)
 	defer cancel()
+	// Shutdown stops the job too, e.g. an OCR run that would outlive the process
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	jobCtx, span := tracing.Start(jobCtx, "evaluation.process",
+		attribute.String("job.id", jobID),
+		attribute.String("queue.name", result[0]),
+	)
+	if !msg.EnqueuedAt.IsZero() {
+		span.SetAttributes(attribute.Float64("queue.wait_seconds", time.Since(msg.EnqueuedAt).Seconds()))
+	}
+	slog.InfoContext(jobCtx, "processing job", "queue", result[0])
 
-	if err := w.processJob(jobCtx, jobID); err != nil {
-		log.Printf("❌ Job %s failed: %v\n", jobID, err)
-		w.evalRepo.UpdateError(jobID, err.Error())
+	job, err := w.evalRepo.GetByID(msg.JobID)
+	if err != nil {
+		slog.ErrorContext(jobCtx, "failed to load job", logging.Err(err))
+		tracing.End(span, err)
+		return
+	}
+
+	// Claim the job. The outbox delivers at least once, so the same job can
+	// arrive twice; the guarded transition fails if it already finished or
+	// another worker has it, which deduplicates by job ID.
+	if err := w.evalRepo.MarkProcessing(job, w.id); err != nil {
+		if errors.Is(err, domain.ErrInvalidTransition) || errors.Is(err, domain.ErrJobConflict) {
+			slog.InfoContext(jobCtx, "skipping duplicate delivery", "status", job.Status, logging.Err(err))
+			tracing.End(span, nil)
+			return
+		}
+		slog.ErrorContext(jobCtx, "failed to claim job", logging.Err(err))
+		tracing.End(span, err)
+		return
+	}
+
+	err = w.processJob(jobCtx, job)
+	if err != nil && ctx.Err() != nil {
+		// Interrupted by shutdown: the job stays processing and the reaper
+		// requeues it once this worker's heartbeat is gone
+		slog.WarnContext(jobCtx, "job interrupted by shutdown", logging.Err(err))
+	} else if err != nil {
+		w.fail(jobCtx, job, err)
 	} else {
-		log.Printf("\n✅ Job %s completed successfully!\n", jobID)
+		slog.InfoContext(jobCtx, "job completed")
+		metrics.JobsTotal.WithLabelValues(string(domain.JobCompleted)).Inc()
 	}
+	tracing.End(span, err)
 }
 
-func (w *EvaluationWorker) processJob(ctx context.Context, jobID string) error {
-	// Update status to processing
-	if err := w.evalRepo.UpdateStatus(jobID, "processing"); err != nil {
-		return fmt.Errorf("failed to update status: %w", err)
+// fail records a job as failed with err as its message
+func (w *EvaluationWorker) fail(ctx context.Context, job *domain.EvaluationJob, err error) {
+	slog.ErrorContext(ctx, "job failed", logging.Err(err))
+	if failErr := w.evalRepo.MarkFailed(job, err.Error(), w.id); failErr != nil {
+		slog.ErrorContext(ctx, "failed to record job failure", logging.Err(failErr))
 	}
+	metrics.JobsTotal.WithLabelValues(string(domain.JobFailed)).Inc()
+}
+
+// preparedJob holds a job's prompt inputs once its documents have been
+// extracted, redacted and paired with their RAG context
+type preparedJob struct {
+	cvInput     string
+	jobContext  string
+	reportText  string
+	caseContext string
+	// redactor restores PII in the model's answers; nil when not redacting
+	redactor *redact.Redactor
+}
+
+// scopeJob attributes the LLM calls and log lines made with ctx to the job
+// and its tenant
+func scopeJob(ctx context.Context, job *domain.EvaluationJob) context.Context {
+	ctx = llm.WithUsageScope(ctx, job.TenantID, &job.ID)
+	ctx = logging.WithTenant(ctx, job.TenantID)
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("tenant.id", job.TenantID),
+		attribute.Bool("job.blind", job.Blind),
+	)
+	return ctx
+}
+
+func (w *EvaluationWorker) processJob(ctx context.Context, job *domain.EvaluationJob) error {
+	ctx = scopeJob(ctx, job)
 
-	// Convert string jobID to uint
-	jobIDUint, err := strconv.ParseUint(jobID, 10, 32)
+	prepared, err := w.prepare(ctx, job)
 	if err != nil {
-		return fmt.Errorf("invalid job ID format: %w", err)
+		return err
 	}
 
-	// Get job details
-	job, err := w.evalRepo.GetByID(uint(jobIDUint))
+	// ========================================
+	// STEP 4: Evaluate CV with LLM
+	// ========================================
+	stepCtx, endStep := startStep(ctx, stepEvaluateCV)
+	cvResult, err := w.llmClient.EvaluateCV(stepCtx, prepared.cvInput, prepared.jobContext, llm.EvaluationOptions{Blind: job.Blind})
+	endStep(err)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate CV: %w", err)
+	}
+	slog.InfoContext(ctx, "CV evaluated", "match_rate", cvResult.MatchRate)
+
+	// ========================================
+	// STEP 7: Evaluate Project with LLM
+	// ========================================
+	stepCtx, endStep = startStep(ctx, stepEvaluateProject)
+	projectResult, err := w.llmClient.EvaluateProject(stepCtx, prepared.reportText, prepared.caseContext)
+	endStep(err)
 	if err != nil {
-		return fmt.Errorf("failed to get job: %w", err)
+		return fmt.Errorf("failed to evaluate project: %w", err)
 	}
+	slog.InfoContext(ctx, "project evaluated", "score", projectResult.Score)
+
+	// ========================================
+	// STEP 8: Generate final summary
+	// ========================================
+	stepCtx, endStep = startStep(ctx, stepGenerateSummary)
+	summary, err := w.llmClient.GenerateSummary(
+		stepCtx,
+		cvResult.Feedback,
+		projectResult.Feedback,
+		cvResult.MatchRate,
+		projectResult.Score,
+	)
+	endStep(err)
+	if err != nil {
+		return fmt.Errorf("failed to generate summary: %w", err)
+	}
+
+	return w.complete(job, prepared.redactor, cvResult, projectResult, summary)
+}
+
+// prepare runs the steps before the LLM evaluations: text extraction, PII
+// redaction, profile extraction and RAG context. The batch runner shares it.
+func (w *EvaluationWorker) prepare(ctx context.Context, job *domain.EvaluationJob) (*preparedJob, error) {
+	jobID := strconv.FormatUint(uint64(job.ID), 10)
 
 	// Get CV document
 	cvDoc, err := w.docRepo.GetByID(job.CVID)
 	if err != nil {
-		return fmt.Errorf("failed to get CV document: %w", err)
+		return nil, fmt.Errorf("failed to get CV document: %w", err)
 	}
 
 	// Get Project Report document
 	reportDoc, err := w.docRepo.GetByID(job.ReportID)
 	if err != nil {
-		return fmt.Errorf("failed to get report document: %w", err)
+		return nil, fmt.Errorf("failed to get report document: %w", err)
 	}
 
 	// ========================================
 	// STEP 1: Extract text from CV
 	// ========================================
-	log.Println("📄 [1/7] Extracting text from CV...")
-	cvText, err := w.pdfParser.ExtractText(cvDoc.FilePath)
+	stepCtx, endStep := startStep(ctx, stepExtractCV)
+	cvText, err := w.extractText(stepCtx, cvDoc)
+	endStep(err)
 	if err != nil {
-		return fmt.Errorf("failed to extract CV text: %w", err)
+		return nil, fmt.Errorf("failed to extract CV text: %w", err)
+	}
+
+	// Redact PII before any CV text leaves the process, if the tenant requires it
+	var redactor *redact.Redactor
+	tenant, err := w.tenantRepo.GetByID(job.TenantID)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load tenant settings, redacting by default", logging.Err(err))
+		tenant = domain.DefaultTenant(job.TenantID)
+	}
+	if tenant.RedactPII {
+		names := []string{redact.GuessName(cvText)}
+		if cvDoc.Profile != nil {
+			names = append(names, cvDoc.Profile.Contact.Name)
+		}
+		redactor = redact.New(names...)
+		cvText = redactor.Redact(cvText)
+		slog.InfoContext(ctx, "PII redacted from CV")
 	}
-	cvText = w.pdfParser.CleanText(cvText)
-	log.Printf("   ✅ Extracted %d characters from CV\n", len(cvText))
 
 	// ========================================
-	// STEP 2: Get job requirements context (RAG!)
+	// STEP 2: Extract structured candidate profile
 	// ========================================
-	log.Println("\n🔍 [2/7] Retrieving job requirements context (RAG)...")
+	stepCtx, endStep = startStep(ctx, stepExtractProfile)
+	profile := cvDoc.Profile
+	if profile == nil {
+		profile, err = w.llmClient.ExtractProfile(stepCtx, cvText)
+		if err == nil && redactor != nil {
+			profile, err = restoreProfile(redactor, profile)
+		}
+		if err != nil {
+			slog.WarnContext(ctx, "profile extraction failed, continuing without it", logging.Err(err))
+		} else if err := w.docRepo.UpdateProfile(cvDoc.ID, profile); err != nil {
+			slog.ErrorContext(ctx, "failed to save profile", logging.Err(err))
+		} else {
+			slog.InfoContext(ctx, "profile saved", "years_experience", profile.YearsExperience, "skills", len(profile.Skills))
+		}
+	} else {
+		slog.InfoContext(ctx, "using stored profile")
+	}
+	endStep(nil)
+
+	// The profile may surface a name the first-line heuristic missed
+	if redactor != nil && profile != nil && profile.Contact.Name != "" {
+		redactor.AddNames(profile.Contact.Name)
+		cvText = redactor.Redact(cvText)
+	}
+	slog.DebugContext(ctx, "CV text prepared", logging.Content("text", cvText))
+
+	// ========================================
+	// STEP 3: Get job requirements context (RAG!)
+	// ========================================
+	stepCtx, endStep = startStep(ctx, stepJobContext)
 	var jobContext string
 	if w.contextService != nil {
-		jobContext, err = w.contextService.GetJobRequirementsContext(ctx, job.JobTitle)
+		jobContext, err = w.contextService.GetJobRequirementsContext(stepCtx, job.JobTitle)
 		if err != nil {
-			log.Printf("   ⚠️  RAG failed, using fallback: %v\n", err)
+			slog.WarnContext(ctx, "RAG failed, using fallback context", logging.Err(err))
 			jobContext = service.GetHardcodedJobContext()
 		} else {
-			log.Println("   ✅ Retrieved context from vector database")
+			slog.InfoContext(ctx, "retrieved context from vector database")
 		}
 	} else {
-		log.Println("   ⚠️  No context service, using fallback")
+		slog.WarnContext(ctx, "no context service, using fallback context")
 		jobContext = service.GetHardcodedJobContext()
 	}
 
 	// Add CV scoring rubric
 	if w.contextService != nil {
-		cvRubric, _ := w.contextService.GetCVScoringContext(ctx)
+		cvRubric, _ := w.contextService.GetCVScoringContext(stepCtx)
 		jobContext += "\n\n" + cvRubric
 	} else {
 		jobContext += "\n\n" + service.GetCVScoringRubric()
 	}
+	endStep(nil)
 
-	// ========================================
-	// STEP 3: Evaluate CV with LLM
-	// ========================================
-	log.Println("\n🤖 [3/7] Evaluating CV with LLM...")
-	cvResult, err := w.llmClient.EvaluateCV(cvText, jobContext)
-	if err != nil {
-		return fmt.Errorf("failed to evaluate CV: %w", err)
+	// The CV prompt carries the structured profile alongside the text
+	cvInput := cvText
+	if profile != nil {
+		summary := profile.Summary()
+		if redactor != nil {
+			summary = redactor.Redact(summary)
+		}
+		cvInput += "\n\nSTRUCTURED PROFILE (derived from the CV above):\n" + summary
+	}
+
+	// Blind mode removes protected attributes so the model cannot weigh them
+	if job.Blind {
+		blindText, blindCounts := redact.Blind(cvInput)
+		cvInput = blindText
+
+		counts := domain.JSON{}
+		for attribute, n := range blindCounts {
+			counts[string(attribute)] = n
+		}
+		if err := w.evalRepo.UpdateBlindCounts(jobID, counts); err != nil {
+			slog.ErrorContext(ctx, "failed to record blind evaluation", logging.Err(err))
+		}
+		slog.InfoContext(ctx, "blind evaluation: protected attributes neutralised", "count", sumCounts(blindCounts))
 	}
-	log.Printf("   ✅ CV Match Rate: %.2f (%.0f%%)\n", cvResult.MatchRate, cvResult.MatchRate*100)
 
 	// ========================================
-	// STEP 4: Extract text from Project Report
+	// STEP 5: Extract text from Project Report
 	// ========================================
-	log.Println("\n📄 [4/7] Extracting text from Project Report...")
-	reportText, err := w.pdfParser.ExtractText(reportDoc.FilePath)
+	stepCtx, endStep = startStep(ctx, stepExtractReport)
+	reportText, err := w.extractText(stepCtx, reportDoc)
+	endStep(err)
 	if err != nil {
-		return fmt.Errorf("failed to extract report text: %w", err)
+		return nil, fmt.Errorf("failed to extract report text: %w", err)
+	}
+	if redactor != nil {
+		reportText = redactor.Redact(reportText)
+		slog.InfoContext(ctx, "PII redacted from report")
 	}
-	reportText = w.pdfParser.CleanText(reportText)
-	log.Printf("   ✅ Extracted %d characters from report\n", len(reportText))
+	slog.DebugContext(ctx, "report text prepared", logging.Content("text", reportText))
 
 	// ========================================
-	// STEP 5: Get case study context (RAG!)
+	// STEP 6: Get case study context (RAG!)
 	// ========================================
-	log.Println("\n🔍 [5/7] Retrieving case study context (RAG)...")
+	stepCtx, endStep = startStep(ctx, stepCaseStudyContext)
 	var caseContext string
 	if w.contextService != nil {
-		caseContext, err = w.contextService.GetCaseStudyContext(ctx)
+		caseContext, err = w.contextService.GetCaseStudyContext(stepCtx)
 		if err != nil {
-			log.Printf("   ⚠️  RAG failed, using fallback: %v\n", err)
+			slog.WarnContext(ctx, "RAG failed, using fallback context", logging.Err(err))
 			caseContext = service.GetHardcodedCaseStudyContext()
 		} else {
-			log.Println("   ✅ Retrieved context from vector database")
+			slog.InfoContext(ctx, "retrieved context from vector database")
 		}
 	} else {
-		log.Println("   ⚠️  No context service, using fallback")
+		slog.WarnContext(ctx, "no context service, using fallback context")
 		caseContext = service.GetHardcodedCaseStudyContext()
 	}
 
 	// Add project scoring rubric
 	if w.contextService != nil {
-		projectRubric, _ := w.contextService.GetProjectScoringContext(ctx)
+		projectRubric, _ := w.contextService.GetProjectScoringContext(stepCtx)
 		caseContext += "\n\n" + projectRubric
 	} else {
 		caseContext += "\n\n" + service.GetProjectScoringRubric()
 	}
+	endStep(nil)
 
-	// ========================================
-	// STEP 6: Evaluate Project with LLM
-	// ========================================
-	log.Println("\n🤖 [6/7] Evaluating Project with LLM...")
-	projectResult, err := w.llmClient.EvaluateProject(reportText, caseContext)
-	if err != nil {
-		return fmt.Errorf("failed to evaluate project: %w", err)
+	if redactor != nil {
+		counts := domain.JSON{}
+		for kind, n := range redactor.Counts() {
+			counts[strings.ToLower(string(kind))] = n
+		}
+		if err := w.evalRepo.UpdateRedaction(jobID, counts); err != nil {
+			slog.ErrorContext(ctx, "failed to record redaction counts", logging.Err(err))
+		}
 	}
-	log.Printf("   ✅ Project Score: %.1f/5.0\n", projectResult.Score)
 
-	// ========================================
-	// STEP 7: Generate final summary
-	// ========================================
-	log.Println("\n🤖 [7/7] Generating final summary...")
-	summary, err := w.llmClient.GenerateSummary(
-		cvResult.Feedback,
-		projectResult.Feedback,
-		cvResult.MatchRate,
-		projectResult.Score,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to generate summary: %w", err)
+	return &preparedJob{
+		cvInput:     cvInput,
+		jobContext:  jobContext,
+		reportText:  reportText,
+		caseContext: caseContext,
+		redactor:    redactor,
+	}, nil
+}
+
+// complete restores redacted values in the model's answers and saves the result
+func (w *EvaluationWorker) complete(job *domain.EvaluationJob, redactor *redact.Redactor, cvResult *domain.CVEvaluationResult, projectResult *domain.ProjectEvaluationResult, summary string) error {
+	// Put redacted values back so the stored feedback reads naturally
+	if redactor != nil {
+		cvResult.Feedback = redactor.Restore(cvResult.Feedback)
+		projectResult.Feedback = redactor.Restore(projectResult.Feedback)
+		summary = redactor.Restore(summary)
 	}
-	log.Println("   ✅ Summary generated")
 
-	// ========================================
-	// Save results
-	// ========================================
-	log.Println("\n💾 Saving results to database...")
 	result := &domain.EvaluationResult{
 		CVMatchRate:     cvResult.MatchRate,
 		CVFeedback:      cvResult.Feedback,
 		ProjectScore:    projectResult.Score,
 		ProjectFeedback: projectResult.Feedback,
 		OverallSummary:  summary,
+		Recommendation:  domain.RecommendationFor(cvResult.MatchRate, projectResult.Score),
+		CVCriteria:      cvResult.Criteria,
+		ProjectCriteria: projectResult.Criteria,
 	}
 
-	if err := w.evalRepo.UpdateResult(jobID, result); err != nil {
+	if err := w.evalRepo.MarkCompleted(job, result, w.id); err != nil {
 		return fmt.Errorf("failed to save results: %w", err)
 	}
 
-	log.Println("   ✅ Results saved")
-
 	return nil
 }
+
+// startStep opens a span for a pipeline step; the returned function records
+// the step duration and outcome, logs it and ends the span
+func startStep(ctx context.Context, step string) (context.Context, func(error)) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "step."+step)
+	slog.DebugContext(ctx, "step started", "step", step)
+	return ctx, func(err error) {
+		metrics.ObserveStep(step, start, err)
+		tracing.End(span, err)
+
+		duration := float64(time.Since(start).Microseconds()) / 1000
+		if err != nil {
+			slog.ErrorContext(ctx, "step failed", "step", step, "duration_ms", duration, logging.Err(err))
+			return
+		}
+		slog.InfoContext(ctx, "step finished", "step", step, "duration_ms", duration)
+	}
+}
+
+func sumCounts(counts map[redact.Attribute]int) int {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	return total
+}
+
+// restoreProfile puts redacted contact details back into an extracted profile
+func restoreProfile(redactor *redact.Redactor, profile *domain.CandidateProfile) (*domain.CandidateProfile, error) {
+	data, err := json.Marshal(profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal profile: %w", err)
+	}
+
+	var restored domain.CandidateProfile
+	if err := json.Unmarshal(redactor.RestoreJSON(data), &restored); err != nil {
+		return nil, fmt.Errorf("failed to restore profile: %w", err)
+	}
+	return &restored, nil
+}
+
+// extractText picks the extractor from the document's stored MIME type,
+// falling back to the filename for documents uploaded before it was recorded
+func (w *EvaluationWorker) extractText(ctx context.Context, doc *domain.Document) (string, error) {
+	mimeType := doc.MimeType
+	if mimeType == "" {
+		var err error
+		mimeType, err = extract.MimeTypeFromFilename(doc.Filename)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	result, err := w.extractors.Extract(ctx, mimeType, doc.FilePath)
+	if err != nil {
+		return "", err
+	}
+	metrics.ExtractionsTotal.WithLabelValues(mimeType, result.Strategy).Inc()
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("document.mime_type", mimeType),
+		attribute.String("extraction.strategy", result.Strategy),
+		attribute.Int("extraction.characters", len(result.Text)),
+	)
+
+	var confidence *float64
+	attrs := []any{"document_id", doc.ID, "mime_type", mimeType, "strategy", result.Strategy, "characters", len(result.Text)}
+	if result.Strategy == pdf.StrategyOCR {
+		confidence = &result.Confidence
+		attrs = append(attrs, "ocr_confidence", result.Confidence)
+	}
+	slog.InfoContext(ctx, "text extracted", attrs...)
+
+	if err := w.docRepo.UpdateExtraction(doc.ID, result.Strategy, confidence); err != nil {
+		slog.ErrorContext(ctx, "failed to record extraction strategy", logging.Err(err))
+	}
+
+	return result.Text, nil
+}
@@ -0,0 +1,477 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/adyutaa/parsea/internal/domain"
+	"github.com/adyutaa/parsea/internal/infrastructure/llm"
+	"github.com/adyutaa/parsea/internal/logging"
+	"github.com/adyutaa/parsea/internal/metrics"
+	"github.com/adyutaa/parsea/internal/queue"
+	"github.com/adyutaa/parsea/internal/repository"
+	"github.com/adyutaa/parsea/internal/tracing"
+	"github.com/adyutaa/parsea/pkg/redact"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// llmBatchTick is how often the runner checks the bulk queue and due batches
+const llmBatchTick = 5 * time.Second
+
+// LLMBatchRunner evaluates bulk-priority jobs through the provider's batch
+// API instead of one synchronous call per step. Each round it prepares up to
+// maxJobs queued jobs, submits their CV and project evaluations as one
+// batch, and once that finishes submits the summaries as a second batch.
+// Submitted batches are stored, so a restart resumes polling them.
+type LLMBatchRunner struct {
+	worker        *EvaluationWorker
+	redis         *redis.Client
+	evalRepo      *repository.EvaluationRepository
+	batchRepo     *repository.LLMBatchRepository
+	llmClient     *llm.OpenAIService
+	maxJobs       int
+	collectWindow time.Duration
+	pollInterval  time.Duration
+}
+
+// NewLLMBatchRunner builds a runner that shares the worker's pipeline. It
+// takes over the bulk queue from the worker.
+func NewLLMBatchRunner(worker *EvaluationWorker, batchRepo *repository.LLMBatchRepository, maxJobs int, collectWindow, pollInterval time.Duration) *LLMBatchRunner {
+	worker.SkipQueue(queue.BulkQueue)
+	return &LLMBatchRunner{
+		worker:        worker,
+		redis:         worker.redis,
+		evalRepo:      worker.evalRepo,
+		batchRepo:     batchRepo,
+		llmClient:     worker.llmClient,
+		maxJobs:       maxJobs,
+		collectWindow: collectWindow,
+		pollInterval:  pollInterval,
+	}
+}
+
+// Start submits and polls batches until ctx is cancelled
+func (r *LLMBatchRunner) Start(ctx context.Context) {
+	slog.InfoContext(ctx, "llm batch runner started",
+		"queue", queue.BulkQueue,
+		"max_jobs", r.maxJobs,
+		"collect_window", r.collectWindow.String(),
+		"poll_interval", r.pollInterval.String(),
+	)
+	ticker := time.NewTicker(llmBatchTick)
+	defer ticker.Stop()
+
+	for {
+		r.collect(ctx)
+		r.pollDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			slog.InfoContext(ctx, "llm batch runner shutting down")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// collect submits the bulk queue as batches once it holds maxJobs jobs or
+// its oldest job has waited collectWindow. Messages stay in Redis until
+// then, so nothing is held in memory across a restart.
+func (r *LLMBatchRunner) collect(ctx context.Context) {
+	for ctx.Err() == nil {
+		depth, err := r.redis.LLen(ctx, queue.BulkQueue).Result()
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to read bulk queue length", logging.Err(err))
+			return
+		}
+		if depth == 0 {
+			return
+		}
+		if depth < int64(r.maxJobs) {
+			// LPush adds at the head, so the oldest message is the last one
+			oldest, err := r.redis.LIndex(ctx, queue.BulkQueue, -1).Result()
+			if errors.Is(err, redis.Nil) {
+				return
+			}
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to read bulk queue", logging.Err(err))
+				return
+			}
+			if msg, err := queue.Decode(oldest); err == nil && time.Since(msg.EnqueuedAt) < r.collectWindow {
+				return
+			}
+		}
+
+		payloads, err := r.redis.RPopCount(ctx, queue.BulkQueue, r.maxJobs).Result()
+		if errors.Is(err, redis.Nil) {
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to pop bulk queue", logging.Err(err))
+			return
+		}
+		r.submitEvaluations(ctx, payloads)
+	}
+}
+
+// submitEvaluations claims and prepares the jobs of the given queue
+// messages, then submits their CV and project evaluations as one batch
+func (r *LLMBatchRunner) submitEvaluations(ctx context.Context, payloads []string) {
+	ctx, span := tracing.Start(ctx, "llm_batch.submit",
+		attribute.String("llm_batch.stage", string(domain.LLMBatchEvaluate)),
+		attribute.Int("llm_batch.messages", len(payloads)),
+	)
+	defer span.End()
+
+	var (
+		jobs     []*domain.EvaluationJob
+		requests []llm.BatchRequest
+		items    []domain.LLMBatchItem
+	)
+	for _, payload := range payloads {
+		job, prepared := r.prepare(ctx, payload)
+		if job == nil {
+			continue
+		}
+
+		customID := batchCustomID(job.ID)
+		requests = append(requests,
+			llm.CVEvaluationRequest(customID+":cv", prepared.cvInput, prepared.jobContext, llm.EvaluationOptions{Blind: job.Blind}),
+			llm.ProjectEvaluationRequest(customID+":project", prepared.reportText, prepared.caseContext),
+		)
+		state := &domain.LLMBatchState{}
+		if prepared.redactor != nil {
+			state.Redactions = prepared.redactor.Originals()
+		}
+		items = append(items, domain.LLMBatchItem{JobID: job.ID, State: state})
+		jobs = append(jobs, job)
+	}
+	if len(jobs) == 0 {
+		return
+	}
+
+	if err := r.submit(ctx, domain.LLMBatchEvaluate, requests, items); err != nil {
+		tracing.End(span, err)
+		for _, job := range jobs {
+			r.worker.fail(logging.WithJobID(ctx, batchJobID(job)), job, err)
+		}
+	}
+}
+
+// prepare claims the job of one queue message and runs the steps before the
+// LLM calls. It returns a nil job when the message is skipped or the job failed.
+func (r *LLMBatchRunner) prepare(ctx context.Context, payload string) (*domain.EvaluationJob, *preparedJob) {
+	msg, err := queue.Decode(payload)
+	if err != nil {
+		slog.ErrorContext(ctx, "dropping malformed queue message", "payload", payload, logging.Err(err))
+		return nil, nil
+	}
+
+	jobCtx, cancel := context.WithTimeout(logging.WithJobID(msg.Context(ctx), msg.JobIDString()), r.worker.jobTimeout)
+	defer cancel()
+	jobCtx, span := tracing.Start(jobCtx, "evaluation.prepare",
+		attribute.String("job.id", msg.JobIDString()),
+		attribute.String("queue.name", queue.BulkQueue),
+	)
+
+	job, err := r.evalRepo.GetByID(msg.JobID)
+	if err != nil {
+		slog.ErrorContext(jobCtx, "failed to load job", logging.Err(err))
+		tracing.End(span, err)
+		return nil, nil
+	}
+	if err := r.evalRepo.MarkProcessing(job, r.worker.id); err != nil {
+		if errors.Is(err, domain.ErrInvalidTransition) || errors.Is(err, domain.ErrJobConflict) {
+			slog.InfoContext(jobCtx, "skipping duplicate delivery", "status", job.Status, logging.Err(err))
+			tracing.End(span, nil)
+			return nil, nil
+		}
+		slog.ErrorContext(jobCtx, "failed to claim job", logging.Err(err))
+		tracing.End(span, err)
+		return nil, nil
+	}
+
+	prepared, err := r.worker.prepare(scopeJob(jobCtx, job), job)
+	tracing.End(span, err)
+	if err != nil {
+		r.worker.fail(jobCtx, job, err)
+		return nil, nil
+	}
+	return job, prepared
+}
+
+// submit sends the requests to the provider and records the batch
+func (r *LLMBatchRunner) submit(ctx context.Context, stage domain.LLMBatchStage, requests []llm.BatchRequest, items []domain.LLMBatchItem) error {
+	providerID, err := r.llmClient.SubmitBatch(ctx, requests)
+	if err != nil {
+		metrics.LLMBatchesTotal.WithLabelValues(string(stage), "failed").Inc()
+		return fmt.Errorf("failed to submit %s batch: %w", stage, err)
+	}
+
+	batch := r.newBatch(providerID, stage, len(requests))
+	if err := r.batchRepo.Create(batch, items); err != nil {
+		// The provider runs it anyway, but nothing would collect the results
+		metrics.LLMBatchesTotal.WithLabelValues(string(stage), "failed").Inc()
+		return fmt.Errorf("failed to record %s batch %s: %w", stage, providerID, err)
+	}
+
+	metrics.LLMBatchesTotal.WithLabelValues(string(stage), "submitted").Inc()
+	slog.InfoContext(ctx, "llm batch submitted", "stage", stage, "provider_batch_id", providerID, "jobs", len(items), "requests", len(requests))
+	return nil
+}
+
+func (r *LLMBatchRunner) newBatch(providerID string, stage domain.LLMBatchStage, requests int) *domain.LLMBatch {
+	return &domain.LLMBatch{
+		ProviderBatchID: providerID,
+		Stage:           stage,
+		Status:          domain.LLMBatchSubmitted,
+		RequestCount:    requests,
+		NextPollAt:      time.Now().Add(r.pollInterval),
+	}
+}
+
+// pollDue checks every batch whose poll is due
+func (r *LLMBatchRunner) pollDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		// Claiming leases the batch until its next poll; if this process dies
+		// mid-way, another runner picks it up then
+		batch, err := r.batchRepo.ClaimDue(time.Now(), r.pollInterval)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to claim llm batch", logging.Err(err))
+			return
+		}
+		if batch == nil {
+			return
+		}
+		r.poll(ctx, batch)
+	}
+}
+
+func (r *LLMBatchRunner) poll(ctx context.Context, batch *domain.LLMBatch) {
+	ctx, span := tracing.Start(ctx, "llm_batch.poll",
+		attribute.String("llm_batch.stage", string(batch.Stage)),
+		attribute.String("llm_batch.provider_id", batch.ProviderBatchID),
+	)
+	var err error
+	defer func() { tracing.End(span, err) }()
+
+	done, results, batchErr := r.llmClient.BatchResults(ctx, batch.ProviderBatchID)
+	if !done {
+		if batchErr != nil {
+			slog.WarnContext(ctx, "failed to check llm batch, retrying at next poll", "provider_batch_id", batch.ProviderBatchID, logging.Err(batchErr))
+		}
+		return
+	}
+
+	items, err := r.batchRepo.Items(batch.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load llm batch items", "provider_batch_id", batch.ProviderBatchID, logging.Err(err))
+		return
+	}
+
+	if batchErr != nil {
+		// The whole batch failed, e.g. the input file was rejected
+		err = batchErr
+		batch.Status = domain.LLMBatchFailed
+		batch.ErrorMessage = batchErr.Error()
+		if finishErr := r.batchRepo.Finish(batch, nil, nil); finishErr != nil {
+			slog.ErrorContext(ctx, "failed to record llm batch failure", logging.Err(finishErr))
+			return
+		}
+		for _, item := range items {
+			if job := r.activeJob(ctx, item.JobID); job != nil {
+				r.worker.fail(logging.WithJobID(ctx, batchJobID(job)), job, batchErr)
+			}
+		}
+		metrics.LLMBatchesTotal.WithLabelValues(string(batch.Stage), "failed").Inc()
+		return
+	}
+
+	switch batch.Stage {
+	case domain.LLMBatchEvaluate:
+		err = r.finishEvaluations(ctx, batch, items, results)
+	case domain.LLMBatchSummarize:
+		err = r.finishSummaries(ctx, batch, items, results)
+	default:
+		err = fmt.Errorf("unknown llm batch stage %q", batch.Stage)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to process llm batch results, retrying at next poll", "provider_batch_id", batch.ProviderBatchID, logging.Err(err))
+		return
+	}
+	metrics.LLMBatchesTotal.WithLabelValues(string(batch.Stage), "completed").Inc()
+	slog.InfoContext(ctx, "llm batch finished", "stage", batch.Stage, "provider_batch_id", batch.ProviderBatchID, "jobs", len(items))
+}
+
+// finishEvaluations parses the CV and project evaluations and submits the
+// summaries of the jobs that got both as the second batch. Jobs are failed
+// and usage recorded only once the summary batch is stored, so a retry
+// after an error doesn't count anything twice.
+func (r *LLMBatchRunner) finishEvaluations(ctx context.Context, batch *domain.LLMBatch, items []domain.LLMBatchItem, results map[string]*llm.BatchResult) error {
+	type failure struct {
+		job *domain.EvaluationJob
+		err error
+	}
+	var (
+		failures  []failure
+		requests  []llm.BatchRequest
+		nextItems []domain.LLMBatchItem
+		usage     []func()
+	)
+
+	for _, item := range items {
+		job := r.activeJob(ctx, item.JobID)
+		if job == nil {
+			continue
+		}
+		jobCtx := scopeJob(logging.WithJobID(ctx, batchJobID(job)), job)
+		customID := batchCustomID(job.ID)
+
+		cvRaw, projectRaw := results[customID+":cv"], results[customID+":project"]
+		for op, result := range map[string]*llm.BatchResult{llm.OperationEvaluateCV: cvRaw, llm.OperationEvaluateProject: projectRaw} {
+			if result != nil {
+				usage = append(usage, func() { r.llmClient.RecordBatchUsage(jobCtx, op, result) })
+			}
+		}
+
+		cvResult, err := batchAnswer(jobCtx, cvRaw, llm.ParseCVEvaluation)
+		if err != nil {
+			failures = append(failures, failure{job, fmt.Errorf("failed to evaluate CV: %w", err)})
+			continue
+		}
+		projectResult, err := batchAnswer(jobCtx, projectRaw, llm.ParseProjectEvaluation)
+		if err != nil {
+			failures = append(failures, failure{job, fmt.Errorf("failed to evaluate project: %w", err)})
+			continue
+		}
+		slog.InfoContext(jobCtx, "evaluations received from batch", "match_rate", cvResult.MatchRate, "score", projectResult.Score)
+
+		state := item.State
+		if state == nil {
+			state = &domain.LLMBatchState{}
+		}
+		state.CVResult, state.ProjectResult = cvResult, projectResult
+		requests = append(requests, llm.SummaryRequest(customID+":summary", cvResult.Feedback, projectResult.Feedback, cvResult.MatchRate, projectResult.Score))
+		nextItems = append(nextItems, domain.LLMBatchItem{JobID: job.ID, State: state})
+	}
+
+	// If this process dies between submitting and storing the summary batch,
+	// the next poll submits it again; the orphaned batch is never read
+	var next *domain.LLMBatch
+	if len(requests) > 0 {
+		providerID, err := r.llmClient.SubmitBatch(ctx, requests)
+		if err != nil {
+			metrics.LLMBatchesTotal.WithLabelValues(string(domain.LLMBatchSummarize), "failed").Inc()
+			return fmt.Errorf("failed to submit summarize batch: %w", err)
+		}
+		next = r.newBatch(providerID, domain.LLMBatchSummarize, len(requests))
+	}
+
+	batch.Status = domain.LLMBatchCompleted
+	if err := r.batchRepo.Finish(batch, next, nextItems); err != nil {
+		return err
+	}
+	if next != nil {
+		metrics.LLMBatchesTotal.WithLabelValues(string(domain.LLMBatchSummarize), "submitted").Inc()
+		slog.InfoContext(ctx, "llm batch submitted", "stage", next.Stage, "provider_batch_id", next.ProviderBatchID, "jobs", len(nextItems), "requests", len(requests))
+	}
+
+	for _, record := range usage {
+		record()
+	}
+	for _, f := range failures {
+		r.worker.fail(logging.WithJobID(ctx, batchJobID(f.job)), f.job, f.err)
+	}
+	return nil
+}
+
+// finishSummaries completes each job from its carried evaluations and summary
+func (r *LLMBatchRunner) finishSummaries(ctx context.Context, batch *domain.LLMBatch, items []domain.LLMBatchItem, results map[string]*llm.BatchResult) error {
+	for _, item := range items {
+		job := r.activeJob(ctx, item.JobID)
+		if job == nil {
+			continue
+		}
+		jobCtx := scopeJob(logging.WithJobID(ctx, batchJobID(job)), job)
+
+		raw := results[batchCustomID(job.ID)+":summary"]
+		if raw != nil {
+			r.llmClient.RecordBatchUsage(jobCtx, llm.OperationGenerateSummary, raw)
+		}
+		r.finishSummary(jobCtx, job, item, raw)
+
+		// The job is done either way; don't keep its PII until the batch is
+		if err := r.batchRepo.ClearState(batch.ID, job.ID); err != nil {
+			slog.ErrorContext(jobCtx, "failed to clear llm batch state", logging.Err(err))
+		}
+	}
+
+	batch.Status = domain.LLMBatchCompleted
+	return r.batchRepo.Finish(batch, nil, nil)
+}
+
+// finishSummary completes a job from its carried evaluations and summary, or
+// fails it
+func (r *LLMBatchRunner) finishSummary(ctx context.Context, job *domain.EvaluationJob, item domain.LLMBatchItem, raw *llm.BatchResult) {
+	summary, err := batchAnswer(ctx, raw, func(_ context.Context, content string) (string, error) { return content, nil })
+	if err != nil {
+		r.worker.fail(ctx, job, fmt.Errorf("failed to generate summary: %w", err))
+		return
+	}
+	if item.State == nil || item.State.CVResult == nil || item.State.ProjectResult == nil {
+		r.worker.fail(ctx, job, errors.New("batch state lost before the summary arrived"))
+		return
+	}
+
+	var redactor *redact.Redactor
+	if len(item.State.Redactions) > 0 {
+		redactor = redact.FromOriginals(item.State.Redactions)
+	}
+	if err := r.worker.complete(job, redactor, item.State.CVResult, item.State.ProjectResult, summary); err != nil {
+		r.worker.fail(ctx, job, err)
+		return
+	}
+	slog.InfoContext(ctx, "job completed", "mode", "batch")
+	metrics.JobsTotal.WithLabelValues(string(domain.JobCompleted)).Inc()
+}
+
+// activeJob loads a job that is still waiting on its batch, or returns nil
+// when it is gone or already finished, e.g. by an earlier attempt
+func (r *LLMBatchRunner) activeJob(ctx context.Context, id uint) *domain.EvaluationJob {
+	job, err := r.evalRepo.GetByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "llm batch job not found", "job_id", id, logging.Err(err))
+		return nil
+	}
+	if job.Status != domain.JobProcessing {
+		return nil
+	}
+	return job
+}
+
+// batchAnswer parses a batch result, treating a missing result as an error
+func batchAnswer[T any](ctx context.Context, result *llm.BatchResult, parse func(context.Context, string) (T, error)) (T, error) {
+	var zero T
+	if result == nil {
+		return zero, errors.New("no result in batch output")
+	}
+	if result.Err != nil {
+		return zero, result.Err
+	}
+	return parse(ctx, result.Content)
+}
+
+// batchCustomID identifies a job's requests in a batch; the request kind is
+// appended, e.g. "job-42:cv"
+func batchCustomID(jobID uint) string {
+	return "job-" + strconv.FormatUint(uint64(jobID), 10)
+}
+
+func batchJobID(job *domain.EvaluationJob) string {
+	return strconv.FormatUint(uint64(job.ID), 10)
+}
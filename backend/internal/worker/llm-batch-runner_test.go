@@ -0,0 +1,265 @@
+package worker
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adyutaa/parsea/internal/config"
+	"github.com/adyutaa/parsea/internal/domain"
+	"github.com/adyutaa/parsea/internal/infrastructure/llm"
+	"github.com/adyutaa/parsea/internal/infrastructure/llm/llmstub"
+	"github.com/adyutaa/parsea/internal/queue"
+	"github.com/adyutaa/parsea/internal/repository"
+	"github.com/adyutaa/parsea/internal/testdb"
+	"github.com/adyutaa/parsea/pkg/extract"
+	"gorm.io/gorm"
+)
+
+// batchHarness runs an LLMBatchRunner against llmstub and a rolled-back
+// test transaction
+type batchHarness struct {
+	t         *testing.T
+	tx        *gorm.DB
+	stub      *llmstub.Server
+	runner    *LLMBatchRunner
+	evalRepo  *repository.EvaluationRepository
+	batchRepo *repository.LLMBatchRepository
+	tenantID  string
+	cvID      uint
+	reportID  uint
+}
+
+func newBatchHarness(t *testing.T) *batchHarness {
+	t.Helper()
+	tx := testdb.Tx(t)
+
+	// A short delay makes the first poll see the batch still in progress
+	stub := llmstub.NewServer(20 * time.Millisecond)
+	srv := httptest.NewServer(stub.Handler())
+	t.Cleanup(srv.Close)
+	// The synchronous client reads its base URL from the environment; point
+	// it at the stub too so nothing reaches OpenAI
+	t.Setenv("OPENAI_BASE_URL", srv.URL+"/v1")
+	llmClient := llm.NewOpenAIClient(config.LLMConfig{
+		APIKey:         "test",
+		Timeout:        10 * time.Second,
+		SummaryTimeout: 10 * time.Second,
+		Batch:          config.LLMBatchConfig{BaseURL: srv.URL + "/v1"},
+	})
+
+	tenant := domain.DefaultTenant("llm-batch-test")
+	if err := repository.NewTenantRepository(tx).Create(tenant); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+
+	dir := t.TempDir()
+	docRepo := repository.NewDocumentRepository(tx)
+	newDoc := func(name, docType, text string, profile *domain.CandidateProfile) uint {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		doc := &domain.Document{TenantID: tenant.ID, Filename: name, FilePath: path, DocType: docType, MimeType: extract.MimeText, Profile: profile}
+		if err := docRepo.Create(doc); err != nil {
+			t.Fatalf("create document: %v", err)
+		}
+		return doc.ID
+	}
+	// A stored profile skips the synchronous profile extraction
+	profile := &domain.CandidateProfile{Contact: domain.ContactInfo{Name: "Jane Doe"}, YearsExperience: 5}
+	cvID := newDoc("cv.txt", "cv", "Jane Doe\nBackend engineer, five years of Go and PostgreSQL.\n", profile)
+	reportID := newDoc("report.txt", "project_report", "Built the evaluation pipeline with retries and a job queue.\n", nil)
+
+	evalRepo := repository.NewEvaluationRepository(tx)
+	batchRepo := repository.NewLLMBatchRepository(tx)
+	worker := NewEvaluationWorker(nil, evalRepo, docRepo, repository.NewTenantRepository(tx), llmClient, nil,
+		extract.NewRegistry(nil), 30*time.Second, queue.Weights{High: 6, Normal: 3, Bulk: 1})
+
+	return &batchHarness{
+		t:         t,
+		tx:        tx,
+		stub:      stub,
+		runner:    NewLLMBatchRunner(worker, batchRepo, 10, time.Millisecond, time.Millisecond),
+		evalRepo:  evalRepo,
+		batchRepo: batchRepo,
+		tenantID:  tenant.ID,
+		cvID:      cvID,
+		reportID:  reportID,
+	}
+}
+
+// queueJobs creates n bulk jobs and returns them with their queue messages
+func (h *batchHarness) queueJobs(n int) ([]*domain.EvaluationJob, []string) {
+	h.t.Helper()
+	var (
+		jobs     []*domain.EvaluationJob
+		payloads []string
+	)
+	for i := 0; i < n; i++ {
+		job := &domain.EvaluationJob{
+			TenantID: h.tenantID,
+			CVID:     h.cvID,
+			ReportID: h.reportID,
+			JobTitle: "Backend Engineer",
+			Status:   domain.JobQueued,
+			Priority: domain.PriorityBulk,
+		}
+		if err := h.evalRepo.Create(job); err != nil {
+			h.t.Fatalf("create job: %v", err)
+		}
+		payload, err := queue.NewMessage(context.Background(), job.ID).Encode()
+		if err != nil {
+			h.t.Fatal(err)
+		}
+		jobs = append(jobs, job)
+		payloads = append(payloads, payload)
+	}
+	return jobs, payloads
+}
+
+// run submits the messages and polls until no batch is left waiting
+func (h *batchHarness) run(payloads []string) {
+	h.t.Helper()
+	ctx := context.Background()
+	h.runner.submitEvaluations(ctx, payloads)
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		waiting, err := h.batchRepo.CountSubmitted()
+		if err != nil {
+			h.t.Fatalf("count submitted batches: %v", err)
+		}
+		if waiting == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			h.t.Fatalf("%d llm batches still submitted after 10s", waiting)
+		}
+		time.Sleep(5 * time.Millisecond)
+		h.runner.pollDue(ctx)
+	}
+}
+
+func (h *batchHarness) job(id uint) *domain.EvaluationJob {
+	h.t.Helper()
+	job, err := h.evalRepo.GetByID(id)
+	if err != nil {
+		h.t.Fatalf("load job %d: %v", id, err)
+	}
+	return job
+}
+
+// batches returns the stored provider batches, oldest first
+func (h *batchHarness) batches() []domain.LLMBatch {
+	h.t.Helper()
+	var batches []domain.LLMBatch
+	if err := h.tx.Order("id ASC").Find(&batches).Error; err != nil {
+		h.t.Fatalf("load llm batches: %v", err)
+	}
+	return batches
+}
+
+// assertNoState checks that no batch item still holds carried state, which
+// includes the PII originals of redactions
+func (h *batchHarness) assertNoState() {
+	h.t.Helper()
+	var count int64
+	if err := h.tx.Model(&domain.LLMBatchItem{}).Where("state IS NOT NULL").Count(&count).Error; err != nil {
+		h.t.Fatalf("count llm batch items: %v", err)
+	}
+	if count != 0 {
+		h.t.Errorf("%d llm batch items still hold state after their jobs finished", count)
+	}
+}
+
+func assertCompleted(t *testing.T, job *domain.EvaluationJob) {
+	t.Helper()
+	if job.Status != domain.JobCompleted {
+		t.Errorf("job %d status = %q (%s), want completed", job.ID, job.Status, job.ErrorMessage)
+		return
+	}
+	if job.Result == nil || job.Result.OverallSummary != llmstub.SummaryAnswer {
+		t.Errorf("job %d result = %+v, want the stub summary", job.ID, job.Result)
+	}
+	if job.Recommendation == "" || job.CVMatchRate == nil || job.ProjectScore == nil {
+		t.Errorf("job %d scores not stored: recommendation %q, match rate %v, project score %v", job.ID, job.Recommendation, job.CVMatchRate, job.ProjectScore)
+	}
+}
+
+func assertFailed(t *testing.T, job *domain.EvaluationJob, want string) {
+	t.Helper()
+	if job.Status != domain.JobFailed {
+		t.Errorf("job %d status = %q, want failed", job.ID, job.Status)
+		return
+	}
+	if !strings.Contains(job.ErrorMessage, want) {
+		t.Errorf("job %d error = %q, want it to mention %q", job.ID, job.ErrorMessage, want)
+	}
+	if job.Recommendation != "" {
+		t.Errorf("job %d recommendation = %q, want none", job.ID, job.Recommendation)
+	}
+}
+
+func TestLLMBatchRunnerCompletesJobs(t *testing.T) {
+	h := newBatchHarness(t)
+	jobs, payloads := h.queueJobs(3)
+
+	h.run(payloads)
+
+	for _, job := range jobs {
+		assertCompleted(t, h.job(job.ID))
+	}
+	h.assertNoState()
+
+	batches := h.batches()
+	if len(batches) != 2 {
+		t.Fatalf("stored %d llm batches, want an evaluate and a summarize batch", len(batches))
+	}
+	for i, want := range []struct {
+		stage    domain.LLMBatchStage
+		requests int
+	}{{domain.LLMBatchEvaluate, 6}, {domain.LLMBatchSummarize, 3}} {
+		b := batches[i]
+		if b.Stage != want.stage || b.Status != domain.LLMBatchCompleted || b.RequestCount != want.requests {
+			t.Errorf("batch %d = stage %q, status %q, %d requests; want completed %q with %d requests",
+				i, b.Stage, b.Status, b.RequestCount, want.stage, want.requests)
+		}
+	}
+}
+
+func TestLLMBatchRunnerPartialFailure(t *testing.T) {
+	h := newBatchHarness(t)
+	jobs, payloads := h.queueJobs(4)
+	failedCV, expiredProject, expiredSummary, ok := jobs[0], jobs[1], jobs[2], jobs[3]
+
+	h.stub.FailCustomIDs[batchCustomID(failedCV.ID)+":cv"] = true
+	h.stub.ExpireCustomIDs[batchCustomID(expiredProject.ID)+":project"] = true
+	h.stub.ExpireCustomIDs[batchCustomID(expiredSummary.ID)+":summary"] = true
+
+	h.run(payloads)
+
+	assertFailed(t, h.job(failedCV.ID), "failed to evaluate CV: stub_failure")
+	assertFailed(t, h.job(expiredProject.ID), "failed to evaluate project: batch_expired")
+	assertFailed(t, h.job(expiredSummary.ID), "failed to generate summary: batch_expired")
+	assertCompleted(t, h.job(ok.ID))
+	h.assertNoState()
+
+	// Only the jobs that got both evaluations go on to the summary batch
+	batches := h.batches()
+	if len(batches) != 2 {
+		t.Fatalf("stored %d llm batches, want 2", len(batches))
+	}
+	if got := batches[1].RequestCount; got != 2 {
+		t.Errorf("summarize batch has %d requests, want 2", got)
+	}
+	for _, b := range batches {
+		if b.Status != domain.LLMBatchCompleted {
+			t.Errorf("%s batch status = %q, want completed", b.Stage, b.Status)
+		}
+	}
+}
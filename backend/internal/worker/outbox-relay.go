@@ -0,0 +1,111 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/adyutaa/parsea/internal/domain"
+	"github.com/adyutaa/parsea/internal/logging"
+	"github.com/adyutaa/parsea/internal/metrics"
+	"github.com/adyutaa/parsea/internal/repository"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// outboxRetention is how long published outbox rows are kept for debugging
+	outboxRetention = 24 * time.Hour
+	// outboxCleanupInterval is how often published rows are pruned
+	outboxCleanupInterval = time.Hour
+)
+
+// OutboxRelay publishes outbox rows to their Redis queues and marks them sent
+type OutboxRelay struct {
+	redis        *redis.Client
+	outboxRepo   *repository.OutboxRepository
+	pollInterval time.Duration
+	batchSize    int
+	wake         chan struct{}
+}
+
+func NewOutboxRelay(redis *redis.Client, outboxRepo *repository.OutboxRepository, pollInterval time.Duration, batchSize int) *OutboxRelay {
+	return &OutboxRelay{
+		redis:        redis,
+		outboxRepo:   outboxRepo,
+		pollInterval: pollInterval,
+		batchSize:    batchSize,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// Notify asks the relay to run now rather than at the next poll, e.g. right
+// after a job was committed. It never blocks.
+func (r *OutboxRelay) Notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start relays messages until ctx is cancelled
+func (r *OutboxRelay) Start(ctx context.Context) {
+	slog.InfoContext(ctx, "outbox relay started", "poll_interval", r.pollInterval.String())
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+	lastCleanup := time.Now()
+
+	for {
+		// Drain full batches back to back, then wait
+		for ctx.Err() == nil {
+			if r.relayOnce(ctx) < r.batchSize {
+				break
+			}
+		}
+
+		if time.Since(lastCleanup) > outboxCleanupInterval {
+			if n, err := r.outboxRepo.DeleteSentBefore(time.Now().Add(-outboxRetention)); err != nil {
+				slog.WarnContext(ctx, "failed to prune outbox", logging.Err(err))
+			} else if n > 0 {
+				slog.InfoContext(ctx, "pruned outbox", "deleted", n)
+			}
+			lastCleanup = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.InfoContext(ctx, "outbox relay shutting down")
+			return
+		case <-ticker.C:
+		case <-r.wake:
+		}
+	}
+}
+
+// relayOnce publishes one batch and returns how many messages were sent
+func (r *OutboxRelay) relayOnce(ctx context.Context) int {
+	sent, err := r.outboxRepo.Relay(r.batchSize, func(messages []domain.OutboxMessage) []error {
+		pipe := r.redis.Pipeline()
+		cmds := make([]*redis.IntCmd, len(messages))
+		for i, msg := range messages {
+			cmds[i] = pipe.LPush(ctx, msg.Queue, msg.Payload)
+		}
+		pipe.Exec(ctx)
+
+		errs := make([]error, len(messages))
+		for i, cmd := range cmds {
+			errs[i] = cmd.Err()
+		}
+		return errs
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.ErrorContext(ctx, "outbox relay failed", logging.Err(err))
+		}
+		return 0
+	}
+	if sent > 0 {
+		metrics.OutboxPublished.Add(float64(sent))
+		slog.DebugContext(ctx, "outbox messages published", "count", sent)
+	}
+	return sent
+}
@@ -0,0 +1,56 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/adyutaa/parsea/internal/logging"
+	"github.com/adyutaa/parsea/internal/service"
+)
+
+// Purger erases documents once they outlive their tenant's retention period
+type Purger struct {
+	erasures  *service.ErasureService
+	interval  time.Duration
+	batchSize int
+}
+
+func NewPurger(erasures *service.ErasureService, interval time.Duration, batchSize int) *Purger {
+	return &Purger{
+		erasures:  erasures,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+// Start purges expired documents every interval until ctx is cancelled
+func (p *Purger) Start(ctx context.Context) {
+	slog.InfoContext(ctx, "retention purger started", "interval", p.interval.String())
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			if p.purge(ctx) < p.batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.InfoContext(ctx, "retention purger shutting down")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purge erases one batch of expired documents and returns how many were erased
+func (p *Purger) purge(ctx context.Context) int {
+	purged, err := p.erasures.PurgeExpired(ctx, time.Now(), p.batchSize)
+	if err != nil && ctx.Err() == nil {
+		slog.ErrorContext(ctx, "failed to purge expired documents", logging.Err(err))
+	}
+	return purged
+}
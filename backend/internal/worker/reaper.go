@@ -0,0 +1,153 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/adyutaa/parsea/internal/domain"
+	"github.com/adyutaa/parsea/internal/logging"
+	"github.com/adyutaa/parsea/internal/queue"
+	"github.com/adyutaa/parsea/internal/repository"
+	"github.com/redis/go-redis/v9"
+)
+
+// maxRequeues is how often a job is taken back from a dead worker before it
+// is failed, so a job that crashes every worker that claims it can't loop
+const maxRequeues = 3
+
+// Reaper recovers jobs whose queue message was lost. A worker pops a message
+// before it claims the job, so a crash loses the message and leaves the job
+// processing, or queued when the crash came before the claim. Processing
+// jobs whose worker stopped heartbeating and queued jobs whose message is in
+// none of the queues are requeued through the outbox once they have been
+// unchanged for staleAfter.
+type Reaper struct {
+	id         string
+	evalRepo   *repository.EvaluationRepository
+	interval   time.Duration
+	staleAfter time.Duration
+	batchSize  int
+	notify     func() // wakes the outbox relay after jobs were requeued
+
+	// liveWorkers returns the IDs of workers with a current heartbeat
+	liveWorkers func(ctx context.Context) (map[string]time.Time, error)
+	// queuedJobs returns the IDs of jobs with a message in any queue
+	queuedJobs func(ctx context.Context) (map[uint]bool, error)
+}
+
+func NewReaper(id string, rdb *redis.Client, evalRepo *repository.EvaluationRepository, interval, staleAfter time.Duration, batchSize int, notify func()) *Reaper {
+	return &Reaper{
+		id:          id,
+		evalRepo:    evalRepo,
+		interval:    interval,
+		staleAfter:  staleAfter,
+		batchSize:   batchSize,
+		notify:      notify,
+		liveWorkers: func(ctx context.Context) (map[string]time.Time, error) { return Heartbeats(ctx, rdb) },
+		queuedJobs:  func(ctx context.Context) (map[uint]bool, error) { return queuedJobIDs(ctx, rdb) },
+	}
+}
+
+// Start looks for lost jobs every interval until ctx is cancelled
+func (r *Reaper) Start(ctx context.Context) {
+	slog.InfoContext(ctx, "reaper started", "interval", r.interval.String(), "stale_after", r.staleAfter.String())
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.reap(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "failed to reap lost jobs", logging.Err(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.InfoContext(ctx, "reaper shutting down")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reap requeues one batch of lost jobs
+func (r *Reaper) reap(ctx context.Context) error {
+	jobs, err := r.evalRepo.ListStale(time.Now().Add(-r.staleAfter), r.batchSize)
+	if err != nil {
+		return err
+	}
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	// Read Redis after the database, so a message published or a worker
+	// started in between is seen and its job left alone
+	live, err := r.liveWorkers(ctx)
+	if err != nil {
+		return fmt.Errorf("read worker heartbeats: %w", err)
+	}
+	queued, err := r.queuedJobs(ctx)
+	if err != nil {
+		return fmt.Errorf("read queued messages: %w", err)
+	}
+
+	requeued := 0
+	for i := range jobs {
+		stale := &jobs[i]
+		job := &stale.EvaluationJob
+		var reason string
+		switch job.Status {
+		case domain.JobProcessing:
+			if _, ok := live[stale.ClaimedBy]; ok {
+				continue
+			}
+			reason = fmt.Sprintf("worker %s stopped", stale.ClaimedBy)
+		case domain.JobQueued:
+			if queued[job.ID] {
+				continue
+			}
+			reason = "queue message lost"
+		}
+
+		if job.Status == domain.JobProcessing && stale.Requeues >= maxRequeues {
+			msg := fmt.Sprintf("job lost by its worker %d times", stale.Requeues+1)
+			if err := r.evalRepo.MarkFailed(job, msg, r.id); err != nil {
+				slog.ErrorContext(ctx, "failed to fail lost job", "job_id", job.ID, logging.Err(err))
+				continue
+			}
+			slog.WarnContext(ctx, "failed job lost too often", "job_id", job.ID, "worker_id", stale.ClaimedBy)
+			continue
+		}
+
+		err := r.evalRepo.Requeue(job, r.id, reason, queue.NameFor(job.Priority),
+			func(jobID uint) (string, error) { return queue.NewMessage(ctx, jobID).Encode() })
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to requeue lost job", "job_id", job.ID, logging.Err(err))
+			continue
+		}
+		slog.WarnContext(ctx, "requeued lost job", "job_id", job.ID, "reason", reason)
+		requeued++
+	}
+
+	if requeued > 0 && r.notify != nil {
+		r.notify()
+	}
+	return nil
+}
+
+// queuedJobIDs returns the IDs of the jobs with a message in any priority queue
+func queuedJobIDs(ctx context.Context, rdb *redis.Client) (map[uint]bool, error) {
+	ids := make(map[uint]bool)
+	for _, name := range []string{queue.HighQueue, queue.EvaluationQueue, queue.BulkQueue} {
+		payloads, err := rdb.LRange(ctx, name, 0, -1).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, payload := range payloads {
+			if msg, err := queue.Decode(payload); err == nil {
+				ids[msg.JobID] = true
+			}
+		}
+	}
+	return ids, nil
+}
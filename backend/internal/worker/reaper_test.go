@@ -0,0 +1,237 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/adyutaa/parsea/internal/domain"
+	"github.com/adyutaa/parsea/internal/queue"
+	"github.com/adyutaa/parsea/internal/repository"
+	"github.com/adyutaa/parsea/internal/testdb"
+	"gorm.io/gorm"
+)
+
+// reaperHarness runs a Reaper against a rolled-back test transaction, with
+// the worker heartbeats and queue contents it reads from Redis stubbed out
+type reaperHarness struct {
+	t        *testing.T
+	tx       *gorm.DB
+	evalRepo *repository.EvaluationRepository
+	reaper   *Reaper
+	live     map[string]time.Time
+	queued   map[uint]bool
+	tenantID string
+	cvID     uint
+	reportID uint
+}
+
+func newReaperHarness(t *testing.T) *reaperHarness {
+	t.Helper()
+	tx := testdb.Tx(t)
+
+	tenant := domain.DefaultTenant("reaper-test")
+	if err := repository.NewTenantRepository(tx).Create(tenant); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+	docRepo := repository.NewDocumentRepository(tx)
+	cv := &domain.Document{TenantID: tenant.ID, Filename: "cv.txt", FilePath: "/nonexistent/cv.txt", DocType: "cv", MimeType: "text/plain"}
+	report := &domain.Document{TenantID: tenant.ID, Filename: "report.txt", FilePath: "/nonexistent/report.txt", DocType: "project_report", MimeType: "text/plain"}
+	for _, doc := range []*domain.Document{cv, report} {
+		if err := docRepo.Create(doc); err != nil {
+			t.Fatalf("create document: %v", err)
+		}
+	}
+
+	h := &reaperHarness{
+		t:        t,
+		tx:       tx,
+		evalRepo: repository.NewEvaluationRepository(tx),
+		live:     map[string]time.Time{},
+		queued:   map[uint]bool{},
+		tenantID: tenant.ID,
+		cvID:     cv.ID,
+		reportID: report.ID,
+	}
+	h.reaper = NewReaper("reaper-test", nil, h.evalRepo, time.Minute, time.Minute, 1000, nil)
+	h.reaper.liveWorkers = func(context.Context) (map[string]time.Time, error) { return h.live, nil }
+	h.reaper.queuedJobs = func(context.Context) (map[uint]bool, error) { return h.queued, nil }
+	return h
+}
+
+// job creates a queued job, claims it for claimedBy unless that is empty, and
+// backdates it so the reaper considers it stale
+func (h *reaperHarness) job(claimedBy string) *domain.EvaluationJob {
+	h.t.Helper()
+	job := &domain.EvaluationJob{
+		TenantID: h.tenantID,
+		CVID:     h.cvID,
+		ReportID: h.reportID,
+		JobTitle: "Backend Engineer",
+		Status:   domain.JobQueued,
+		Priority: domain.PriorityHigh,
+	}
+	if err := h.evalRepo.Create(job); err != nil {
+		h.t.Fatalf("create job: %v", err)
+	}
+	if claimedBy != "" {
+		if err := h.evalRepo.MarkProcessing(job, claimedBy); err != nil {
+			h.t.Fatalf("MarkProcessing: %v", err)
+		}
+	}
+	if err := h.tx.Model(&domain.EvaluationJob{}).Where("id = ?", job.ID).
+		UpdateColumn("updated_at", time.Now().Add(-time.Hour)).Error; err != nil {
+		h.t.Fatalf("backdate job: %v", err)
+	}
+	return job
+}
+
+func (h *reaperHarness) reap() {
+	h.t.Helper()
+	if err := h.reaper.reap(context.Background()); err != nil {
+		h.t.Fatalf("reap: %v", err)
+	}
+}
+
+func (h *reaperHarness) status(job *domain.EvaluationJob) domain.JobStatus {
+	h.t.Helper()
+	stored, err := h.evalRepo.GetByID(job.ID)
+	if err != nil {
+		h.t.Fatalf("GetByID: %v", err)
+	}
+	return stored.Status
+}
+
+// messages counts the outbox messages that queue job
+func (h *reaperHarness) messages(job *domain.EvaluationJob) int {
+	h.t.Helper()
+	var outbox []domain.OutboxMessage
+	if err := h.tx.Where("queue = ?", queue.HighQueue).Find(&outbox).Error; err != nil {
+		h.t.Fatalf("list outbox: %v", err)
+	}
+	n := 0
+	for _, m := range outbox {
+		if msg, err := queue.Decode(m.Payload); err == nil && msg.JobID == job.ID {
+			n++
+		}
+	}
+	return n
+}
+
+// A worker popped the job's message, claimed the job and crashed. Its
+// heartbeat expired and the message is gone, so only the reaper can recover it.
+func TestReaperRequeuesJobOfCrashedWorker(t *testing.T) {
+	h := newReaperHarness(t)
+	job := h.job("crashed-worker")
+
+	h.reap()
+
+	if got := h.status(job); got != domain.JobQueued {
+		t.Fatalf("status = %q, want queued", got)
+	}
+	if got := h.messages(job); got != 1 {
+		t.Errorf("%d outbox messages for the job, want 1", got)
+	}
+	events, err := h.evalRepo.ListEvents(job.ID)
+	if err != nil {
+		t.Fatalf("ListEvents: %v", err)
+	}
+	last := events[len(events)-1]
+	if last.FromStatus != domain.JobProcessing || last.ToStatus != domain.JobQueued || last.WorkerID != "reaper-test" {
+		t.Errorf("last event = %s -> %s by %q, want processing -> queued by the reaper", last.FromStatus, last.ToStatus, last.WorkerID)
+	}
+
+	// The new message lets another worker claim and finish the job
+	requeued, err := h.evalRepo.GetByID(job.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if err := h.evalRepo.MarkProcessing(requeued, "next-worker"); err != nil {
+		t.Errorf("claiming the requeued job: %v", err)
+	}
+}
+
+func TestReaperLeavesJobOfLiveWorker(t *testing.T) {
+	h := newReaperHarness(t)
+	job := h.job("busy-worker")
+	h.live["busy-worker"] = time.Now()
+
+	h.reap()
+
+	if got := h.status(job); got != domain.JobProcessing {
+		t.Errorf("status = %q, want processing", got)
+	}
+	if got := h.messages(job); got != 0 {
+		t.Errorf("%d outbox messages for the job, want 0", got)
+	}
+}
+
+func TestReaperLeavesJobWaitingOnLLMBatch(t *testing.T) {
+	h := newReaperHarness(t)
+	job := h.job("crashed-worker")
+	batch := &domain.LLMBatch{ProviderBatchID: "batch_reaper_test", Stage: domain.LLMBatchEvaluate, Status: domain.LLMBatchSubmitted, RequestCount: 2, NextPollAt: time.Now()}
+	if err := repository.NewLLMBatchRepository(h.tx).Create(batch, []domain.LLMBatchItem{{JobID: job.ID}}); err != nil {
+		t.Fatalf("create llm batch: %v", err)
+	}
+
+	h.reap()
+
+	if got := h.status(job); got != domain.JobProcessing {
+		t.Errorf("status = %q, want processing", got)
+	}
+}
+
+// A worker popped the message and crashed before claiming the job
+func TestReaperResendsQueuedJobWithoutMessage(t *testing.T) {
+	h := newReaperHarness(t)
+	lost := h.job("")
+	waiting := h.job("")
+	h.queued[waiting.ID] = true
+
+	h.reap()
+
+	if got := h.messages(lost); got != 1 {
+		t.Errorf("%d outbox messages for the lost job, want 1", got)
+	}
+	if got := h.messages(waiting); got != 0 {
+		t.Errorf("%d outbox messages for the job still in the queue, want 0", got)
+	}
+	if got := h.status(lost); got != domain.JobQueued {
+		t.Errorf("status = %q, want queued", got)
+	}
+
+	// The refreshed updated_at keeps the next pass from sending it again
+	h.reap()
+	if got := h.messages(lost); got != 1 {
+		t.Errorf("%d outbox messages after a second pass, want 1", got)
+	}
+}
+
+func TestReaperFailsJobLostTooOften(t *testing.T) {
+	h := newReaperHarness(t)
+	job := h.job("crashed-worker")
+	for i := 0; i < maxRequeues; i++ {
+		h.reap()
+		stored, err := h.evalRepo.GetByID(job.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if err := h.evalRepo.MarkProcessing(stored, "crashed-worker"); err != nil {
+			t.Fatalf("MarkProcessing: %v", err)
+		}
+		if err := h.tx.Model(&domain.EvaluationJob{}).Where("id = ?", job.ID).
+			UpdateColumn("updated_at", time.Now().Add(-time.Hour)).Error; err != nil {
+			t.Fatalf("backdate job: %v", err)
+		}
+	}
+
+	h.reap()
+
+	stored, err := h.evalRepo.GetByID(job.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if stored.Status != domain.JobFailed || stored.ErrorMessage == "" {
+		t.Errorf("job = status %q, error %q; want failed with a message", stored.Status, stored.ErrorMessage)
+	}
+}
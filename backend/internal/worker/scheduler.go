@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/adyutaa/parsea/internal/domain"
+	"github.com/adyutaa/parsea/internal/logging"
+	"github.com/adyutaa/parsea/internal/queue"
+	"github.com/adyutaa/parsea/internal/repository"
+)
+
+// Scheduler queues scheduled jobs once their run_at has passed
+type Scheduler struct {
+	id        string
+	evalRepo  *repository.EvaluationRepository
+	interval  time.Duration
+	batchSize int
+	notify    func() // wakes the outbox relay after jobs were queued
+}
+
+func NewScheduler(id string, evalRepo *repository.EvaluationRepository, interval time.Duration, batchSize int, notify func()) *Scheduler {
+	return &Scheduler{
+		id:        id,
+		evalRepo:  evalRepo,
+		interval:  interval,
+		batchSize: batchSize,
+		notify:    notify,
+	}
+}
+
+// Start checks for due jobs every interval until ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	slog.InfoContext(ctx, "scheduler started", "interval", s.interval.String())
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			if s.enqueueDue(ctx) < s.batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.InfoContext(ctx, "scheduler shutting down")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// enqueueDue queues one batch of due jobs and returns how many were queued
+func (s *Scheduler) enqueueDue(ctx context.Context) int {
+	queued, err := s.evalRepo.EnqueueDue(time.Now(), s.batchSize, s.id,
+		func(job *domain.EvaluationJob) string { return queue.NameFor(job.Priority) },
+		func(jobID uint) (string, error) { return queue.NewMessage(ctx, jobID).Encode() },
+	)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.ErrorContext(ctx, "failed to queue scheduled jobs", logging.Err(err))
+		}
+		return 0
+	}
+	if queued > 0 {
+		slog.InfoContext(ctx, "scheduled jobs queued", "count", queued)
+		if s.notify != nil {
+			s.notify()
+		}
+	}
+	return queued
+}
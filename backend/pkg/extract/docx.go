@@ -0,0 +1,107 @@
+package extract
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const docxBodyPart = "word/document.xml"
+
+// DOCXExtractor extracts text from Office Open XML word documents
+type DOCXExtractor struct{}
+
+func NewDOCXExtractor() *DOCXExtractor {
+	return &DOCXExtractor{}
+}
+
+func (e *DOCXExtractor) Extract(_ context.Context, filePath string) (*Result, error) {
+	zr, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DOCX: %w", err)
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if f.Name != docxBodyPart {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", docxBodyPart, err)
+		}
+		defer rc.Close()
+
+		text, err := parseDocumentXML(rc)
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(text) == "" {
+			return nil, fmt.Errorf("no text content found in DOCX")
+		}
+
+		return &Result{Text: text, Strategy: "docx"}, nil
+	}
+
+	return nil, fmt.Errorf("invalid DOCX: %s not found", docxBodyPart)
+}
+
+// parseDocumentXML walks the WordprocessingML body, keeping paragraph,
+// line break and tab boundaries
+func parseDocumentXML(r io.Reader) (string, error) {
+	decoder := xml.NewDecoder(r)
+
+	var (
+		builder   strings.Builder
+		paragraph strings.Builder
+		inText    bool
+	)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to parse DOCX XML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				paragraph.WriteString("\t")
+			case "br", "cr":
+				paragraph.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				line := strings.TrimRight(paragraph.String(), " \t")
+				if line != "" {
+					builder.WriteString(line)
+					builder.WriteString("\n")
+				}
+				paragraph.Reset()
+			}
+		case xml.CharData:
+			if inText {
+				paragraph.Write(t)
+			}
+		}
+	}
+
+	if paragraph.Len() > 0 {
+		builder.WriteString(paragraph.String())
+	}
+
+	return builder.String(), nil
+}
@@ -0,0 +1,153 @@
+package extract
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/adyutaa/parsea/pkg/pdf"
+)
+
+// Supported MIME types
+const (
+	MimePDF      = "application/pdf"
+	MimeDOCX     = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	MimeText     = "text/plain"
+	MimeMarkdown = "text/markdown"
+)
+
+// Result holds the text extracted from a document
+type Result struct {
+	Text       string
+	Strategy   string
+	Confidence float64 // OCR confidence between 0 and 1, zero when no OCR was needed
+
+	// Document holds page and block structure, set for PDFs only
+	Document *pdf.ExtractedDocument
+}
+
+// Extractor turns a stored document into plain text
+type Extractor interface {
+	Extract(ctx context.Context, filePath string) (*Result, error)
+}
+
+// Registry maps MIME types to extractors
+type Registry struct {
+	extractors map[string]Extractor
+}
+
+// NewRegistry creates a registry with the PDF, DOCX, text and Markdown extractors
+func NewRegistry(pdfParser *pdf.Parser) *Registry {
+	r := &Registry{extractors: make(map[string]Extractor)}
+	r.Register(MimePDF, NewPDFExtractor(pdfParser))
+	r.Register(MimeDOCX, NewDOCXExtractor())
+	r.Register(MimeText, NewTextExtractor("plaintext"))
+	r.Register(MimeMarkdown, NewTextExtractor("markdown"))
+	return r
+}
+
+// Register adds or replaces the extractor for a MIME type
+func (r *Registry) Register(mimeType string, extractor Extractor) {
+	r.extractors[mimeType] = extractor
+}
+
+// Get returns the extractor registered for a MIME type
+func (r *Registry) Get(mimeType string) (Extractor, error) {
+	extractor, ok := r.extractors[mimeType]
+	if !ok {
+		return nil, fmt.Errorf("no extractor registered for %s", mimeType)
+	}
+	return extractor, nil
+}
+
+// Extract runs the extractor registered for mimeType against filePath,
+// stopping when ctx is done
+func (r *Registry) Extract(ctx context.Context, mimeType, filePath string) (*Result, error) {
+	extractor, err := r.Get(mimeType)
+	if err != nil {
+		return nil, err
+	}
+	return extractor.Extract(ctx, filePath)
+}
+
+var extensionTypes = map[string]string{
+	".pdf":      MimePDF,
+	".docx":     MimeDOCX,
+	".txt":      MimeText,
+	".md":       MimeMarkdown,
+	".markdown": MimeMarkdown,
+}
+
+// SupportedExtensions lists the file extensions that can be extracted
+func SupportedExtensions() []string {
+	return []string{".pdf", ".docx", ".txt", ".md", ".markdown"}
+}
+
+// MimeTypeFromFilename maps a filename extension to a supported MIME type
+func MimeTypeFromFilename(filename string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(filename))
+	mimeType, ok := extensionTypes[ext]
+	if !ok {
+		return "", fmt.Errorf("unsupported file type %q (allowed: %s)", ext, strings.Join(SupportedExtensions(), ", "))
+	}
+	return mimeType, nil
+}
+
+// ContentError reports a file whose content doesn't match its extension
+type ContentError struct {
+	Expected string // MIME type implied by the extension
+	Detected string // MIME type sniffed from the content
+}
+
+func (e *ContentError) Error() string {
+	return fmt.Sprintf("file content is not a valid %s (detected %s)", contentKinds[e.Expected], e.Detected)
+}
+
+// contentKinds names each supported MIME type in error messages
+var contentKinds = map[string]string{
+	MimePDF:      "PDF",
+	MimeDOCX:     "DOCX document",
+	MimeText:     "UTF-8 text file",
+	MimeMarkdown: "UTF-8 text file",
+}
+
+// DetectMimeType determines the MIME type from the filename and checks it
+// against the leading bytes of the file content. A mismatch is returned as
+// a *ContentError.
+func DetectMimeType(filename string, head []byte) (string, error) {
+	mimeType, err := MimeTypeFromFilename(filename)
+	if err != nil {
+		return "", err
+	}
+
+	var valid bool
+	switch mimeType {
+	case MimePDF:
+		valid = bytes.HasPrefix(head, []byte("%PDF-"))
+	case MimeDOCX:
+		valid = bytes.HasPrefix(head, []byte("PK\x03\x04"))
+	case MimeText, MimeMarkdown:
+		valid = bytes.IndexByte(head, 0) < 0 && utf8.Valid(trimPartialRune(head))
+	}
+	if !valid {
+		return "", &ContentError{Expected: mimeType, Detected: http.DetectContentType(head)}
+	}
+
+	return mimeType, nil
+}
+
+// trimPartialRune drops a multi-byte rune cut off at the end of a sniffed header
+func trimPartialRune(b []byte) []byte {
+	for i := 0; i < utf8.UTFMax && len(b) > 0; i++ {
+		r, size := utf8.DecodeLastRune(b)
+		if r != utf8.RuneError || size != 1 {
+			break
+		}
+		b = b[:len(b)-1]
+	}
+	return b
+}
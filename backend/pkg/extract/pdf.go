@@ -0,0 +1,33 @@
+package extract
+
+import (
+	"context"
+
+	"github.com/adyutaa/parsea/pkg/pdf"
+)
+
+// PDFExtractor extracts text from PDF files using pdf.Parser
+type PDFExtractor struct {
+	parser *pdf.Parser
+}
+
+func NewPDFExtractor(parser *pdf.Parser) *PDFExtractor {
+	if parser == nil {
+		parser = pdf.NewParser()
+	}
+	return &PDFExtractor{parser: parser}
+}
+
+func (e *PDFExtractor) Extract(ctx context.Context, filePath string) (*Result, error) {
+	extraction, err := e.parser.Extract(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Text:       e.parser.CleanText(extraction.Text),
+		Strategy:   extraction.Strategy,
+		Confidence: extraction.Confidence,
+		Document:   extraction.Document,
+	}, nil
+}
@@ -0,0 +1,39 @@
+package extract
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// TextExtractor reads plain text and Markdown files as-is
+type TextExtractor struct {
+	strategy string
+}
+
+func NewTextExtractor(strategy string) *TextExtractor {
+	return &TextExtractor{strategy: strategy}
+}
+
+func (e *TextExtractor) Extract(_ context.Context, filePath string) (*Result, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("file is not valid UTF-8 text")
+	}
+
+	text := strings.TrimPrefix(string(data), "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("no text content found in file")
+	}
+
+	return &Result{Text: text, Strategy: e.strategy}, nil
+}
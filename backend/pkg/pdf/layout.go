@@ -0,0 +1,462 @@
+package pdf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// PageBreak separates pages in flattened text, matching pdftotext output
+const PageBreak = "\f"
+
+type BlockType string
+
+const (
+	BlockHeading   BlockType = "heading"
+	BlockParagraph BlockType = "paragraph"
+	BlockBullet    BlockType = "bullet"
+)
+
+// Block is a run of text with a single role on the page
+type Block struct {
+	Type   BlockType `json:"type"`
+	Text   string    `json:"text"`
+	Column int       `json:"column"` // 0 for full width, 1 for left, 2 for right
+}
+
+// Page holds the blocks of one page in reading order
+type Page struct {
+	Number  int     `json:"number"`
+	Columns int     `json:"columns"`
+	Blocks  []Block `json:"blocks"`
+}
+
+// ExtractedDocument is the structured form of a PDF along with its flattened text
+type ExtractedDocument struct {
+	Pages []Page `json:"pages"`
+	Text  string `json:"text"`
+}
+
+// ExtractStructured returns the pages and blocks of a PDF in reading order
+func (p *Parser) ExtractStructured(ctx context.Context, filePath string) (*ExtractedDocument, error) {
+	extraction, err := p.Extract(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+	return extraction.Document, nil
+}
+
+// fragment is a horizontal run of glyphs on one baseline
+type fragment struct {
+	x0, x1   float64
+	y        float64
+	fontSize float64
+	text     string
+	column   int
+}
+
+// extractLayout rebuilds lines from glyph coordinates so that multi-column
+// pages are read one column at a time
+func (p *Parser) extractLayout(filePath string) (*ExtractedDocument, error) {
+	f, r, err := pdf.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PDF: %w", err)
+	}
+	defer f.Close()
+
+	doc := &ExtractedDocument{}
+	totalPages := r.NumPage()
+
+	for pageIndex := 1; pageIndex <= totalPages; pageIndex++ {
+		page := r.Page(pageIndex)
+		if page.V.IsNull() {
+			continue
+		}
+
+		texts, err := pageTexts(page)
+		if err != nil || len(texts) == 0 {
+			continue // Skip failed pages
+		}
+
+		fragments := buildFragments(texts)
+		ordered, columns := readingOrder(fragments)
+		doc.Pages = append(doc.Pages, Page{
+			Number:  pageIndex,
+			Columns: columns,
+			Blocks:  buildBlocks(ordered),
+		})
+	}
+
+	doc.Text = flatten(doc.Pages)
+	if strings.TrimSpace(doc.Text) == "" {
+		return nil, errors.New("no positioned text found in PDF")
+	}
+
+	return doc, nil
+}
+
+// pageTexts reads positioned glyphs, guarding against panics in malformed content streams
+func pageTexts(page pdf.Page) (texts []pdf.Text, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			texts = nil
+			err = fmt.Errorf("failed to read page content: %v", r)
+		}
+	}()
+	return page.Content().Text, nil
+}
+
+// buildFragments groups glyphs into rows sharing a baseline, then splits each
+// row into runs wherever the horizontal gap is too wide to be a word space
+func buildFragments(texts []pdf.Text) []fragment {
+	sort.SliceStable(texts, func(i, j int) bool {
+		return texts[i].Y > texts[j].Y
+	})
+
+	var rows [][]pdf.Text
+	for _, t := range texts {
+		if t.S == "" {
+			continue
+		}
+		last := len(rows) - 1
+		if last >= 0 && math.Abs(rows[last][0].Y-t.Y) <= baselineTolerance(rows[last][0], t) {
+			rows[last] = append(rows[last], t)
+			continue
+		}
+		rows = append(rows, []pdf.Text{t})
+	}
+
+	var fragments []fragment
+	for _, row := range rows {
+		sort.SliceStable(row, func(i, j int) bool {
+			return row[i].X < row[j].X
+		})
+		fragments = append(fragments, splitRow(row)...)
+	}
+
+	return fragments
+}
+
+func splitRow(row []pdf.Text) []fragment {
+	var (
+		fragments []fragment
+		current   *fragment
+		builder   strings.Builder
+	)
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		current.text = strings.Join(strings.Fields(builder.String()), " ")
+		if current.text != "" {
+			fragments = append(fragments, *current)
+		}
+		current = nil
+		builder.Reset()
+	}
+
+	for _, t := range row {
+		size := fontSize(t)
+
+		// Many fonts report no glyph width, so estimate it and only infer
+		// word spaces from clearly wider gaps
+		width, spaceGap := t.W, size*0.25
+		if width <= 0 {
+			width, spaceGap = float64(utf8.RuneCountInString(t.S))*size*0.45, size*0.6
+		}
+
+		if current != nil {
+			gap := t.X - current.x1
+			if gap > size*3 {
+				flush()
+			} else if gap > spaceGap && !strings.HasSuffix(builder.String(), " ") && t.S != " " {
+				builder.WriteString(" ")
+			}
+		}
+
+		if current == nil {
+			current = &fragment{x0: t.X, x1: t.X, y: t.Y, fontSize: size}
+		}
+		builder.WriteString(strings.ReplaceAll(t.S, string(utf8.RuneError), ""))
+		current.x1 = math.Max(current.x1, t.X+width)
+		current.fontSize = math.Max(current.fontSize, size)
+	}
+	flush()
+
+	return fragments
+}
+
+func fontSize(t pdf.Text) float64 {
+	if t.FontSize <= 0 {
+		return 10
+	}
+	return t.FontSize
+}
+
+func baselineTolerance(a, b pdf.Text) float64 {
+	return math.Max(fontSize(a), fontSize(b)) * 0.4
+}
+
+// readingOrder detects a two-column layout and orders fragments column by
+// column, using full-width fragments (such as section titles) as separators
+func readingOrder(fragments []fragment) ([]fragment, int) {
+	gutter, ok := findGutter(fragments)
+	if !ok {
+		return fragments, 1
+	}
+
+	var ordered, left, right []fragment
+	flushColumns := func() {
+		ordered = append(ordered, left...)
+		ordered = append(ordered, right...)
+		left, right = nil, nil
+	}
+
+	for _, f := range fragments {
+		switch {
+		case f.x1 <= gutter:
+			f.column = 1
+			left = append(left, f)
+		case f.x0 >= gutter:
+			f.column = 2
+			right = append(right, f)
+		default:
+			flushColumns()
+			ordered = append(ordered, f)
+		}
+	}
+	flushColumns()
+
+	return ordered, 2
+}
+
+// findGutter looks for a vertical line in the middle of the page that few
+// fragments cross but many fragments sit on either side of
+func findGutter(fragments []fragment) (float64, bool) {
+	if len(fragments) < 6 {
+		return 0, false
+	}
+
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	for _, f := range fragments {
+		minX = math.Min(minX, f.x0)
+		maxX = math.Max(maxX, f.x1)
+	}
+	width := maxX - minX
+	if width <= 0 {
+		return 0, false
+	}
+
+	bestX, bestCrossing, bestBalance := 0.0, len(fragments)+1, 0
+	for x := minX + width*0.25; x <= minX+width*0.75; x += 2 {
+		left, right, crossing := 0, 0, 0
+		for _, f := range fragments {
+			switch {
+			case f.x1 <= x:
+				left++
+			case f.x0 >= x:
+				right++
+			default:
+				crossing++
+			}
+		}
+
+		balance := min(left, right)
+		if balance < 3 || crossing*100 > len(fragments)*15 {
+			continue
+		}
+		if crossing < bestCrossing || (crossing == bestCrossing && balance > bestBalance) {
+			bestX, bestCrossing, bestBalance = x, crossing, balance
+		}
+	}
+
+	// Require both columns to hold a meaningful share of the page
+	if bestCrossing > len(fragments) || bestBalance*100 < len(fragments)*20 {
+		return 0, false
+	}
+	return bestX, true
+}
+
+var bulletPattern = regexp.MustCompile(`^(?:[•●▪◦‣∙·\-\*–]|\d{1,2}[.)])\s+`)
+
+// buildBlocks classifies lines as headings, bullets or paragraph text and
+// merges continuation lines
+func buildBlocks(lines []fragment) []Block {
+	bodySize := medianFontSize(lines)
+
+	var (
+		blocks []Block
+		prev   *fragment
+	)
+
+	for i := range lines {
+		line := lines[i]
+		text := line.text
+
+		switch {
+		case isBullet(text):
+			blocks = append(blocks, Block{
+				Type:   BlockBullet,
+				Text:   strings.TrimSpace(bulletPattern.ReplaceAllString(text, "")),
+				Column: line.column,
+			})
+		case isHeading(text, line.fontSize, bodySize):
+			blocks = append(blocks, Block{Type: BlockHeading, Text: text, Column: line.column})
+		default:
+			last := len(blocks) - 1
+			if last >= 0 && prev != nil && continues(blocks[last], *prev, line) {
+				blocks[last].Text = joinLines(blocks[last].Text, text)
+			} else {
+				blocks = append(blocks, Block{Type: BlockParagraph, Text: text, Column: line.column})
+			}
+		}
+		prev = &lines[i]
+	}
+
+	return blocks
+}
+
+// continues reports whether line carries on the previous paragraph or bullet
+func continues(block Block, prev, line fragment) bool {
+	if block.Type == BlockHeading || block.Column != line.column {
+		return false
+	}
+	gap := prev.y - line.y
+	if gap <= 0 || gap > math.Max(prev.fontSize, line.fontSize)*1.8 {
+		return false
+	}
+	if block.Type == BlockBullet {
+		// Wrapped bullet text is indented past the bullet glyph
+		return line.x0 > prev.x0-1
+	}
+	return true
+}
+
+func joinLines(a, b string) string {
+	if strings.HasSuffix(a, "-") && !strings.HasSuffix(a, " -") {
+		return strings.TrimSuffix(a, "-") + b
+	}
+	return a + " " + b
+}
+
+func isBullet(text string) bool {
+	return bulletPattern.MatchString(text)
+}
+
+// isHeading uses font size where available and falls back to short
+// all-caps or colon-terminated lines
+func isHeading(text string, fontSize, bodySize float64) bool {
+	words := len(strings.Fields(text))
+	if words == 0 || words > 8 || len(text) > 80 {
+		return false
+	}
+	if bodySize > 0 && fontSize >= bodySize*1.2 {
+		return true
+	}
+	if strings.HasSuffix(text, ":") && words <= 5 {
+		return true
+	}
+	return words <= 6 && isUpper(text)
+}
+
+func isUpper(text string) bool {
+	letters := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			if !unicode.IsUpper(r) {
+				return false
+			}
+			letters++
+		}
+	}
+	return letters >= 3
+}
+
+func medianFontSize(lines []fragment) float64 {
+	if len(lines) == 0 {
+		return 0
+	}
+	sizes := make([]float64, 0, len(lines))
+	for _, l := range lines {
+		if l.fontSize > 0 {
+			sizes = append(sizes, l.fontSize)
+		}
+	}
+	if len(sizes) == 0 {
+		return 0
+	}
+	sort.Float64s(sizes)
+	return sizes[len(sizes)/2]
+}
+
+// StructureText builds an ExtractedDocument from plain text, as produced by
+// pdftotext or OCR, using form feeds as page breaks
+func StructureText(text string) *ExtractedDocument {
+	doc := &ExtractedDocument{}
+
+	for i, pageText := range strings.Split(text, PageBreak) {
+		var lines []fragment
+		y := 0.0
+		for _, raw := range strings.Split(pageText, "\n") {
+			y -= 10
+			line := strings.Join(strings.Fields(raw), " ")
+			if line == "" {
+				// A blank line ends the current paragraph
+				y -= 100
+				continue
+			}
+			lines = append(lines, fragment{y: y, fontSize: 10, text: line})
+		}
+		if len(lines) == 0 {
+			continue
+		}
+
+		doc.Pages = append(doc.Pages, Page{
+			Number:  i + 1,
+			Columns: 1,
+			Blocks:  buildBlocks(lines),
+		})
+	}
+
+	doc.Text = flatten(doc.Pages)
+	return doc
+}
+
+// flatten renders blocks as lightly formatted text: headings are prefixed
+// with "##", bullets with "-", and pages are separated by form feeds
+func flatten(pages []Page) string {
+	renderedPages := make([]string, 0, len(pages))
+
+	for _, page := range pages {
+		var builder strings.Builder
+		for i, block := range page.Blocks {
+			if i > 0 {
+				if block.Type == BlockBullet && page.Blocks[i-1].Type == BlockBullet {
+					builder.WriteString("\n")
+				} else {
+					builder.WriteString("\n\n")
+				}
+			}
+			switch block.Type {
+			case BlockHeading:
+				builder.WriteString("## " + block.Text)
+			case BlockBullet:
+				builder.WriteString("- " + block.Text)
+			default:
+				builder.WriteString(block.Text)
+			}
+		}
+		renderedPages = append(renderedPages, builder.String())
+	}
+
+	return strings.Join(renderedPages, "\n"+PageBreak+"\n")
+}
@@ -0,0 +1,210 @@
+package pdf
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// OCRConfig controls the pdftoppm + tesseract fallback used for scanned PDFs
+type OCRConfig struct {
+	Enabled     bool
+	Languages   []string      // tesseract language codes, e.g. "eng", "ind"
+	DPI         int           // rasterisation resolution
+	PageTimeout time.Duration // limit for rasterising and recognising one page
+	MaxPages    int           // pages beyond this are skipped, 0 means no limit
+}
+
+// DefaultOCRConfig returns the settings used when none are provided
+func DefaultOCRConfig() OCRConfig {
+	return OCRConfig{
+		Enabled:     true,
+		Languages:   []string{"eng"},
+		DPI:         300,
+		PageTimeout: 30 * time.Second,
+		MaxPages:    20,
+	}
+}
+
+// ocrPage holds the recognised text of a single page
+type ocrPage struct {
+	text       string
+	confidence float64
+	words      int
+}
+
+func (p *Parser) extractWithOCR(ctx context.Context, filePath string) (string, float64, error) {
+	if !p.ocr.Enabled {
+		return "", 0, fmt.Errorf("OCR disabled")
+	}
+	if _, err := exec.LookPath("pdftoppm"); err != nil {
+		return "", 0, fmt.Errorf("pdftoppm not available: %w", err)
+	}
+	if _, err := exec.LookPath("tesseract"); err != nil {
+		return "", 0, fmt.Errorf("tesseract not available: %w", err)
+	}
+
+	totalPages, err := countPages(ctx, filePath)
+	if err != nil {
+		return "", 0, err
+	}
+	if p.ocr.MaxPages > 0 && totalPages > p.ocr.MaxPages {
+		totalPages = p.ocr.MaxPages
+	}
+
+	tmpDir, err := os.MkdirTemp("", "parsea-ocr-*")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create OCR temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	var (
+		textBuilder     strings.Builder
+		confidenceTotal float64
+		wordTotal       int
+		lastErr         error
+	)
+
+	for pageIndex := 1; pageIndex <= totalPages; pageIndex++ {
+		// A cancelled or timed-out job stops here rather than after every page
+		if err := ctx.Err(); err != nil {
+			return "", 0, fmt.Errorf("OCR stopped at page %d: %w", pageIndex, err)
+		}
+		page, err := p.ocrPage(ctx, filePath, tmpDir, pageIndex)
+		if err != nil {
+			lastErr = err
+			continue // Skip failed pages
+		}
+
+		textBuilder.WriteString(page.text)
+		textBuilder.WriteString("\n" + PageBreak)
+		confidenceTotal += page.confidence * float64(page.words)
+		wordTotal += page.words
+	}
+
+	if wordTotal == 0 {
+		if lastErr != nil {
+			return "", 0, fmt.Errorf("OCR produced no text: %w", lastErr)
+		}
+		return "", 0, fmt.Errorf("OCR produced no text")
+	}
+
+	// Word-weighted mean of tesseract confidences, scaled to 0-1
+	return textBuilder.String(), confidenceTotal / float64(wordTotal) / 100, nil
+}
+
+// ocrPage rasterises one page and runs tesseract on it within the page
+// timeout, or less if ctx ends sooner
+func (p *Parser) ocrPage(ctx context.Context, filePath, tmpDir string, pageIndex int) (*ocrPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.ocr.PageTimeout)
+	defer cancel()
+
+	prefix := filepath.Join(tmpDir, fmt.Sprintf("page-%d", pageIndex))
+	page := strconv.Itoa(pageIndex)
+
+	rasterise := exec.CommandContext(ctx, "pdftoppm",
+		"-r", strconv.Itoa(p.ocr.DPI),
+		"-f", page, "-l", page,
+		"-gray", "-png", "-singlefile",
+		filePath, prefix,
+	)
+	if output, err := rasterise.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("pdftoppm failed on page %d: %w: %s", pageIndex, err, bytes.TrimSpace(output))
+	}
+
+	recognise := exec.CommandContext(ctx, "tesseract",
+		prefix+".png", "stdout",
+		"-l", strings.Join(p.ocr.Languages, "+"),
+		"tsv",
+	)
+	output, err := recognise.Output()
+	if err != nil {
+		return nil, fmt.Errorf("tesseract failed on page %d: %w", pageIndex, err)
+	}
+
+	return parseTesseractTSV(output), nil
+}
+
+// parseTesseractTSV rebuilds line text from tesseract's TSV output and
+// averages the confidence of recognised words
+func parseTesseractTSV(data []byte) *ocrPage {
+	var (
+		lines           []string
+		current         []string
+		currentKey      string
+		confidenceTotal float64
+		words           int
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		// level page_num block_num par_num line_num word_num left top width height conf text
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < 12 || fields[0] != "5" {
+			continue
+		}
+
+		confidence, err := strconv.ParseFloat(fields[10], 64)
+		word := strings.TrimSpace(fields[11])
+		if err != nil || confidence < 0 || word == "" {
+			continue
+		}
+
+		key := strings.Join(fields[1:5], ".")
+		if key != currentKey && len(current) > 0 {
+			lines = append(lines, strings.Join(current, " "))
+			current = nil
+		}
+		currentKey = key
+		current = append(current, word)
+
+		confidenceTotal += confidence
+		words++
+	}
+	if len(current) > 0 {
+		lines = append(lines, strings.Join(current, " "))
+	}
+
+	page := &ocrPage{text: strings.Join(lines, "\n"), words: words}
+	if words > 0 {
+		page.confidence = confidenceTotal / float64(words)
+	}
+	return page
+}
+
+var pdfInfoPages = regexp.MustCompile(`(?m)^Pages:\s+(\d+)`)
+
+// countPages reads the page count with ledongthuc/pdf, falling back to pdfinfo
+func countPages(ctx context.Context, filePath string) (int, error) {
+	if f, r, err := pdf.Open(filePath); err == nil {
+		defer f.Close()
+		if n := r.NumPage(); n > 0 {
+			return n, nil
+		}
+	}
+
+	if _, err := exec.LookPath("pdfinfo"); err != nil {
+		return 0, fmt.Errorf("unable to determine page count: %w", err)
+	}
+
+	output, err := exec.CommandContext(ctx, "pdfinfo", filePath).Output()
+	if err != nil {
+		return 0, fmt.Errorf("pdfinfo failed: %w", err)
+	}
+
+	match := pdfInfoPages.FindSubmatch(output)
+	if match == nil {
+		return 0, fmt.Errorf("pdfinfo did not report a page count")
+	}
+	return strconv.Atoi(string(match[1]))
+}
@@ -2,6 +2,7 @@ package pdf
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os/exec"
 	"strings"
@@ -9,27 +10,91 @@ import (
 	"github.com/ledongthuc/pdf"
 )
 
-type Parser struct{}
+// Extraction strategies, in the order they are attempted
+const (
+	StrategyLedong    = "ledongthuc"
+	StrategyPdfToText = "pdftotext"
+	StrategyOCR       = "ocr"
+)
+
+type Parser struct {
+	ocr OCRConfig
+}
 
 func NewParser() *Parser {
-	return &Parser{}
+	return NewParserWithOCR(DefaultOCRConfig())
+}
+
+func NewParserWithOCR(ocr OCRConfig) *Parser {
+	defaults := DefaultOCRConfig()
+	if len(ocr.Languages) == 0 {
+		ocr.Languages = defaults.Languages
+	}
+	if ocr.DPI <= 0 {
+		ocr.DPI = defaults.DPI
+	}
+	if ocr.PageTimeout <= 0 {
+		ocr.PageTimeout = defaults.PageTimeout
+	}
+	return &Parser{ocr: ocr}
+}
+
+// Extraction is the text of a PDF together with the strategy that produced it
+type Extraction struct {
+	Text       string
+	Document   *ExtractedDocument
+	Strategy   string
+	Confidence float64 // OCR confidence between 0 and 1, zero for text-layer strategies
 }
 
-func (p *Parser) ExtractText(filePath string) (string, error) {
-	// Try method 1: ledongthuc/pdf library
+// Extract tries the embedded text layer first and falls back to OCR for
+// scanned PDFs. The external tools are stopped when ctx is done.
+func (p *Parser) Extract(ctx context.Context, filePath string) (*Extraction, error) {
+	// Try method 1: ledongthuc/pdf library, layout-aware and then plain
+	if doc, err := p.extractLayout(filePath); err == nil {
+		return &Extraction{Text: doc.Text, Document: doc, Strategy: StrategyLedong}, nil
+	}
 	text, err := p.extractWithLedong(filePath)
 	if err == nil && len(strings.TrimSpace(text)) > 0 {
-		return text, nil
+		return newExtraction(text, StrategyLedong, 0), nil
 	}
 
 	// Try method 2: pdftotext (if available)
-	text, err = p.extractWithPdfToText(filePath)
+	text, err = p.extractWithPdfToText(ctx, filePath)
+	if err == nil && len(strings.TrimSpace(text)) > 0 {
+		return newExtraction(text, StrategyPdfToText, 0), nil
+	}
+
+	// Try method 3: rasterise and OCR (if pdftoppm and tesseract are available)
+	text, confidence, err := p.extractWithOCR(ctx, filePath)
 	if err == nil && len(strings.TrimSpace(text)) > 0 {
-		return text, nil
+		return newExtraction(text, StrategyOCR, confidence), nil
 	}
 
-	// If both methods fail
-	return "", fmt.Errorf("no text content found in PDF")
+	// If all methods fail
+	if err != nil {
+		return nil, fmt.Errorf("no text content found in PDF (OCR: %w)", err)
+	}
+	return nil, fmt.Errorf("no text content found in PDF")
+}
+
+// newExtraction structures plain text from the fallback strategies
+func newExtraction(text, strategy string, confidence float64) *Extraction {
+	doc := StructureText(text)
+	return &Extraction{
+		Text:       doc.Text,
+		Document:   doc,
+		Strategy:   strategy,
+		Confidence: confidence,
+	}
+}
+
+func (p *Parser) ExtractText(ctx context.Context, filePath string) (string, error) {
+	extraction, err := p.Extract(ctx, filePath)
+	if err != nil {
+		return "", err
+	}
+	return extraction.Text, nil
 }
 
 func (p *Parser) extractWithLedong(filePath string) (string, error) {
@@ -54,20 +119,20 @@ func (p *Parser) extractWithLedong(filePath string) (string, error) {
 		}
 
 		textBuilder.WriteString(text)
-		textBuilder.WriteString("\n")
+		textBuilder.WriteString("\n" + PageBreak)
 	}
 
 	return textBuilder.String(), nil
 }
 
-func (p *Parser) extractWithPdfToText(filePath string) (string, error) {
+func (p *Parser) extractWithPdfToText(ctx context.Context, filePath string) (string, error) {
 	// Check if pdftotext is available
 	if _, err := exec.LookPath("pdftotext"); err != nil {
 		return "", fmt.Errorf("pdftotext not available: %w", err)
 	}
 
 	// Run pdftotext command
-	cmd := exec.Command("pdftotext", filePath, "-")
+	cmd := exec.CommandContext(ctx, "pdftotext", filePath, "-")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("pdftotext failed: %w", err)
@@ -103,18 +168,33 @@ func (p *Parser) ExtractTextFromBytes(data []byte) (string, error) {
 	return textBuilder.String(), nil
 }
 
+// CleanText collapses whitespace within each line while keeping line
+// breaks, single blank lines between paragraphs and page breaks
 func (p *Parser) CleanText(text string) string {
-
-	text = strings.Join(strings.Fields(text), " ")
-
-	lines := strings.Split(text, "\n")
-	var cleanedLines []string
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line != "" {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	pages := strings.Split(text, PageBreak)
+	cleanedPages := make([]string, 0, len(pages))
+
+	for _, page := range pages {
+		var cleanedLines []string
+		blank := false
+		for _, line := range strings.Split(page, "\n") {
+			line = strings.Join(strings.Fields(line), " ")
+			if line == "" {
+				blank = len(cleanedLines) > 0
+				continue
+			}
+			if blank {
+				cleanedLines = append(cleanedLines, "")
+				blank = false
+			}
 			cleanedLines = append(cleanedLines, line)
 		}
+		if len(cleanedLines) > 0 {
+			cleanedPages = append(cleanedPages, strings.Join(cleanedLines, "\n"))
+		}
 	}
 
-	return strings.Join(cleanedLines, "\n")
+	return strings.Join(cleanedPages, "\n"+PageBreak+"\n")
 }
@@ -0,0 +1,349 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// A4 page geometry in points
+const (
+	PageWidth   = 595.28
+	PageHeight  = 841.89
+	PageMargin  = 56.0
+	footerSpace = 28.0
+)
+
+// ContentWidth is the width available between the margins
+const ContentWidth = PageWidth - 2*PageMargin
+
+// Color is an RGB color with components from 0 to 1
+type Color struct{ R, G, B float64 }
+
+var (
+	Black     = Color{0, 0, 0}
+	Gray      = Color{0.45, 0.45, 0.45}
+	LightGray = Color{0.9, 0.9, 0.9}
+)
+
+// Style sets the font and color of a run of text
+type Style struct {
+	Size  float64
+	Bold  bool
+	Color Color
+}
+
+// Document writes a simple A4 PDF using the standard Helvetica fonts, which
+// every reader provides, so no font files are embedded. Content flows from
+// the top of the page down and continues on a new page when it runs out of
+// room. Text is encoded as WinAnsi; characters outside it print as "?".
+type Document struct {
+	title   string
+	footer  string
+	created time.Time
+	pages   []*bytes.Buffer
+	page    *bytes.Buffer
+	y       float64 // baseline cursor, measured from the bottom of the page
+}
+
+// NewDocument starts a document; footer is printed on every page next to
+// the page number
+func NewDocument(title, footer string) *Document {
+	d := &Document{title: title, footer: footer, created: time.Now()}
+	d.AddPage()
+	return d
+}
+
+// AddPage starts a new page and moves the cursor to its top
+func (d *Document) AddPage() {
+	d.page = &bytes.Buffer{}
+	d.pages = append(d.pages, d.page)
+	d.y = PageHeight - PageMargin
+}
+
+// ensure starts a new page unless height points fit above the footer
+func (d *Document) ensure(height float64) {
+	if d.y-height < PageMargin+footerSpace {
+		d.AddPage()
+	}
+}
+
+// Space moves the cursor down
+func (d *Document) Space(height float64) {
+	d.y -= height
+}
+
+// Text writes wrapped text across the content width. Blank lines in text
+// separate paragraphs.
+func (d *Document) Text(text string, style Style) {
+	d.TextAt(PageMargin, ContentWidth, text, style)
+}
+
+// TextAt writes wrapped text in a column starting x points from the left
+func (d *Document) TextAt(x, width float64, text string, style Style) {
+	leading := style.Size * 1.35
+	for _, line := range wrap(text, width, style) {
+		d.ensure(leading)
+		d.y -= leading
+		if line != "" {
+			d.text(x, d.y+leading*0.25, line, style)
+		}
+	}
+}
+
+// Row writes one line of cells side by side, each starting at its x offset
+// from the left margin; it wraps no text, so cells must fit their column
+func (d *Document) Row(offsets []float64, cells []string, style Style) {
+	leading := style.Size * 1.35
+	d.ensure(leading)
+	d.y -= leading
+	for i, cell := range cells {
+		if i < len(offsets) {
+			d.text(PageMargin+offsets[i], d.y+leading*0.25, cell, style)
+		}
+	}
+}
+
+// Rule draws a horizontal line across the content width
+func (d *Document) Rule(color Color) {
+	d.ensure(8)
+	d.y -= 4
+	fmt.Fprintf(d.page, "%s RG 0.75 w %.2f %.2f m %.2f %.2f l S\n", rgb(color), PageMargin, d.y, PageWidth-PageMargin, d.y)
+	d.y -= 4
+}
+
+// Bar draws a labelled horizontal bar filled to value/scale, followed by
+// caption, e.g. "4.0 / 5"
+func (d *Document) Bar(label string, value, scale float64, caption string, fill Color) {
+	const (
+		height   = 16.0
+		labelW   = 190.0
+		captionW = 70.0
+		barH     = 8.0
+	)
+	style := Style{Size: 10, Color: Black}
+	d.ensure(height)
+	d.y -= height
+	baseline := d.y + 4
+
+	d.text(PageMargin, baseline, fitText(label, labelW-8, style), style)
+
+	barX := PageMargin + labelW
+	barW := ContentWidth - labelW - captionW
+	fraction := 0.0
+	if scale > 0 {
+		fraction = max(0, min(1, value/scale))
+	}
+	fmt.Fprintf(d.page, "%s rg %.2f %.2f %.2f %.2f re f\n", rgb(LightGray), barX, baseline-1, barW, barH)
+	if fraction > 0 {
+		fmt.Fprintf(d.page, "%s rg %.2f %.2f %.2f %.2f re f\n", rgb(fill), barX, baseline-1, barW*fraction, barH)
+	}
+	d.text(barX+barW+8, baseline, caption, style)
+}
+
+// text draws one line at an absolute position
+func (d *Document) text(x, y float64, s string, style Style) {
+	font := "F1"
+	if style.Bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.page, "BT %s rg /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", rgb(style.Color), font, style.Size, x, y, escape(encode(s)))
+}
+
+// WriteTo writes the finished PDF, numbering the pages
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1-5 are fixed; each page then takes a page and a content object
+	const firstPage = 6
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object(fmt.Sprintf("<< /Title (%s) /Producer (parsea) /CreationDate (D:%s) >>",
+		escape(encode(d.title)), d.created.UTC().Format("20060102150405Z")))
+
+	footerStyle := Style{Size: 8, Color: Gray}
+	for i, page := range d.pages {
+		content := bytes.NewBuffer(page.Bytes())
+		number := fmt.Sprintf("Page %d of %d", i+1, len(d.pages))
+		footerY := PageMargin - 12
+		fmt.Fprintf(content, "BT %s rg /F1 8 Tf %.2f %.2f Td (%s) Tj ET\n", rgb(footerStyle.Color), PageMargin, footerY, escape(encode(d.footer)))
+		fmt.Fprintf(content, "BT %s rg /F1 8 Tf %.2f %.2f Td (%s) Tj ET\n", rgb(footerStyle.Color),
+			PageWidth-PageMargin-textWidth(number, footerStyle), footerY, number)
+
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		if _, err := zw.Write(content.Bytes()); err != nil {
+			return 0, err
+		}
+		if err := zw.Close(); err != nil {
+			return 0, err
+		}
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			PageWidth, PageHeight, firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", compressed.Len(), compressed.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.WriteTo(w)
+}
+
+func rgb(c Color) string {
+	return fmt.Sprintf("%.3f %.3f %.3f", c.R, c.G, c.B)
+}
+
+// wrap breaks text into lines no wider than width; an empty string marks a
+// blank line between paragraphs
+func wrap(text string, width float64, style Style) []string {
+	var lines []string
+	for i, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if i > 0 && strings.TrimSpace(paragraph) == "" {
+			lines = append(lines, "")
+			continue
+		}
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if textWidth(candidate, style) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			// A word wider than the line is split wherever it overflows
+			for textWidth(word, style) > width {
+				cut := fitPrefix(word, width, style)
+				lines = append(lines, word[:cut])
+				word = word[cut:]
+			}
+			line = word
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// fitText shortens s with an ellipsis until it fits width
+func fitText(s string, width float64, style Style) string {
+	if textWidth(s, style) <= width {
+		return s
+	}
+	return strings.TrimRight(s[:fitPrefix(s, width-textWidth("...", style), style)], " ") + "..."
+}
+
+// fitPrefix returns the byte length of the longest prefix of s, at least one
+// rune, that fits width
+func fitPrefix(s string, width float64, style Style) int {
+	end := 0
+	for i, r := range s {
+		if i > 0 && textWidth(s[:i+len(string(r))], style) > width {
+			break
+		}
+		end = i + len(string(r))
+	}
+	return end
+}
+
+// textWidth measures s in points
+func textWidth(s string, style Style) float64 {
+	widths := &helveticaWidths
+	if style.Bold {
+		widths = &helveticaBoldWidths
+	}
+	total := 0
+	for _, b := range encode(s) {
+		if b >= 32 && b <= 126 {
+			total += widths[b-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * style.Size / 1000
+}
+
+// winAnsi maps the characters outside Latin-1 that WinAnsiEncoding covers
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// encode converts s to WinAnsi bytes
+func encode(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r == '\t':
+			out = append(out, ' ')
+		case r >= 32 && r <= 126, r >= 0xA0 && r <= 0xFF:
+			out = append(out, byte(r))
+		default:
+			if b, ok := winAnsi[r]; ok {
+				out = append(out, b)
+			} else {
+				out = append(out, '?')
+			}
+		}
+	}
+	return out
+}
+
+// escape quotes a PDF literal string
+func escape(b []byte) string {
+	var sb strings.Builder
+	for _, c := range b {
+		switch c {
+		case '(', ')', '\\':
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+// Glyph widths of characters 32-126 per 1000 units of font size, from the
+// Adobe font metrics of the standard fonts
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
@@ -0,0 +1,115 @@
+package redact
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Attribute identifies a protected characteristic removed in blind evaluations
+type Attribute string
+
+const (
+	AttributeGender      Attribute = "gender"
+	AttributeAge         Attribute = "age"
+	AttributePhoto       Attribute = "photo"
+	AttributeNationality Attribute = "nationality"
+	AttributeInstitution Attribute = "institution"
+)
+
+type blindRule struct {
+	attribute   Attribute
+	re          *regexp.Regexp
+	replacement string
+}
+
+// Rules run in order; labelled fields are handled before free-text patterns
+var blindRules = []blindRule{
+	// Labelled personal fields, e.g. "Gender: Female" or "Date of Birth: 1 May 1995"
+	{AttributeGender, regexp.MustCompile(`(?im)^[ \t]*(?:gender|sex|jenis kelamin)\s*:[^\n]*\n?`), ""},
+	{AttributeGender, regexp.MustCompile(`(?im)^[ \t]*(?:marital status|status pernikahan|religion|agama)\s*:[^\n]*\n?`), ""},
+	{AttributeAge, regexp.MustCompile(`(?im)^[ \t]*(?:age|usia|umur|date of birth|birth ?date|dob|born|place(?:,| and)? date of birth|tempat(?:,|/)? ?tanggal lahir|tanggal lahir)\s*:[^\n]*\n?`), ""},
+	{AttributeNationality, regexp.MustCompile(`(?im)^[ \t]*(?:nationality|citizenship|kewarganegaraan|ethnicity|race)\s*:[^\n]*\n?`), ""},
+	{AttributePhoto, regexp.MustCompile(`(?im)^[ \t]*\[?[ \t]*(?:photo|picture|foto|headshot|pas foto)\b[^\n]{0,60}\n?`), ""},
+
+	// Inline mentions
+	{AttributeAge, regexp.MustCompile(`(?i)\b\d{2}\s*(?:years?|yrs?|tahun)\s*old\b`), "[AGE]"},
+	{AttributeAge, regexp.MustCompile(`(?i)\bborn\s+(?:on\s+|in\s+)?(?:\d{1,2}\s+)?(?:[A-Za-z]+\s+)?\d{4}\b`), "[BIRTH DATE]"},
+	{AttributeGender, regexp.MustCompile(`\b(?:Mr|Mrs|Ms|Miss|Mx)\.?\s+`), ""},
+
+	// Educational institutions, e.g. "Bandung Institute of Technology", "University of Indonesia"
+	{AttributeInstitution, regexp.MustCompile(`\b(?:[A-Z][\w&'\-]*[ \t]+){1,5}(?:University|College|Institute(?:[ \t]+of[ \t]+Technology)?|Polytechnic|Academy|High[ \t]+School|School)\b`), "[INSTITUTION]"},
+	{AttributeInstitution, regexp.MustCompile(`\b(?:University|Universitas|Institut|Institute|College|Politeknik|Polytechnic|Academy|Akademi|Sekolah[ \t]+Tinggi|SMA|SMK)(?:[ \t]+(?:of|for|de|the|Negeri))*(?:[ \t]+[A-Z][\w&'\-]*)+`), "[INSTITUTION]"},
+}
+
+var pronouns = map[string]string{
+	"he": "they", "she": "they",
+	"him": "them", "his": "their", "hers": "theirs",
+	"himself": "themselves", "herself": "themselves",
+}
+
+var pronounPattern = regexp.MustCompile(`(?i)\b(?:he|she|him|his|her|hers|himself|herself)\b`)
+
+// nextWordPattern matches the word right after a pronoun, if any
+var nextWordPattern = regexp.MustCompile(`^[ \t]+([A-Za-z]+)`)
+
+// objectFollowers are words that can follow object "her" ("gave her the
+// award", "worked with her on") but not possessive "her" ("her team")
+var objectFollowers = map[string]bool{
+	"a": true, "an": true, "the": true, "this": true, "that": true, "these": true, "those": true,
+	"my": true, "your": true, "his": true, "her": true, "our": true, "their": true, "its": true,
+	"some": true, "any": true, "all": true, "every": true, "each": true, "no": true,
+	"to": true, "for": true, "with": true, "on": true, "in": true, "at": true, "by": true,
+	"from": true, "of": true, "about": true, "as": true, "into": true, "onto": true, "over": true,
+	"through": true, "during": true, "after": true, "before": true, "since": true, "until": true,
+	"up": true, "down": true, "out": true, "off": true, "back": true, "again": true,
+	"and": true, "or": true, "but": true, "nor": true, "so": true, "because": true,
+	"when": true, "while": true, "if": true, "than": true, "then": true, "too": true,
+}
+
+// neutralHer tells possessive "her" (their) from object "her" (them) by the
+// word that follows: a noun or adjective makes it possessive
+func neutralHer(after string) string {
+	m := nextWordPattern.FindStringSubmatch(after)
+	if m == nil {
+		return "them" // end of sentence or clause
+	}
+	if objectFollowers[strings.ToLower(m[1])] {
+		return "them"
+	}
+	return "their"
+}
+
+// Blind strips or neutralises protected attributes (gender, age, photo
+// captions, nationality and school names) and reports how many were changed
+func Blind(text string) (string, map[Attribute]int) {
+	counts := make(map[Attribute]int)
+
+	for _, rule := range blindRules {
+		n := len(rule.re.FindAllStringIndex(text, -1))
+		if n == 0 {
+			continue
+		}
+		counts[rule.attribute] += n
+		text = rule.re.ReplaceAllString(text, rule.replacement)
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range pronounPattern.FindAllStringIndex(text, -1) {
+		p := text[loc[0]:loc[1]]
+		neutral := pronouns[strings.ToLower(p)]
+		if neutral == "" {
+			neutral = neutralHer(text[loc[1]:])
+		}
+		if p[0] >= 'A' && p[0] <= 'Z' {
+			neutral = strings.ToUpper(neutral[:1]) + neutral[1:]
+		}
+		counts[AttributeGender]++
+		b.WriteString(text[last:loc[0]])
+		b.WriteString(neutral)
+		last = loc[1]
+	}
+	b.WriteString(text[last:])
+
+	return b.String(), counts
+}
@@ -0,0 +1,41 @@
+package redact
+
+import "testing"
+
+func TestBlindPronouns(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"subject", "She led the migration.", "They led the migration."},
+		{"possessive her", "She grew her team to eight engineers.", "They grew their team to eight engineers."},
+		{"possessive her before adjective", "Her early work was on compilers.", "Their early work was on compilers."},
+		{"object her at end of sentence", "The manager promoted her.", "The manager promoted them."},
+		{"object her before comma", "We hired her, then trained her.", "We hired them, then trained them."},
+		{"object her before article", "They gave her the award.", "They gave them the award."},
+		{"object her before preposition", "I worked with her on the API.", "I worked with them on the API."},
+		{"object her before conjunction", "Ask her and the lead.", "Ask them and the lead."},
+		{"hers", "The idea was hers.", "The idea was theirs."},
+		{"herself", "She taught herself Go.", "They taught themselves Go."},
+		{"masculine forms", "He told him his plan himself.", "They told them their plan themselves."},
+		{"capitalised her", "Her team shipped.", "Their team shipped."},
+		{"words containing pronouns", "Hershey, the hero, shed there.", "Hershey, the hero, shed there."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := Blind(tt.in)
+			if got != tt.want {
+				t.Errorf("Blind(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlindCountsPronouns(t *testing.T) {
+	_, counts := Blind("She said her manager thanked her.")
+	if got := counts[AttributeGender]; got != 3 {
+		t.Errorf("gender count = %d, want 3", got)
+	}
+}
@@ -0,0 +1,302 @@
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Kind identifies a category of personal data
+type Kind string
+
+const (
+	KindEmail      Kind = "EMAIL"
+	KindPhone      Kind = "PHONE"
+	KindURL        Kind = "URL"
+	KindNationalID Kind = "NATIONAL_ID"
+	KindAddress    Kind = "ADDRESS"
+	KindName       Kind = "NAME"
+)
+
+type pattern struct {
+	kind  Kind
+	re    *regexp.Regexp
+	group int                 // submatch to replace, 0 for the whole match
+	valid func(s string) bool // optional post-match check
+}
+
+// Detection order matters: national IDs before phones, emails before URLs
+var patterns = []pattern{
+	{kind: KindNationalID, re: regexp.MustCompile(`(?i)\b(?:NIK|KTP|SSN|NPWP|passport(?:\s+no\.?)?|national\s+id)\s*[:#]?\s*([A-Z0-9][A-Z0-9.\-]{5,24})`), group: 1},
+	{kind: KindNationalID, re: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b|\b\d{16}\b`)},
+	{kind: KindEmail, re: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)},
+	{kind: KindURL, re: regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>()"']+|\b(?:linkedin\.com|github\.com|gitlab\.com|behance\.net|dribbble\.com)/[^\s<>()"']+`)},
+	{kind: KindPhone, re: regexp.MustCompile(`(?:\+|\b)\d[\d\s().\-]{7,18}\d\b`), valid: isPhoneNumber},
+	{kind: KindAddress, re: regexp.MustCompile(`(?im)\b(?:address|alamat|domicile|domisili)\s*:\s*([^\n]+)`), group: 1},
+	{kind: KindAddress, re: regexp.MustCompile(`(?i)\b(?:Jl\.|Jln\.|Jalan)\s+[^\n,]+(?:,[^\n,]+){0,2}`)},
+	{kind: KindAddress, re: regexp.MustCompile(`\b\d{1,5}\s+(?:[A-Z][a-z]+\s+){1,4}(?:Street|St\.|Avenue|Ave\.|Road|Rd\.|Boulevard|Blvd\.|Lane|Ln\.|Drive|Dr\.|Court|Ct\.)`)},
+}
+
+// isPhoneNumber rejects date ranges and short numbers caught by the phone pattern
+func isPhoneNumber(s string) bool {
+	digits := 0
+	for _, r := range s {
+		if unicode.IsDigit(r) {
+			digits++
+		}
+	}
+	if digits < 9 || digits > 15 {
+		return false
+	}
+	return !yearRange.MatchString(strings.TrimSpace(s))
+}
+
+// yearRange matches "2019 - 2021" style ranges
+var yearRange = regexp.MustCompile(`^\d{4}\s*[-–]\s*\d{4}$`)
+
+// Redactor replaces personal data with stable placeholders and remembers the
+// originals so that text returned by the LLM can be restored for display.
+// One Redactor should be used per candidate so placeholders stay consistent
+// across the CV, the project report and the model's feedback.
+type Redactor struct {
+	names        []namePattern
+	placeholders map[string]string // original -> placeholder
+	originals    map[string]string // placeholder -> original
+	counters     map[Kind]int      // distinct values seen per kind
+	counts       map[Kind]int      // replacements made per kind
+}
+
+// namePattern matches one registered name or name part
+type namePattern struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// New creates a Redactor that also redacts the given person names
+func New(names ...string) *Redactor {
+	r := &Redactor{
+		placeholders: make(map[string]string),
+		originals:    make(map[string]string),
+		counters:     make(map[Kind]int),
+		counts:       make(map[Kind]int),
+	}
+	r.AddNames(names...)
+	return r
+}
+
+// AddNames registers person names; each full name and its longer parts are
+// redacted. Parts that are also common words ("Will", "Summary") are only
+// redacted as part of the full name.
+func (r *Redactor) AddNames(names ...string) {
+	for _, name := range names {
+		name = strings.Join(strings.Fields(name), " ")
+		if name == "" || strings.HasPrefix(name, "[") {
+			continue
+		}
+		r.addName(name)
+		for _, part := range strings.Fields(name) {
+			if len([]rune(part)) >= 3 && !nameStopwords[strings.ToLower(part)] {
+				r.addName(part)
+			}
+		}
+	}
+
+	// Longest first so full names win over their parts
+	sort.SliceStable(r.names, func(i, j int) bool {
+		return len(r.names[i].name) > len(r.names[j].name)
+	})
+}
+
+// addName compiles the pattern of a name once. Names match as written or in
+// capitals, as in a CV heading, but not in lower case, so a name that is
+// also a word doesn't erase that word from the text.
+func (r *Redactor) addName(name string) {
+	for _, n := range r.names {
+		if n.name == name {
+			return
+		}
+	}
+	variants := regexp.QuoteMeta(name)
+	if upper := strings.ToUpper(name); upper != name {
+		variants += "|" + regexp.QuoteMeta(upper)
+	}
+	r.names = append(r.names, namePattern{name: name, re: regexp.MustCompile(`\b(?:` + variants + `)\b`)})
+}
+
+// Redact replaces all detected personal data in text
+func (r *Redactor) Redact(text string) string {
+	for _, p := range patterns {
+		text = r.replace(text, p)
+	}
+
+	for _, name := range r.names {
+		text = r.replace(text, pattern{kind: KindName, re: name.re})
+	}
+
+	return text
+}
+
+func (r *Redactor) replace(text string, p pattern) string {
+	matches := p.re.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[2*p.group], m[2*p.group+1]
+		if start < 0 || start < last {
+			continue
+		}
+
+		value := strings.TrimSpace(text[start:end])
+		if value == "" || strings.HasPrefix(value, "[") || (p.valid != nil && !p.valid(value)) {
+			continue
+		}
+
+		b.WriteString(text[last:start])
+		b.WriteString(r.placeholderFor(p.kind, value))
+		last = end
+		r.counts[p.kind]++
+	}
+	b.WriteString(text[last:])
+
+	return b.String()
+}
+
+// placeholderFor returns the same placeholder every time a value is seen
+func (r *Redactor) placeholderFor(kind Kind, value string) string {
+	key := string(kind) + "\x00" + strings.ToLower(value)
+	if placeholder, ok := r.placeholders[key]; ok {
+		return placeholder
+	}
+
+	r.counters[kind]++
+	placeholder := fmt.Sprintf("[%s_%d]", kind, r.counters[kind])
+	r.placeholders[key] = placeholder
+	r.originals[placeholder] = value
+	return placeholder
+}
+
+// FromOriginals rebuilds a Redactor that can Restore text redacted earlier,
+// given the placeholders returned by Originals
+func FromOriginals(originals map[string]string) *Redactor {
+	r := New()
+	for placeholder, original := range originals {
+		r.originals[placeholder] = original
+	}
+	return r
+}
+
+// Originals returns the placeholder to original value mapping, for
+// restoring text after the Redactor itself is gone
+func (r *Redactor) Originals() map[string]string {
+	originals := make(map[string]string, len(r.originals))
+	for placeholder, original := range r.originals {
+		originals[placeholder] = original
+	}
+	return originals
+}
+
+// Restore puts the original values back in place of placeholders
+func (r *Redactor) Restore(text string) string {
+	if len(r.originals) == 0 || !strings.Contains(text, "[") {
+		return text
+	}
+
+	pairs := make([]string, 0, len(r.originals)*2)
+	for placeholder, original := range r.originals {
+		pairs = append(pairs, placeholder, original)
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// RestoreJSON restores placeholders inside JSON string values, escaping the originals
+func (r *Redactor) RestoreJSON(data []byte) []byte {
+	if len(r.originals) == 0 {
+		return data
+	}
+
+	pairs := make([]string, 0, len(r.originals)*2)
+	for placeholder, original := range r.originals {
+		escaped, err := json.Marshal(original)
+		if err != nil {
+			continue
+		}
+		pairs = append(pairs, placeholder, string(escaped[1:len(escaped)-1]))
+	}
+	return []byte(strings.NewReplacer(pairs...).Replace(string(data)))
+}
+
+// Counts returns how many replacements were made per kind
+func (r *Redactor) Counts() map[Kind]int {
+	counts := make(map[Kind]int, len(r.counts))
+	for kind, n := range r.counts {
+		counts[kind] = n
+	}
+	return counts
+}
+
+var nameLine = regexp.MustCompile(`^(?:\p{Lu}[\p{L}'.\-]*\s+){1,3}\p{Lu}[\p{L}'.\-]*$`)
+
+// GuessName returns the first line of a CV when it looks like a person's
+// name, which is where most resumes put it. Headings and job titles such as
+// "Professional Summary" or "Senior Backend Engineer" are not names.
+func GuessName(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(line, "#"))
+		if line == "" {
+			continue
+		}
+		if len(line) <= 60 && nameLine.MatchString(line) && !isDocumentTitle(line) {
+			return line
+		}
+		return ""
+	}
+	return ""
+}
+
+// isDocumentTitle filters titles such as "CURRICULUM VITAE", section
+// headings and job titles: lines with any word from headingWords
+func isDocumentTitle(line string) bool {
+	for _, word := range strings.Fields(strings.ToLower(line)) {
+		if headingWords[strings.Trim(word, "'.-")] {
+			return true
+		}
+	}
+	return false
+}
+
+// headingWords appear in CV titles, section headings and job titles but
+// rarely in a person's name
+var headingWords = setOf(
+	"curriculum", "vitae", "resume", "résumé", "cv", "profile", "portfolio", "cover", "letter",
+	"summary", "professional", "personal", "career", "objective", "about", "contact", "information", "details",
+	"experience", "work", "employment", "history", "education", "skills", "technical", "projects", "certifications",
+	"achievements", "awards", "references", "languages", "interests", "publications", "training", "courses",
+	"senior", "junior", "lead", "principal", "staff", "head", "chief", "intern", "associate", "assistant",
+	"engineer", "engineering", "developer", "programmer", "architect", "designer", "analyst", "scientist",
+	"manager", "director", "consultant", "specialist", "officer", "administrator", "coordinator", "executive",
+	"software", "backend", "frontend", "full", "stack", "fullstack", "data", "product", "project", "cloud",
+	"devops", "web", "mobile", "machine", "learning", "ai", "security", "systems", "quality", "qa",
+)
+
+// nameStopwords are common words that are also names or name parts; they
+// are redacted within a full name but not on their own
+var nameStopwords = setOf(
+	"will", "may", "mark", "bill", "grace", "hope", "faith", "joy", "june", "april", "august",
+	"rose", "summer", "dawn", "sky", "king", "young", "long", "white", "black", "brown", "green",
+	"van", "von", "der", "den", "del", "della", "bin", "binti", "the", "and",
+)
+
+func setOf(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
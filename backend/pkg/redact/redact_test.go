@@ -0,0 +1,89 @@
+package redact
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		in    string
+		want  string
+	}{
+		{"email", nil, "Reach me at jane.doe+cv@example.co.id today.", "Reach me at [EMAIL_1] today."},
+		{"phone", nil, "Phone: +62 812-3456-7890", "Phone: [PHONE_1]"},
+		{"year range is not a phone", nil, "Acme Corp 2019 - 2021", "Acme Corp 2019 - 2021"},
+		{"url", nil, "See github.com/janedoe and https://jane.dev/about", "See [URL_1] and [URL_2]"},
+		{"labelled national ID", nil, "NIK: 3201234567890001", "NIK: [NATIONAL_ID_1]"},
+		{"SSN", nil, "SSN 123-45-6789 on file", "SSN [NATIONAL_ID_1] on file"},
+		{"labelled address", nil, "Address: Jl. Sudirman No. 5, Jakarta\nSkills: Go", "Address: [ADDRESS_1]\nSkills: Go"},
+		{"street address", nil, "Lives at 221 Baker Street now.", "Lives at [ADDRESS_1] now."},
+		{"full name and parts", []string{"Jane Doe"}, "Jane Doe built it. Later Doe led it.", "[NAME_1] built it. Later [NAME_2] led it."},
+		{"name in capitals", []string{"Jane Doe"}, "JANE DOE\nBackend engineer", "[NAME_1]\nBackend engineer"},
+		{"lower-case word is not the name", []string{"Grace Hopper"}, "Deployed with grace and hopper-style care.", "Deployed with grace and hopper-style care."},
+		{"stopword part only redacted in full", []string{"Will Smith"}, "Will Smith wrote it. Will it scale? Smith says yes.", "[NAME_1] wrote it. Will it scale? [NAME_2] says yes."},
+		{"same value, same placeholder", nil, "a@b.io and again a@b.io", "[EMAIL_1] and again [EMAIL_1]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New(tt.names...)
+			got := r.Redact(tt.in)
+			if got != tt.want {
+				t.Errorf("Redact(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			if restored := r.Restore(got); !strings.EqualFold(restored, tt.in) {
+				t.Errorf("Restore(%q) = %q, want %q", got, restored, tt.in)
+			}
+		})
+	}
+}
+
+func TestRedactKeepsOrdinaryWords(t *testing.T) {
+	cv := "Professional Summary\nSenior Backend Engineer who will mentor the team.\n"
+	r := New(GuessName(cv))
+	if got := r.Redact(cv); got != cv {
+		t.Errorf("Redact changed a CV without personal data:\n got %q\nwant %q", got, cv)
+	}
+	if counts := r.Counts(); len(counts) != 0 {
+		t.Errorf("Counts() = %v, want none", counts)
+	}
+}
+
+func TestGuessName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Jane Doe\nBackend engineer", "Jane Doe"},
+		{"# Budi Santoso Wijaya\n\nSummary", "Budi Santoso Wijaya"},
+		{"\n\n  Ana María O'Neil  \n", "Ana María O'Neil"},
+		{"JANE DOE\nEngineer", "JANE DOE"},
+		{"CURRICULUM VITAE\nJane Doe", ""},
+		{"Resume\nJane Doe", ""},
+		{"Professional Summary\nI build APIs", ""},
+		{"Senior Backend Engineer\nJane Doe", ""},
+		{"Work Experience", ""},
+		{"Data Scientist", ""},
+		{"jane doe", ""},
+		{"I am a backend engineer with Go experience", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := GuessName(tt.in); got != tt.want {
+			t.Errorf("GuessName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRestoreJSONEscapes(t *testing.T) {
+	r := New(`Jane "JD" Doe`)
+	redacted := r.Redact(`Jane "JD" Doe`)
+	got := string(r.RestoreJSON([]byte(`{"name":"` + redacted + `"}`)))
+	if want := `{"name":"Jane \"JD\" Doe"}`; got != want {
+		t.Errorf("RestoreJSON = %s, want %s", got, want)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 
+	"github.com/adyutaa/parsea/internal/config"
 	"github.com/adyutaa/parsea/internal/infrastructure/llm"
 	"github.com/adyutaa/parsea/internal/infrastructure/vectordb"
 	"github.com/joho/godotenv"
@@ -16,19 +17,24 @@ func main() {
 
 	fmt.Println("🚀 Starting Qdrant document ingestion...")
 
+	// Only the Qdrant and OpenAI settings matter here, so skip full validation
+	cfg, err := config.Load(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		log.Fatal("Failed to load configuration:", err)
+	}
+
 	// Initialize Qdrant
-	qdrant, err := vectordb.NewQdrantClient()
+	qdrant, err := vectordb.NewQdrantClient(cfg.Qdrant)
 	if err != nil {
 		log.Fatal("Failed to create Qdrant client:", err)
 	}
 	fmt.Println("✅ Connected to Qdrant Cloud")
 
 	// Initialize OpenAI for embeddings
-	openaiKey := os.Getenv("OPENAI_API_KEY")
-	if openaiKey == "" {
+	if cfg.LLM.APIKey == "" {
 		log.Fatal("OPENAI_API_KEY not set in environment")
 	}
-	llmClient := llm.NewOpenAIClient(openaiKey)
+	llmClient := llm.NewOpenAIClient(cfg.LLM)
 	if llmClient == nil {
 		log.Fatal("Failed to create OpenAI client")
 	}
@@ -104,7 +104,7 @@ export default function Home() {
               Document Evaluation Setup
             </h1>
             <p className="text-lg text-slate-500 dark:text-slate-400 font-normal leading-relaxed">
-              Please upload your Curriculum Vitae and Project Report in PDF format. Our AI will
+              Please upload your Curriculum Vitae and Project Report in PDF, DOCX, TXT or Markdown format. Our AI will
               analyze both to generate your comprehensive evaluation.
             </p>
           </div>
@@ -115,7 +115,7 @@ export default function Home() {
             <div className="group relative flex flex-col rounded-2xl bg-surface-light dark:bg-surface-dark shadow-sm border border-gray-200 dark:border-gray-800 overflow-hidden transition-all hover:shadow-md">
               <input
                 type="file"
-                accept=".pdf"
+                accept=".pdf,.docx,.txt,.md"
                 className="hidden"
                 ref={cvInputRef}
                 onChange={(e) => handleFileChange(e, setCvFile)}
@@ -141,7 +141,7 @@ export default function Home() {
                 <button className="relative overflow-hidden rounded-lg bg-primary text-white px-6 py-2.5 text-sm font-bold shadow-lg shadow-primary/25 hover:bg-blue-600 transition-all focus:ring-4 focus:ring-primary/20 active:scale-95">
                   <span className="relative z-10 flex items-center gap-2">
                     <span className="material-symbols-outlined text-lg">upload_file</span>
-                    {cvFile ? 'Change File' : 'Select PDF File'}
+                    {cvFile ? 'Change File' : 'Select File'}
                   </span>
                 </button>
               </div>
@@ -151,7 +151,7 @@ export default function Home() {
             <div className="group relative flex flex-col rounded-2xl bg-surface-light dark:bg-surface-dark shadow-sm border border-gray-200 dark:border-gray-800 overflow-hidden transition-all hover:shadow-md">
               <input
                 type="file"
-                accept=".pdf"
+                accept=".pdf,.docx,.txt,.md"
                 className="hidden"
                 ref={reportInputRef}
                 onChange={(e) => handleFileChange(e, setReportFile)}
@@ -177,7 +177,7 @@ export default function Home() {
                 <button className="relative overflow-hidden rounded-lg bg-surface-light dark:bg-gray-800 text-gray-900 dark:text-white border border-gray-200 dark:border-gray-600 px-6 py-2.5 text-sm font-bold shadow-sm hover:bg-gray-50 dark:hover:bg-gray-700 transition-all focus:ring-4 focus:ring-gray-200 dark:focus:ring-gray-700 active:scale-95">
                   <span className="relative z-10 flex items-center gap-2">
                     <span className="material-symbols-outlined text-lg">upload_file</span>
-                    {reportFile ? 'Change File' : 'Select PDF File'}
+                    {reportFile ? 'Change File' : 'Select File'}
                   </span>
                 </button>
               </div>
@@ -191,7 +191,7 @@ export default function Home() {
               <span className="material-symbols-outlined text-base">info</span>
               <span>Max file size: 10MB per file</span>
               <span className="w-1 h-1 bg-slate-400 rounded-full mx-1"></span>
-              <span>Format: PDF only</span>
+              <span>Format: PDF, DOCX, TXT, MD</span>
             </div>
           </div>
 
@@ -1,5 +1,8 @@
 export const API_URL = 'http://localhost:8080';
 
+// Issued with `server apikey create <tenant>`; every API route requires it
+const API_KEY = process.env.NEXT_PUBLIC_API_KEY ?? '';
+
 export interface UploadResponse {
     cv_id: number;
     report_id: number;
@@ -40,6 +43,9 @@ export const api = {
 
         const response = await fetch(`${API_URL}/upload`, {
             method: 'POST',
+            headers: {
+                'X-API-Key': API_KEY,
+            },
             body: formData,
         });
 
@@ -59,6 +65,7 @@ export const api = {
             method: 'POST',
             headers: {
                 'Content-Type': 'application/json',
+                'X-API-Key': API_KEY,
             },
             body: JSON.stringify({
                 cv_id: cvId,
@@ -81,6 +88,9 @@ export const api = {
     async getResult(jobId: number): Promise<EvaluationJob> {
         const response = await fetch(`${API_URL}/result?id=${jobId}`, {
             method: 'GET',
+            headers: {
+                'X-API-Key': API_KEY,
+            },
         });
 
         if (!response.ok) {